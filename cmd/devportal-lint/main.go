@@ -0,0 +1,49 @@
+// Command devportal-lint checks a local checkout of the templates repository,
+// it exits with an error when a template would break the setups:
+//
+//	devportal-lint -dir ../devportal-templates
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/pkg/log_logger"
+	"github.com/zahirsis/dev-portal-backend/src/domain/service"
+	gitRepository "github.com/zahirsis/dev-portal-backend/src/infrastructure/repository/git"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/services/unix"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"log"
+	"os"
+)
+
+func main() {
+	cfg := config.New()
+	dir := flag.String("dir", ".", "checkout of the templates repository")
+	descriptor := flag.String("descriptor", cfg.SetupCiCd.TemplatesDescriptor, "templates descriptor, a file or a directory, relative to the checkout")
+	verbose := flag.Bool("verbose", false, "log the templates being checked")
+	flag.Parse()
+	level := logger.Fatal
+	if *verbose {
+		level = logger.Debug
+	}
+	loggerInstance := log_logger.New(log.New(os.Stderr, "", log.Ldate|log.Ltime), &logger.Config{Level: level})
+	templates, err := gitRepository.ReadTemplates(loggerInstance, &config.SetupCiCdConfig{
+		TemplatesCacheDir:   *dir,
+		TemplatesDescriptor: *descriptor,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	lint := service.NewTemplatesLintService(loggerInstance, unix.NewDirectoryService(loggerInstance))
+	errs := lint.Lint(*dir, templates)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "%d error(s) found\n", len(errs))
+		os.Exit(1)
+	}
+	fmt.Printf("%d template(s) checked\n", len(templates))
+}
@@ -17,9 +17,12 @@ import (
 	httpHandler "github.com/zahirsis/dev-portal-backend/src/app/handlers/http"
 	websocketHandler "github.com/zahirsis/dev-portal-backend/src/app/handlers/websocket"
 	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
+	"github.com/zahirsis/dev-portal-backend/src/app/workers"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
 	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
 	"github.com/zahirsis/dev-portal-backend/src/domain/service"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	gitRepository "github.com/zahirsis/dev-portal-backend/src/infrastructure/repository/git"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/repository/memory"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/repository/redis"
 	awsApp "github.com/zahirsis/dev-portal-backend/src/infrastructure/services/aws"
@@ -77,19 +80,32 @@ func main() {
 		return
 	}
 	awsClient := ecr.NewFromConfig(awsCfg)
-	messengerInstance := messenger.NewMessageMassager()
+	messengerInstance := messenger.NewRedisMessageManager(redisClient)
 
-	tr := memory.NewTemplateRepository(loggerInstance)
-	er := memory.NewEnvironmentRepository(loggerInstance)
-	sr := memory.NewSquadRepository(loggerInstance)
+	git := unix.NewGitService(cfg.GitConfig, loggerInstance)
+	var tr repository.TemplateRepository
+	if cfg.SetupCiCd.TemplatesSource == config.TemplatesMemory {
+		tr = memory.NewTemplateRepository(loggerInstance)
+	} else {
+		tr = gitRepository.NewTemplateRepository(loggerInstance, cfg.SetupCiCd, git)
+	}
+	if err := tr.Refresh(); err != nil {
+		loggerInstance.Error("Error loading templates", err.Error())
+	}
+	er := redis.NewEnvironmentRepository(loggerInstance, redisClient)
+	sr := redis.NewSquadRepository(loggerInstance, redisClient)
 	pr := redis.NewProcessRepository(loggerInstance, redisClient)
 	mr := memory.NewManifestRepository(loggerInstance)
+	jqr := redis.NewJobQueueRepository(loggerInstance, redisClient)
+	ar := redis.NewApplicationRepository(loggerInstance, redisClient)
 	rc := &repository.Container{
 		TemplateRepository:    tr,
 		EnvironmentRepository: er,
 		SquadRepository:       sr,
 		ProgressRepository:    pr,
 		ManifestRepository:    mr,
+		JobQueueRepository:    jqr,
+		ApplicationRepository: ar,
 	}
 
 	bitbucketClient := bitbucketPkg.NewBasicAuth(cfg.GitConfig.UserName, cfg.GitConfig.Token)
@@ -113,7 +129,6 @@ func main() {
 		return
 	}
 
-	git := unix.NewGitService(cfg.GitConfig, loggerInstance)
 	ccs := service.NewCiCdService(loggerInstance, rc)
 	rs := service.NewRegistryService(loggerInstance, rc)
 	ras := awsApp.NewRegistryApiService(loggerInstance, awsClient)
@@ -125,19 +140,23 @@ func main() {
 	ws := service.NewWikiService(cfg, loggerInstance, aws, ds)
 	sas := vault.NewSecretApiService(cfg, loggerInstance, vaultApi, vaultAuth)
 	ss := service.NewSecretService(loggerInstance, sas)
+	cs := service.NewCompensationService(loggerInstance, git, gas, ras, sas, aws, ds)
+	mss := service.NewManifestSchemaService(loggerInstance, ds)
 	sc := &service.Container{
-		GitService:         git,
-		CiCdService:        ccs,
-		RegistryService:    rs,
-		RegistryApiService: ras,
-		GitOpsService:      gs,
-		PipelineService:    ps,
-		DirectoryService:   ds,
-		GitApiService:      gas,
-		WikiService:        ws,
-		WikiApiService:     aws,
-		SecretService:      ss,
-		SecretApiService:   sas,
+		GitService:            git,
+		CiCdService:           ccs,
+		RegistryService:       rs,
+		RegistryApiService:    ras,
+		GitOpsService:         gs,
+		PipelineService:       ps,
+		DirectoryService:      ds,
+		GitApiService:         gas,
+		WikiService:           ws,
+		WikiApiService:        aws,
+		SecretService:         ss,
+		SecretApiService:      sas,
+		CompensationService:   cs,
+		ManifestSchemaService: mss,
 	}
 	c := &container.Container{
 		Logger:         loggerInstance,
@@ -146,24 +165,77 @@ func main() {
 		Services:       sc,
 	}
 
-	// Templates
-	tuc := usecase.NewListTemplatesUseCase(c)
-	httpHandler.NewTemplateHandler(c, apiGroup.Group("templates"), tuc)
-
 	// Environments
 	euc := usecase.NewListEnvironmentsUseCase(c)
-	httpHandler.NewEnvironmentHandler(c, apiGroup.Group("environments"), euc)
+	meuc := usecase.NewManageEnvironmentsUseCase(c)
+	seed, _ := memory.NewEnvironmentRepository(loggerInstance).List()
+	if seeded, err := meuc.Seed(seed); err != nil {
+		loggerInstance.Error("Error seeding environments: %s", err.Error())
+	} else if seeded > 0 {
+		loggerInstance.Info("%d environments seeded", seeded)
+	}
+	httpHandler.NewEnvironmentHandler(c, apiGroup.Group("environments"), euc, meuc)
 
 	// Squads
 	suc := usecase.NewListSquadsUseCase(c)
-	httpHandler.NewSquadHandler(c, apiGroup.Group("squads"), suc)
+	gsuc := usecase.NewGetSquadUseCase(c)
+	msuc := usecase.NewManageSquadsUseCase(c)
+	squads, _ := memory.NewSquadRepository(loggerInstance).List()
+	if seeded, err := msuc.Seed(squads); err != nil {
+		loggerInstance.Error("Error seeding squads: %s", err.Error())
+	} else if seeded > 0 {
+		loggerInstance.Info("%d squads seeded", seeded)
+	}
+	httpHandler.NewSquadHandler(c, apiGroup.Group("squads"), suc, gsuc, msuc)
 
 	// CI/CD
-	cuc := usecase.NewSetupCiCdUseCase(c, cfg)
+	cuc, err := usecase.NewSetupCiCdUseCase(c, cfg)
+	if err != nil {
+		loggerInstance.Fatal("Error creating setup use case", err)
+		return
+	}
 	guc := usecase.NewGetCiCdDataUseCase(cfg)
-	httpHandler.NewCiCdHandler(c, apiGroup.Group("ci-cd"), cuc, guc)
+	httpHandler.NewCiCdHandler(c, apiGroup.Group("ci-cd"), cuc, guc, cfg.Http.UserHeader)
 	puc := usecase.NewProgressUseCase(c)
 	websocketHandler.NewCiCdHandler(c, apiGroup.Group("ci-cd"), upgrader, puc)
+	httpHandler.NewProgressHandler(c, apiGroup.Group("ci-cd"), puc)
+	lpuc := usecase.NewListProcessesUseCase(c)
+	httpHandler.NewProcessHandler(c, apiGroup.Group("ci-cd"), lpuc)
+
+	// Templates
+	tuc := usecase.NewListTemplatesUseCase(c)
+	httpHandler.NewTemplateHandler(c, apiGroup.Group("templates"), tuc, cuc)
+
+	// Applications
+	iuc := usecase.NewImportApplicationsUseCase(c, cfg)
+	lauc := usecase.NewListApplicationsUseCase(c)
+	gauc := usecase.NewGetApplicationUseCase(c)
+	httpHandler.NewApplicationHandler(c, apiGroup.Group("applications"), cuc, iuc, lauc, gauc)
+
+	// Admin
+	wuc := usecase.NewWorkspacesUseCase(c, cfg)
+	httpHandler.NewWorkspaceHandler(c, apiGroup.Group("admin"), wuc)
+
+	// Workers
+	pool := workers.NewPool(c, cfg.Workers)
+	pool.Register(entity.SetupCiCdJob, cuc)
+	pool.Register(entity.RollbackCiCdJob, cuc)
+	pool.Register(entity.DecommissionJob, cuc)
+	pool.Register(entity.AddEnvironmentJob, cuc)
+	pool.Register(entity.ChangeResourcesJob, cuc)
+	pool.Register(entity.PromoteJob, cuc)
+	pool.Fallback(cuc)
+	pool.Schedule(cfg.SetupCiCd.JanitorInterval, func() {
+		if _, err := wuc.Clean(); err != nil {
+			loggerInstance.Error("Error cleaning workspaces", err.Error())
+		}
+	})
+	pool.Schedule(cfg.SetupCiCd.TemplatesRefreshInterval, func() {
+		if err := tr.Refresh(); err != nil {
+			loggerInstance.Error("Error refreshing templates", err.Error())
+		}
+	})
+	pool.Start(ctx)
 
 	err = router.Run(":8080")
 	if err != nil {
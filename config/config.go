@@ -54,6 +54,22 @@ func SecretServiceFromString(service string) SecretService {
 	}
 }
 
+type TemplatesSource string
+
+const (
+	TemplatesRepository TemplatesSource = "repository"
+	TemplatesMemory     TemplatesSource = "memory"
+)
+
+func TemplatesSourceFromString(source string) TemplatesSource {
+	switch source {
+	case "memory":
+		return TemplatesMemory
+	default:
+		return TemplatesRepository
+	}
+}
+
 type GitProtocol int
 
 const (
@@ -84,6 +100,9 @@ type corsConfig struct {
 type httpConfig struct {
 	Path string
 	Port string
+	// UserHeader is the header set by the authenticating proxy with the
+	// user making the request.
+	UserHeader string
 }
 
 type wsConfig struct {
@@ -98,12 +117,25 @@ type redisConfig struct {
 	DB       int
 }
 
+type WorkersConfig struct {
+	Size                int
+	PollInterval        time.Duration
+	VisibilityTimeout   time.Duration
+	HeartbeatInterval   time.Duration
+	ReapInterval        time.Duration
+	QueueReportInterval time.Duration
+	MaxAttempts         int
+}
+
 type GitConfig struct {
 	Host     string
 	UserName string
 	Token    string
 	Project  string
 	Protocol GitProtocol
+	// MirrorsPath keeps a mirror of each repository, the processes get
+	// worktrees of them instead of clones. Empty disables the mirrors.
+	MirrorsPath string
 }
 
 type WikiConfig struct {
@@ -165,6 +197,22 @@ type SetupCiCdConfig struct {
 	ConfigMapDestinationDir     string
 	ApplicationMainBranch       string
 	ApplicationDestinationDir   string
+	AutoRollback                bool
+	// KeepWorkspaceOnFailure keeps the clones of a failed process for
+	// debugging, until the janitor removes them after WorkspaceTTL.
+	KeepWorkspaceOnFailure bool
+	WorkspaceTTL           time.Duration
+	JanitorInterval        time.Duration
+	// TemplatesSource tells where the templates are defined, the templates
+	// repository reads TemplatesDescriptor from a checkout of it kept on
+	// TemplatesCacheDir, refreshed every TemplatesRefreshInterval.
+	TemplatesSource          TemplatesSource
+	TemplatesDescriptor      string
+	TemplatesCacheDir        string
+	TemplatesRefreshInterval time.Duration
+	// MaxConcurrentRenders limits the renders of templates cloning the
+	// templates repository at the same time.
+	MaxConcurrentRenders int
 }
 
 type Config struct {
@@ -172,6 +220,7 @@ type Config struct {
 	Http          *httpConfig
 	WebSocket     *wsConfig
 	Redis         *redisConfig
+	Workers       *WorkersConfig
 	Cors          *corsConfig
 	SetupCiCd     *SetupCiCdConfig
 	GitService    GitService
@@ -191,8 +240,9 @@ func loadConfigFromEnv() *Config {
 	return &Config{
 		LogLevel: getEnumEnvWithDefault[logger.LogLevel]("LOGLEVEL", logger.Error, logger.LogLevelFromString),
 		Http: &httpConfig{
-			Path: getEnvWithDefault("HTTP_PATH", "api"),
-			Port: getEnvWithDefault("HTTP_PORT", "8080"),
+			Path:       getEnvWithDefault("HTTP_PATH", "api"),
+			Port:       getEnvWithDefault("HTTP_PORT", "8080"),
+			UserHeader: getEnvWithDefault("HTTP_USERHEADER", "X-Forwarded-User"),
 		},
 		WebSocket: &wsConfig{
 			ReadBufferSize:   getIntEnvWithDefault("WEBSOCKET_READBUFFERSIZE", 1024),
@@ -204,6 +254,15 @@ func loadConfigFromEnv() *Config {
 			Password: getEnvWithDefault("REDIS_PASSWORD", ""),
 			DB:       getIntEnvWithDefault("REDIS_DB", 0),
 		},
+		Workers: &WorkersConfig{
+			Size:                getIntEnvWithDefault("WORKERS_SIZE", 2),
+			PollInterval:        getDurationEnvWithDefault("WORKERS_POLLINTERVAL", time.Second),
+			VisibilityTimeout:   getDurationEnvWithDefault("WORKERS_VISIBILITYTIMEOUT", time.Minute),
+			HeartbeatInterval:   getDurationEnvWithDefault("WORKERS_HEARTBEATINTERVAL", 15*time.Second),
+			ReapInterval:        getDurationEnvWithDefault("WORKERS_REAPINTERVAL", 30*time.Second),
+			QueueReportInterval: getDurationEnvWithDefault("WORKERS_QUEUEREPORTINTERVAL", 5*time.Second),
+			MaxAttempts:         getIntEnvWithDefault("WORKERS_MAXATTEMPTS", 3),
+		},
 		Cors: &corsConfig{
 			AllowedOrigins:   []string{getEnvWithDefault("CORS_ALLOWEDORIGINS", "http://localhost:3000")},
 			AllowedMethods:   []string{getEnvWithDefault("CORS_ALLOWEDMETHODS", "*")},
@@ -231,14 +290,24 @@ func loadConfigFromEnv() *Config {
 			ConfigMapDestinationDir:     getEnvWithDefault("SETUPCICD_CONFIGMAPDESTINATIONDIR", "/tmp/setup-ci-cd/{{process-id}}/config-maps"),
 			ApplicationMainBranch:       getEnvWithDefault("SETUPCICD_APPLICATIONMAINBRANCH", "master"),
 			ApplicationDestinationDir:   getEnvWithDefault("SETUPCICD_APPLICATIONDESTINATIONDIR", "/tmp/setup-ci-cd/{{process-id}}/application"),
+			AutoRollback:                os.Getenv("SETUPCICD_AUTOROLLBACK") == "true",
+			KeepWorkspaceOnFailure:      os.Getenv("SETUPCICD_KEEPWORKSPACEONFAILURE") == "true",
+			WorkspaceTTL:                getDurationEnvWithDefault("SETUPCICD_WORKSPACETTL", 24*time.Hour),
+			JanitorInterval:             getDurationEnvWithDefault("SETUPCICD_JANITORINTERVAL", 10*time.Minute),
+			TemplatesSource:             getEnumEnvWithDefault[TemplatesSource]("SETUPCICD_TEMPLATESSOURCE", TemplatesRepository, TemplatesSourceFromString),
+			TemplatesDescriptor:         getEnvWithDefault("SETUPCICD_TEMPLATESDESCRIPTOR", "templates.yaml"),
+			TemplatesCacheDir:           getEnvWithDefault("SETUPCICD_TEMPLATESCACHEDIR", "/tmp/devportal-templates"),
+			TemplatesRefreshInterval:    getDurationEnvWithDefault("SETUPCICD_TEMPLATESREFRESHINTERVAL", 5*time.Minute),
+			MaxConcurrentRenders:        getIntEnvWithDefault("SETUPCICD_MAXCONCURRENTRENDERS", 2),
 		},
 		GitService: getEnumEnvWithDefault[GitService]("GITSERVICE", GitBitbucket, GitServiceFromString),
 		GitConfig: &GitConfig{
-			Host:     getEnvWithDefault("GITCONFIG_HOST", ""),
-			UserName: getEnvWithDefault("GITCONFIG_USERNAME", ""),
-			Token:    getEnvWithDefault("GITCONFIG_TOKEN", ""),
-			Project:  getEnvWithDefault("GITCONFIG_PROJECT", ""),
-			Protocol: getEnumEnvWithDefault[GitProtocol]("GITCONFIG_PROTOCOL", GitSSH, GitProtocolFromString),
+			Host:        getEnvWithDefault("GITCONFIG_HOST", ""),
+			UserName:    getEnvWithDefault("GITCONFIG_USERNAME", ""),
+			Token:       getEnvWithDefault("GITCONFIG_TOKEN", ""),
+			Project:     getEnvWithDefault("GITCONFIG_PROJECT", ""),
+			Protocol:    getEnumEnvWithDefault[GitProtocol]("GITCONFIG_PROTOCOL", GitSSH, GitProtocolFromString),
+			MirrorsPath: os.Getenv("GITCONFIG_MIRRORSPATH"),
 		},
 		WikiService: getEnumEnvWithDefault[WikiService]("WIKISERVICE", WikiConfluence, WikiServiceFromString),
 		WikiConfig: &WikiConfig{
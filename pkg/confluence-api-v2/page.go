@@ -1,8 +1,11 @@
 package confluenceapiv2
 
 import (
+	"encoding/json"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 type PageSortOrder string
@@ -130,6 +133,34 @@ func (a *API) CreatePage(page *Page) (*Page, error) {
 	return a.SendPageRequest(ep, "POST", page)
 }
 
+// getArchiveEndpoint creates the v1 archive endpoint, v2 does not support archiving
+func (a *API) getArchiveEndpoint() (*url.URL, error) {
+	return url.ParseRequestURI(strings.TrimSuffix(a.endPoint.String(), "/api/v2") + "/rest/api/content/archive")
+}
+
+// ArchivePages archives pages by id
+func (a *API) ArchivePages(ids ...string) error {
+	ep, err := a.getArchiveEndpoint()
+	if err != nil {
+		return err
+	}
+	body := &ArchivePagesRequest{}
+	for _, id := range ids {
+		body.Pages = append(body.Pages, &ArchivePage{Id: id})
+	}
+	js, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", ep.String(), strings.NewReader(string(js)))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	_, err = a.Request(req)
+	return err
+}
+
 // addPageByIdQueryParams adds the defined query parameters
 func addPageByIdQueryParams(query *GetPageByIdQuery) *url.Values {
 
@@ -53,7 +53,7 @@ func (a *API) Request(req *http.Request) ([]byte, error) {
 	Debug("====== /Response Body ======")
 
 	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusPartialContent:
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
 		return res, nil
 	case http.StatusNoContent, http.StatusResetContent:
 		return nil, nil
@@ -5,6 +5,14 @@ type Version struct {
 	Number  int    `json:"number"`
 }
 
+type ArchivePage struct {
+	Id string `json:"id"`
+}
+
+type ArchivePagesRequest struct {
+	Pages []*ArchivePage `json:"pages"`
+}
+
 type BodyExtended struct {
 	Representation string `json:"representation"`
 	Value          string `json:"value"`
@@ -0,0 +1,22 @@
+package errors
+
+import "encoding/json"
+
+// ConflictError reports a request that clashes with the current state, like
+// a resource that already exists or is being changed by another process.
+type ConflictError struct {
+	Messages []string
+	Input    string
+}
+
+func NewConflictError(input string, errors []string) error {
+	return &ConflictError{
+		Messages: errors,
+		Input:    input,
+	}
+}
+
+func (c *ConflictError) Error() string {
+	jsonError, _ := json.Marshal(c)
+	return string(jsonError)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zahirsis/dev-portal-backend/src/app/interfaces"
+	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+type ApplicationHandler struct {
+	*container.Container
+	setupUseCase  usecase.SetupCiCdUseCase
+	importUseCase usecase.ImportApplicationsUseCase
+	listUseCase   usecase.ListApplicationsUseCase
+	getUseCase    usecase.GetApplicationUseCase
+}
+
+func NewApplicationHandler(
+	c *container.Container,
+	r interfaces.Router,
+	suc usecase.SetupCiCdUseCase,
+	iuc usecase.ImportApplicationsUseCase,
+	luc usecase.ListApplicationsUseCase,
+	guc usecase.GetApplicationUseCase,
+) *ApplicationHandler {
+	h := &ApplicationHandler{
+		c,
+		suc,
+		iuc,
+		luc,
+		guc,
+	}
+	r.GET("", h.ListApplications)
+	r.GET(":slug", h.GetApplication)
+	r.POST("import", h.Import)
+	r.POST(":slug/decommission", h.Decommission)
+	r.POST(":slug/environments", h.AddEnvironments)
+	r.PATCH(":slug/resources", h.ChangeResources)
+	r.POST(":slug/promote", h.Promote)
+	return h
+}
+
+func (th *ApplicationHandler) ListApplications(c interfaces.HttpServerContext) {
+	var input usecase.ListApplicationsInputDto
+	if err := c.BindQuery(&input); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.listUseCase.Exec(input)
+	if len(errs) > 0 {
+		c.JSON(400, gin.H{"errors": formatErrors(th.Logger, errs), "message": "Filters are invalid, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
+
+func (th *ApplicationHandler) GetApplication(c interfaces.HttpServerContext) {
+	application, err := th.getUseCase.Exec(c.Param("slug"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if application == nil {
+		c.JSON(404, gin.H{"errors": gin.H{"slug": []string{"application not found"}}, "message": "Application not found"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": application})
+}
+
+func (th *ApplicationHandler) Decommission(c interfaces.HttpServerContext) {
+	out := th.setupUseCase.Decommission(c.Param("slug"))
+	if len(out.Errors) > 0 {
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Application cannot be decommissioned, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Decommission started"})
+}
+
+func (th *ApplicationHandler) AddEnvironments(c interfaces.HttpServerContext) {
+	var requestBody usecase.AddEnvironmentsInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out := th.setupUseCase.AddEnvironments(c.Param("slug"), requestBody)
+	if len(out.Errors) > 0 {
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Environments cannot be added, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Environments setup started"})
+}
+
+func (th *ApplicationHandler) ChangeResources(c interfaces.HttpServerContext) {
+	var requestBody usecase.ChangeResourcesInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out := th.setupUseCase.ChangeResources(c.Param("slug"), requestBody)
+	if len(out.Errors) > 0 {
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Resources cannot be changed, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Resources change started"})
+}
+
+func (th *ApplicationHandler) Promote(c interfaces.HttpServerContext) {
+	var requestBody usecase.PromoteInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out := th.setupUseCase.Promote(c.Param("slug"), requestBody)
+	if len(out.Errors) > 0 {
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Image cannot be promoted, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Promotion started"})
+}
+
+func (th *ApplicationHandler) Import(c interfaces.HttpServerContext) {
+	var requestBody usecase.ImportApplicationsInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.importUseCase.Exec(c.Request.Context(), requestBody)
+	if len(errs) > 0 {
+		c.JSON(400, gin.H{"errors": formatErrors(th.Logger, errs), "message": "Applications cannot be imported, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
@@ -1,9 +1,7 @@
 package http
 
 import (
-	"errors"
 	"github.com/gin-gonic/gin"
-	customErrors "github.com/zahirsis/dev-portal-backend/pkg/errors"
 	"github.com/zahirsis/dev-portal-backend/src/app/interfaces"
 	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
@@ -13,6 +11,7 @@ type CiCdHandler struct {
 	*container.Container
 	setupUseCase   usecase.SetupCiCdUseCase
 	getDataUseCase usecase.GetCiCdDataUseCase
+	userHeader     string
 }
 
 func NewCiCdHandler(
@@ -20,13 +19,18 @@ func NewCiCdHandler(
 	r interfaces.Router,
 	suc usecase.SetupCiCdUseCase,
 	guc usecase.GetCiCdDataUseCase,
+	userHeader string,
 ) *CiCdHandler {
 	h := &CiCdHandler{
 		c,
 		suc,
 		guc,
+		userHeader,
 	}
 	r.POST("setup", h.Setup)
+	r.POST("setup/:id/rollback", h.Rollback)
+	r.POST("setup/:id/retry", h.Retry)
+	r.DELETE("setup/:id", h.Cancel)
 	r.GET("data", h.GetData)
 	return h
 }
@@ -46,38 +50,38 @@ func (th *CiCdHandler) Setup(c interfaces.HttpServerContext) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	requestBody.CreatedBy = c.GetHeader(th.userHeader)
 	out := th.setupUseCase.Exec(requestBody)
 
 	if len(out.Errors) > 0 {
-		c.JSON(400, gin.H{"errors": th.formatErrors(out.Errors), "message": "Setup data is invalid, please check the errors"})
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Setup data is invalid, please check the errors"})
 		return
 	}
 	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Process started"})
 }
 
-func (th *CiCdHandler) formatErrors(e []error) map[string][]string {
-	errs := make(map[string][]string)
-	for _, err := range e {
-		var ie *customErrors.InputError
-		if err == nil {
-			continue
-		}
-		if errors.As(err, &ie) {
-			input := ie.Input
-			if _, ok := errs[input]; !ok {
-				errs[input] = []string{}
-			}
-			for _, msg := range ie.Messages {
-				errs[input] = append(errs[input], msg)
-			}
-			continue
-		}
-		th.Logger.Error("INTERNAL ERROR", err)
-		if _, ok := errs["internal"]; !ok {
-			errs["internal"] = []string{}
-		}
-		errs["internal"] = append(errs["internal"], err.Error())
+func (th *CiCdHandler) Retry(c interfaces.HttpServerContext) {
+	out := th.setupUseCase.Retry(c.Param("id"))
+	if len(out.Errors) > 0 {
+		c.JSON(errorStatus(out.Errors, 400), gin.H{"errors": formatErrors(th.Logger, out.Errors), "message": "Process cannot be retried, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Process resumed"})
+}
+
+func (th *CiCdHandler) Rollback(c interfaces.HttpServerContext) {
+	if err := th.setupUseCase.Rollback(c.Param("id")); err != nil {
+		c.JSON(errorStatus([]error{err}, 400), gin.H{"errors": formatErrors(th.Logger, []error{err}), "message": "Process cannot be rolled back"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "message": "Rollback started"})
+}
+
+func (th *CiCdHandler) Cancel(c interfaces.HttpServerContext) {
+	if err := th.setupUseCase.Cancel(c.Param("id")); err != nil {
+		errs := []error{err}
+		c.JSON(errorStatus(errs, inputStatus(errs)), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Process cannot be cancelled"})
+		return
 	}
-	th.Logger.Debug("FORMATTED ERRORS", errs)
-	return errs
+	c.JSON(200, gin.H{"status": "success", "message": "Process cancelled"})
 }
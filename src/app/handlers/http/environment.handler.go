@@ -10,19 +10,25 @@ import (
 
 type EnvironmentHandler struct {
 	*container.Container
-	listEnvironmentsUseCase usecase.ListEnvironmentsUseCase
+	listEnvironmentsUseCase   usecase.ListEnvironmentsUseCase
+	manageEnvironmentsUseCase usecase.ManageEnvironmentsUseCase
 }
 
 func NewEnvironmentHandler(
 	c *container.Container,
 	r interfaces.Router,
 	uc usecase.ListEnvironmentsUseCase,
+	muc usecase.ManageEnvironmentsUseCase,
 ) *EnvironmentHandler {
 	h := &EnvironmentHandler{
 		c,
 		uc,
+		muc,
 	}
 	r.GET("", h.ListEnvironments)
+	r.POST("", h.CreateEnvironment)
+	r.PUT(":code", h.UpdateEnvironment)
+	r.DELETE(":code", h.DeleteEnvironment)
 	return h
 }
 
@@ -34,3 +40,40 @@ func (th *EnvironmentHandler) ListEnvironments(c interfaces.HttpServerContext) {
 	}
 	c.JSON(200, gin.H{"status": "success", "data": l})
 }
+
+func (th *EnvironmentHandler) CreateEnvironment(c interfaces.HttpServerContext) {
+	var requestBody usecase.EnvironmentInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.manageEnvironmentsUseCase.Create(requestBody)
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Environment cannot be created, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Environment created"})
+}
+
+func (th *EnvironmentHandler) UpdateEnvironment(c interfaces.HttpServerContext) {
+	var requestBody usecase.EnvironmentInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.manageEnvironmentsUseCase.Update(c.Param("code"), requestBody)
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Environment cannot be updated, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
+
+func (th *EnvironmentHandler) DeleteEnvironment(c interfaces.HttpServerContext) {
+	errs := th.manageEnvironmentsUseCase.Delete(c.Param("code"))
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Environment cannot be deleted, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "message": "Environment deleted"})
+}
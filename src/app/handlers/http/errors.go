@@ -0,0 +1,63 @@
+package http
+
+import (
+	"errors"
+	customErrors "github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+)
+
+func formatErrors(l logger.Logger, e []error) map[string][]string {
+	errs := make(map[string][]string)
+	for _, err := range e {
+		var ie *customErrors.InputError
+		var ce *customErrors.ConflictError
+		if err == nil {
+			continue
+		}
+		if errors.As(err, &ie) {
+			addErrors(errs, ie.Input, ie.Messages)
+			continue
+		}
+		if errors.As(err, &ce) {
+			addErrors(errs, ce.Input, ce.Messages)
+			continue
+		}
+		l.Error("INTERNAL ERROR", err)
+		addErrors(errs, "internal", []string{err.Error()})
+	}
+	l.Debug("FORMATTED ERRORS", errs)
+	return errs
+}
+
+func addErrors(errs map[string][]string, input string, messages []string) {
+	if _, ok := errs[input]; !ok {
+		errs[input] = []string{}
+	}
+	for _, msg := range messages {
+		errs[input] = append(errs[input], msg)
+	}
+}
+
+// errorStatus returns 409 when any of the errors is a conflict, otherwise the
+// given status.
+func errorStatus(e []error, status int) int {
+	for _, err := range e {
+		var ce *customErrors.ConflictError
+		if errors.As(err, &ce) {
+			return 409
+		}
+	}
+	return status
+}
+
+// inputStatus returns 400 when every error is an input error, otherwise the
+// request failed on our side.
+func inputStatus(e []error) int {
+	for _, err := range e {
+		var ie *customErrors.InputError
+		if !errors.As(err, &ie) {
+			return 500
+		}
+	}
+	return 400
+}
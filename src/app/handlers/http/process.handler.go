@@ -0,0 +1,40 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zahirsis/dev-portal-backend/src/app/interfaces"
+	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+type ProcessHandler struct {
+	*container.Container
+	listProcessesUseCase usecase.ListProcessesUseCase
+}
+
+func NewProcessHandler(
+	c *container.Container,
+	r interfaces.Router,
+	uc usecase.ListProcessesUseCase,
+) *ProcessHandler {
+	h := &ProcessHandler{
+		c,
+		uc,
+	}
+	r.GET("processes", h.ListProcesses)
+	return h
+}
+
+func (th *ProcessHandler) ListProcesses(c interfaces.HttpServerContext) {
+	var input usecase.ListProcessesInputDto
+	if err := c.BindQuery(&input); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.listProcessesUseCase.Exec(input)
+	if len(errs) > 0 {
+		c.JSON(400, gin.H{"errors": formatErrors(th.Logger, errs), "message": "Filters are invalid, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
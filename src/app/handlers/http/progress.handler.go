@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/zahirsis/dev-portal-backend/src/app/interfaces"
+	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"strconv"
+	"time"
+)
+
+const keepAliveInterval = 15 * time.Second
+
+type ProgressHandler struct {
+	*container.Container
+	uc usecase.ProgressUseCase
+}
+
+func NewProgressHandler(
+	c *container.Container,
+	r interfaces.Router,
+	uc usecase.ProgressUseCase,
+) *ProgressHandler {
+	h := &ProgressHandler{
+		c,
+		uc,
+	}
+	r.GET("progress/:id", h.Progress)
+	r.GET("progress/:id/stream", h.Stream)
+	return h
+}
+
+// Progress returns the stored progress messages of a process after the given cursor,
+// so clients without websocket or SSE support can poll it.
+func (h *ProgressHandler) Progress(c interfaces.HttpServerContext) {
+	after, err := strconv.ParseInt(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil || after < 0 {
+		c.JSON(400, gin.H{"error": "after must be a positive number"})
+		return
+	}
+	processID := c.Param("id")
+	progress, err := h.uc.After(processID, after)
+	if err != nil {
+		h.Logger.Error("Error reading messages from database: %s", err.Error())
+		c.JSON(500, gin.H{"error": "Error reading the process progress"})
+		return
+	}
+	cursor := after
+	if len(progress) > 0 {
+		cursor = progress[len(progress)-1].Index
+	}
+	c.JSON(200, gin.H{
+		"status":   "success",
+		"data":     progress,
+		"cursor":   cursor,
+		"finished": h.uc.IsFinished(processID),
+	})
+}
+
+// Stream sends the progress messages of a process as Server-Sent Events. Every event
+// carries the message index as its id, so a reconnecting client resumes from the
+// Last-Event-ID header instead of receiving the whole history again.
+func (h *ProgressHandler) Stream(c interfaces.HttpServerContext) {
+	cursor := c.GetHeader("Last-Event-ID")
+	if cursor == "" {
+		cursor = c.DefaultQuery("after", "0")
+	}
+	last, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || last < 0 {
+		c.JSON(400, gin.H{"error": "Last-Event-ID must be a positive number"})
+		return
+	}
+	processID := c.Param("id")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// Subscribing before reading the stored messages avoids losing the ones
+	// saved in between, the duplicates are skipped by their index
+	progressChannel := h.MessageManager.Subscribe(processID)
+	defer h.MessageManager.Unsubscribe(processID, progressChannel)
+
+	oldMessages, err := h.uc.After(processID, last)
+	if err != nil {
+		h.Logger.Error("Error reading messages from database: %s", err.Error())
+	}
+	for _, oldMessage := range oldMessages {
+		jsonOldMessage, _ := json.Marshal(oldMessage)
+		h.sendProgress(c, oldMessage.Index, jsonOldMessage)
+		last = oldMessage.Index
+	}
+	if h.uc.IsFinished(processID) {
+		h.sendEnd(c)
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case message, ok := <-progressChannel:
+			if !ok {
+				// The channel is closed once the process is finished
+				h.sendEnd(c)
+				return
+			}
+			var progress entity.Progress
+			if err := json.Unmarshal(message, &progress); err != nil {
+				h.Logger.Error("Error reading progress message: %s", err.Error())
+				continue
+			}
+			if progress.Index <= last {
+				continue
+			}
+			h.sendProgress(c, progress.Index, message)
+			last = progress.Index
+		}
+	}
+}
+
+func (h *ProgressHandler) sendProgress(c interfaces.HttpServerContext, index int64, message []byte) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(index, 10),
+		Event: "progress",
+		Data:  string(message),
+	})
+	c.Writer.Flush()
+}
+
+func (h *ProgressHandler) sendEnd(c interfaces.HttpServerContext) {
+	c.Render(-1, sse.Event{Event: "end", Data: ""})
+	c.Writer.Flush()
+}
@@ -10,19 +10,29 @@ import (
 
 type SquadHandler struct {
 	*container.Container
-	listSquadsUseCase usecase.ListSquadsUseCase
+	listSquadsUseCase   usecase.ListSquadsUseCase
+	getSquadUseCase     usecase.GetSquadUseCase
+	manageSquadsUseCase usecase.ManageSquadsUseCase
 }
 
 func NewSquadHandler(
 	c *container.Container,
 	r interfaces.Router,
 	uc usecase.ListSquadsUseCase,
+	guc usecase.GetSquadUseCase,
+	muc usecase.ManageSquadsUseCase,
 ) *SquadHandler {
 	h := &SquadHandler{
 		c,
 		uc,
+		guc,
+		muc,
 	}
 	r.GET("", h.ListSquads)
+	r.GET(":code", h.GetSquad)
+	r.POST("", h.CreateSquad)
+	r.PUT(":code", h.UpdateSquad)
+	r.DELETE(":code", h.DeleteSquad)
 	return h
 }
 
@@ -34,3 +44,53 @@ func (th *SquadHandler) ListSquads(c interfaces.HttpServerContext) {
 	}
 	c.JSON(200, gin.H{"status": "success", "data": l})
 }
+
+func (th *SquadHandler) GetSquad(c interfaces.HttpServerContext) {
+	squad, err := th.getSquadUseCase.Exec(c.Param("code"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if squad == nil {
+		c.JSON(404, gin.H{"errors": gin.H{"code": []string{"squad not found"}}, "message": "Squad not found"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": squad})
+}
+
+func (th *SquadHandler) CreateSquad(c interfaces.HttpServerContext) {
+	var requestBody usecase.SquadDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.manageSquadsUseCase.Create(requestBody)
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Squad cannot be created, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out, "message": "Squad created"})
+}
+
+func (th *SquadHandler) UpdateSquad(c interfaces.HttpServerContext) {
+	var requestBody usecase.SquadDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.manageSquadsUseCase.Update(c.Param("code"), requestBody)
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Squad cannot be updated, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
+
+func (th *SquadHandler) DeleteSquad(c interfaces.HttpServerContext) {
+	errs := th.manageSquadsUseCase.Delete(c.Param("code"))
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Squad cannot be deleted, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "message": "Squad deleted"})
+}
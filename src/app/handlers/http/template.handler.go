@@ -11,18 +11,22 @@ import (
 type TemplateHandler struct {
 	*container.Container
 	listTemplatesUseCase usecase.ListTemplatesUseCase
+	setupUseCase         usecase.SetupCiCdUseCase
 }
 
 func NewTemplateHandler(
 	c *container.Container,
 	r interfaces.Router,
 	uc usecase.ListTemplatesUseCase,
+	suc usecase.SetupCiCdUseCase,
 ) *TemplateHandler {
 	h := &TemplateHandler{
 		c,
 		uc,
+		suc,
 	}
 	r.GET("", h.ListTemplates)
+	r.POST(":code/render", h.Render)
 	return h
 }
 
@@ -34,3 +38,17 @@ func (th *TemplateHandler) ListTemplates(c interfaces.HttpServerContext) {
 	}
 	c.JSON(200, gin.H{"status": "success", "data": l})
 }
+
+func (th *TemplateHandler) Render(c interfaces.HttpServerContext) {
+	var requestBody usecase.CiCdInputDto
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	out, errs := th.setupUseCase.Render(c.Request.Context(), c.Param("code"), requestBody)
+	if len(errs) > 0 {
+		c.JSON(errorStatus(errs, 400), gin.H{"errors": formatErrors(th.Logger, errs), "message": "Template cannot be rendered, please check the errors"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
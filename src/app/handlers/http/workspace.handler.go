@@ -0,0 +1,45 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zahirsis/dev-portal-backend/src/app/interfaces"
+	"github.com/zahirsis/dev-portal-backend/src/app/usecase"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+type WorkspaceHandler struct {
+	*container.Container
+	workspacesUseCase usecase.WorkspacesUseCase
+}
+
+func NewWorkspaceHandler(
+	c *container.Container,
+	r interfaces.Router,
+	uc usecase.WorkspacesUseCase,
+) *WorkspaceHandler {
+	h := &WorkspaceHandler{
+		c,
+		uc,
+	}
+	r.GET("workspaces", h.Usage)
+	r.POST("workspaces/clean", h.Clean)
+	return h
+}
+
+func (th *WorkspaceHandler) Usage(c interfaces.HttpServerContext) {
+	out, err := th.workspacesUseCase.Usage()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
+
+func (th *WorkspaceHandler) Clean(c interfaces.HttpServerContext) {
+	out, err := th.workspacesUseCase.Clean()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"status": "success", "data": out})
+}
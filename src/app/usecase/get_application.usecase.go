@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+type GetApplicationUseCase interface {
+	// Exec returns the application of the catalog, or nil when it's unknown.
+	Exec(slug string) (*entity.Application, error)
+}
+
+type getApplicationUseCase struct {
+	*container.Container
+}
+
+func NewGetApplicationUseCase(c *container.Container) GetApplicationUseCase {
+	return &getApplicationUseCase{c}
+}
+
+func (uc *getApplicationUseCase) Exec(slug string) (*entity.Application, error) {
+	return uc.Repositories.ApplicationRepository.Get(slug)
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+type GetSquadUseCase interface {
+	// Exec returns the squad, or nil when it's unknown.
+	Exec(code string) (*SquadDto, error)
+}
+
+type getSquadUseCase struct {
+	*container.Container
+}
+
+func NewGetSquadUseCase(c *container.Container) GetSquadUseCase {
+	return &getSquadUseCase{c}
+}
+
+func (uc *getSquadUseCase) Exec(code string) (*SquadDto, error) {
+	l, err := uc.Repositories.SquadRepository.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range l {
+		if v.Code() == code {
+			squad := newSquadDto(v)
+			return &squad, nil
+		}
+	}
+	return nil, nil
+}
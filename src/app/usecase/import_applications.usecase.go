@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/service"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"strings"
+	"time"
+)
+
+type ImportApplicationsInputDto struct {
+	Template string `json:"template"`
+	Squad    string `json:"squad"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+type ImportedApplicationDto struct {
+	Slug         string   `json:"slug"`
+	Squad        string   `json:"squad"`
+	Template     string   `json:"template"`
+	Environments []string `json:"environments"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+type SkippedApplicationDto struct {
+	Slug   string `json:"slug"`
+	Reason string `json:"reason"`
+}
+
+type ImportApplicationsOutputDto struct {
+	Imported []ImportedApplicationDto `json:"imported"`
+	Skipped  []SkippedApplicationDto  `json:"skipped"`
+}
+
+type ImportApplicationsUseCase interface {
+	Exec(ctx context.Context, i ImportApplicationsInputDto) (*ImportApplicationsOutputDto, []error)
+}
+
+type importApplicationsUseCase struct {
+	*container.Container
+	config *config.Config
+}
+
+func NewImportApplicationsUseCase(c *container.Container, cfg *config.Config) ImportApplicationsUseCase {
+	return &importApplicationsUseCase{c, cfg}
+}
+
+// Exec reads the applications found on the GitOps repositories with the
+// layout of the templates' GitOps manifests and records the ones unknown to
+// the portal, as if they were set up by it.
+func (uc *importApplicationsUseCase) Exec(ctx context.Context, i ImportApplicationsInputDto) (*ImportApplicationsOutputDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: applications/import", i)
+	var templates []entity.TemplateEntity
+	if i.Template != "" {
+		template, err := uc.Repositories.TemplateRepository.Get(i.Template)
+		if err != nil {
+			return nil, []error{errors.NewInputError("template", []string{err.Error()})}
+		}
+		templates = append(templates, template)
+	} else {
+		list, err := uc.Repositories.TemplateRepository.List()
+		if err != nil {
+			return nil, []error{err}
+		}
+		templates = list
+	}
+
+	importID := "import-" + uc.MessageManager.GenerateID()
+	sc := uc.config.SetupCiCd
+	templatesDir := strings.Replace(sc.TemplatesDestinationDir, "{{process-id}}", importID, -1)
+	gitOpsDir := strings.Replace(sc.GitOpsDestinationDir, "{{process-id}}", importID, -1)
+	gitOpsToolsDir := strings.Replace(sc.GitOpsToolsDestinationDir, "{{process-id}}", importID, -1)
+	for _, dir := range []string{templatesDir, gitOpsDir, gitOpsToolsDir} {
+		defer func(dir string) {
+			if err := uc.Services.DirectoryService.RemoveDirectory(dir); err != nil {
+				uc.Logger.Error("Error removing %s: %s", dir, err.Error())
+			}
+		}(dir)
+	}
+	if err := uc.Services.GitService.CloneRepository(ctx, sc.TemplatesRepository, sc.TemplatesRepositoryBranch, templatesDir); err != nil {
+		return nil, []error{err}
+	}
+	if err := uc.Services.GitService.CloneRepository(ctx, sc.GitOpsRepository, sc.GitOpsRepositoryBranch, gitOpsDir); err != nil {
+		return nil, []error{err}
+	}
+	if err := uc.Services.GitService.CloneRepository(ctx, sc.GitOpsToolsRepository, sc.GitOpsToolsRepositoryBranch, gitOpsToolsDir); err != nil {
+		return nil, []error{err}
+	}
+
+	// The applications are found by their namespace, which is the code of the
+	// squad unless overridden
+	squadList, err := uc.Repositories.SquadRepository.List()
+	if err != nil {
+		return nil, []error{err}
+	}
+	squads := make(map[string]entity.SquadEntity, len(squadList))
+	for _, squad := range squadList {
+		squads[squad.Namespace()] = squad
+	}
+
+	out := &ImportApplicationsOutputDto{Imported: []ImportedApplicationDto{}, Skipped: []SkippedApplicationDto{}}
+	// The templates usually share the same layout, an application is
+	// imported with the first template finding it
+	seen := make(map[string]bool)
+	for _, template := range templates {
+		for _, m := range template.Manifests() {
+			if m.Type != entity.GitOpsManifests {
+				continue
+			}
+			cfg, err := uc.Services.GitOpsService.LoadConfig(m, templatesDir)
+			if err != nil {
+				return nil, []error{err}
+			}
+			applications, err := uc.Services.GitOpsService.FindK8sApplications(cfg, gitOpsDir, gitOpsToolsDir)
+			if err != nil {
+				return nil, []error{err}
+			}
+			for _, a := range applications {
+				squad := squads[a.Namespace]
+				if seen[a.Slug] || (i.Squad != "" && (squad == nil || squad.Code() != i.Squad)) {
+					continue
+				}
+				seen[a.Slug] = true
+				imported, reason, err := uc.importApplication(importID, template, m, cfg, a, squad, i.DryRun)
+				if err != nil {
+					return nil, []error{err}
+				}
+				if reason != "" {
+					out.Skipped = append(out.Skipped, SkippedApplicationDto{Slug: a.Slug, Reason: reason})
+					continue
+				}
+				out.Imported = append(out.Imported, *imported)
+			}
+		}
+	}
+	return out, nil
+}
+
+// importApplication records an application found on the GitOps repositories,
+// the reason is returned when it's skipped.
+func (uc *importApplicationsUseCase) importApplication(importID string, template entity.TemplateEntity, m *entity.Manifest, cfg *entity.GitOpsConfig, a *service.K8sApplication, squad entity.SquadEntity, dryRun bool) (*ImportedApplicationDto, string, error) {
+	existing, err := uc.Repositories.ApplicationRepository.Get(a.Slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing != nil {
+		return nil, "application already in the portal", nil
+	}
+	if squad == nil {
+		return nil, fmt.Sprintf("no squad found for namespace %s", a.Namespace), nil
+	}
+
+	out := &ImportedApplicationDto{Slug: a.Slug, Squad: squad.Code(), Template: template.Code()}
+	input := CiCdInputDto{
+		Template:  template.Code(),
+		Manifests: []string{m.Code},
+		Squad:     squad.Code(),
+		Application: entity.ApplicationData{
+			Name:            a.Slug,
+			RootPath:        "/",
+			HealthCheckPath: a.HealthCheckPath,
+			Resources:       a.Resources,
+			Port:            a.Port,
+		},
+	}
+	created := entity.CreatedData{
+		Environments:  []*entity.EnvironmentCreatedData{},
+		GitOpsPath:    uc.config.GitConfig.GetRepositoryUrl(uc.config.SetupCiCd.GitOpsRepository) + "/" + a.Path,
+		RepositoryUrl: uc.config.GitConfig.GetRepositoryUrl(a.Slug),
+	}
+	for _, e := range a.Environments {
+		env, err := uc.Repositories.EnvironmentRepository.Get(e.Code)
+		if err != nil || env == nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("environment %s unknown to the portal, ignored", e.Code))
+			continue
+		}
+		if !e.ArgoApplication {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("Argo Application not found for %s environment", e.Code))
+		}
+		replicas := e.Replicas
+		if replicas.Max == 0 {
+			replicas.Min = int(env.DefaultReplicas().Min.Value)
+			replicas.Max = int(env.DefaultReplicas().Max.Value)
+			out.Warnings = append(out.Warnings, fmt.Sprintf("replicas not found for %s environment, using its defaults", e.Code))
+		}
+		input.Envs = append(input.Envs, EnvInputDto{Code: env.Code(), Replicas: replicas})
+		url := ""
+		if e.Host != "" {
+			url = e.Host + e.Path
+		}
+		created.Environments = append(created.Environments, &entity.EnvironmentCreatedData{
+			Label:           env.Label(),
+			Code:            env.Code(),
+			Url:             url,
+			ApplicationName: a.Slug,
+		})
+		if created.RegistryUrl == "" {
+			created.RegistryUrl = imageRepository(e.Image)
+		}
+		out.Environments = append(out.Environments, env.Code())
+	}
+	if len(input.Envs) == 0 {
+		return nil, "no environment known to the portal", nil
+	}
+	if uc.config.SetupCiCd.ExternalConfigMap {
+		cmPath := strings.NewReplacer("<namespace>", a.Namespace, "<applicationName>", a.Slug).Replace(cfg.K8sConfigMapDestinationPath)
+		created.ConfigMapPath = uc.config.GitConfig.GetRepositoryUrl(uc.config.SetupCiCd.ConfigMapRepository) + "/src/" + uc.config.SetupCiCd.ConfigMapRepositoryBranch + "/" + cmPath
+	}
+	if dryRun {
+		return out, "", nil
+	}
+	rawInput, err := json.Marshal(input)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	err = uc.Repositories.ApplicationRepository.Save(entity.Application{
+		Slug:         a.Slug,
+		Name:         a.Slug,
+		Squad:        squad.Code(),
+		Template:     template.Code(),
+		Environments: out.Environments,
+		ProcessID:    importID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		CreatedData:  &created,
+		Input:        rawInput,
+		Imported:     true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return out, "", nil
+}
+
+// imageRepository removes the tag and the digest of an image.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+const maxApplicationsPageSize = 100
+
+type ListApplicationsInputDto struct {
+	Squad       string `form:"squad"`
+	Template    string `form:"template"`
+	Environment string `form:"environment"`
+	Page        int    `form:"page"`
+	PageSize    int    `form:"pageSize"`
+}
+
+type ListApplicationsOutputDto struct {
+	Items    []entity.Application `json:"items"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
+type ListApplicationsUseCase interface {
+	Exec(i ListApplicationsInputDto) (*ListApplicationsOutputDto, []error)
+}
+
+type listApplicationsUseCase struct {
+	*container.Container
+}
+
+func NewListApplicationsUseCase(c *container.Container) ListApplicationsUseCase {
+	return &listApplicationsUseCase{c}
+}
+
+func (uc *listApplicationsUseCase) Exec(i ListApplicationsInputDto) (*ListApplicationsOutputDto, []error) {
+	if i.Page == 0 {
+		i.Page = 1
+	}
+	if i.PageSize == 0 {
+		i.PageSize = 20
+	}
+	var errs []error
+	if i.Page < 0 {
+		errs = append(errs, errors.NewInputError("page", []string{"page must be greater than zero"}))
+	}
+	if i.PageSize < 0 || i.PageSize > maxApplicationsPageSize {
+		errs = append(errs, errors.NewInputError("pageSize", []string{"pageSize must be between 1 and 100"}))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	l, total, err := uc.Repositories.ApplicationRepository.List(entity.ApplicationFilter{
+		Squad:       i.Squad,
+		Template:    i.Template,
+		Environment: i.Environment,
+		Offset:      (i.Page - 1) * i.PageSize,
+		Limit:       i.PageSize,
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return &ListApplicationsOutputDto{Items: l, Total: total, Page: i.Page, PageSize: i.PageSize}, nil
+}
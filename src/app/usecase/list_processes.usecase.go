@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+)
+
+const maxProcessesPageSize = 100
+
+type ListProcessesInputDto struct {
+	Squad       string `form:"squad"`
+	Application string `form:"application"`
+	Status      string `form:"status"`
+	Page        int    `form:"page"`
+	PageSize    int    `form:"pageSize"`
+}
+
+type ListProcessesOutputDto struct {
+	Items    []entity.ProcessMetadata `json:"items"`
+	Total    int                      `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"pageSize"`
+}
+
+type ListProcessesUseCase interface {
+	Exec(i ListProcessesInputDto) (*ListProcessesOutputDto, []error)
+}
+
+type listProcessesUseCase struct {
+	*container.Container
+}
+
+func NewListProcessesUseCase(c *container.Container) ListProcessesUseCase {
+	return &listProcessesUseCase{c}
+}
+
+func (uc *listProcessesUseCase) Exec(i ListProcessesInputDto) (*ListProcessesOutputDto, []error) {
+	if i.Page == 0 {
+		i.Page = 1
+	}
+	if i.PageSize == 0 {
+		i.PageSize = 20
+	}
+	var errs []error
+	if i.Page < 0 {
+		errs = append(errs, errors.NewInputError("page", []string{"page must be greater than zero"}))
+	}
+	if i.PageSize < 0 || i.PageSize > maxProcessesPageSize {
+		errs = append(errs, errors.NewInputError("pageSize", []string{"pageSize must be between 1 and 100"}))
+	}
+	switch i.Status {
+	case "", string(entity.ProcessQueued), string(entity.ProcessRunning), string(entity.ProcessFinished),
+		string(entity.ResultSuccess), string(entity.ResultError), string(entity.ResultCancelled), string(entity.ResultPending):
+	default:
+		errs = append(errs, errors.NewInputError("status", []string{"status is invalid"}))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	l, total, err := uc.Repositories.ProgressRepository.ListMetadata(entity.ProcessFilter{
+		Squad:       i.Squad,
+		Application: i.Application,
+		Status:      i.Status,
+		Offset:      (i.Page - 1) * i.PageSize,
+		Limit:       i.PageSize,
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return &ListProcessesOutputDto{Items: l, Total: total, Page: i.Page, PageSize: i.PageSize}, nil
+}
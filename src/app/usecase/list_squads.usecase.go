@@ -1,12 +1,30 @@
 package usecase
 
 import (
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
 )
 
 type SquadDto struct {
-	Code  string `json:"code"`
-	Label string `json:"label"`
+	Code             string   `json:"code"`
+	Label            string   `json:"label"`
+	Owners           []string `json:"owners"`
+	SlackChannel     string   `json:"slack_channel"`
+	CostCenter       string   `json:"cost_center"`
+	DefaultReviewers []string `json:"default_reviewers"`
+	Namespace        string   `json:"namespace"`
+}
+
+func newSquadDto(s entity.SquadEntity) SquadDto {
+	return SquadDto{
+		Code:             s.Code(),
+		Label:            s.Label(),
+		Owners:           s.Owners(),
+		SlackChannel:     s.SlackChannel(),
+		CostCenter:       s.CostCenter(),
+		DefaultReviewers: s.DefaultReviewers(),
+		Namespace:        s.Namespace(),
+	}
 }
 
 type ListSquadsUseCase interface {
@@ -28,7 +46,7 @@ func (uc *listSquadsUseCase) Exec() ([]SquadDto, error) {
 		return nil, err
 	}
 	for _, v := range l {
-		r = append(r, SquadDto{v.Code(), v.Label()})
+		r = append(r, newSquadDto(v))
 	}
 	return r, nil
 }
@@ -11,6 +11,8 @@ type TemplateDto struct {
 	ApplicationDefaults entity.ApplicationObject `json:"applicationDefaults"`
 	IngressDefaults     entity.IngressObject     `json:"ingressDefaults"`
 	Manifests           []*entity.Manifest       `json:"manifests"`
+	// Params is the JSON Schema of the params object of the setup
+	Params map[string]interface{} `json:"params"`
 }
 
 type ListTemplatesUseCase interface {
@@ -32,7 +34,43 @@ func (uc *listTemplatesUseCase) Exec() ([]TemplateDto, error) {
 		return nil, err
 	}
 	for _, v := range l {
-		r = append(r, TemplateDto{v.Code(), v.Label(), v.ApplicationDefault(), v.IngressDefault(), v.Manifests()})
+		r = append(r, TemplateDto{v.Code(), v.Label(), v.ApplicationDefault(), v.IngressDefault(), v.Manifests(), paramsSchema(v.Params())})
 	}
 	return r, nil
 }
+
+// paramsSchema describes the params as a JSON Schema, the environments of a
+// param go on x-environments as the schema has no way to tell them. A
+// required param limited to some environments isn't listed as required.
+func paramsSchema(params []*entity.TemplateParam) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, p := range params {
+		property := map[string]interface{}{"type": p.Type}
+		for key, value := range map[string]string{"title": p.Label, "description": p.Description, "pattern": p.Pattern} {
+			if value != "" {
+				property[key] = value
+			}
+		}
+		if p.Default != nil {
+			property["default"] = p.Default
+		}
+		if len(p.Enum) > 0 {
+			property["enum"] = p.Enum
+		}
+		if len(p.Environments) > 0 {
+			property["x-environments"] = p.Environments
+		}
+		properties[p.Name] = property
+		if p.Required && p.Default == nil && len(p.Environments) == 0 {
+			required = append(required, p.Name)
+		}
+	}
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
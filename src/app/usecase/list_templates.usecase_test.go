@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"reflect"
+	"testing"
+)
+
+func TestParamsSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     []*entity.TemplateParam
+		properties map[string]interface{}
+		required   []string
+	}{
+		{
+			name:       "no params",
+			properties: map[string]interface{}{},
+			required:   []string{},
+		},
+		{
+			name: "every keyword",
+			params: []*entity.TemplateParam{{
+				Name:        "javaVersion",
+				Label:       "Java version",
+				Description: "Version of the JDK image",
+				Type:        entity.StringParam,
+				Default:     "17",
+				Enum:        []interface{}{"17", "21"},
+				Pattern:     "^[0-9]+$",
+			}},
+			properties: map[string]interface{}{
+				"javaVersion": map[string]interface{}{
+					"type":        entity.StringParam,
+					"title":       "Java version",
+					"description": "Version of the JDK image",
+					"pattern":     "^[0-9]+$",
+					"default":     "17",
+					"enum":        []interface{}{"17", "21"},
+				},
+			},
+			required: []string{},
+		},
+		{
+			name: "required params",
+			params: []*entity.TemplateParam{
+				{Name: "workers", Type: entity.IntegerParam, Required: true},
+				{Name: "debug", Type: entity.BooleanParam, Required: true, Default: false},
+				{Name: "certificate", Type: entity.StringParam, Required: true, Environments: []string{"prd"}},
+			},
+			properties: map[string]interface{}{
+				"workers":     map[string]interface{}{"type": entity.IntegerParam},
+				"debug":       map[string]interface{}{"type": entity.BooleanParam, "default": false},
+				"certificate": map[string]interface{}{"type": entity.StringParam, "x-environments": []string{"prd"}},
+			},
+			// A default or an environment makes the param optional
+			required: []string{"workers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := paramsSchema(tt.params)
+			if !reflect.DeepEqual(schema["properties"], tt.properties) {
+				t.Errorf("expected properties %v, got %v", tt.properties, schema["properties"])
+			}
+			if !reflect.DeepEqual(schema["required"], tt.required) {
+				t.Errorf("expected required %v, got %v", tt.required, schema["required"])
+			}
+			if schema["type"] != "object" || schema["additionalProperties"] != false {
+				t.Errorf("unexpected schema %v", schema)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"regexp"
+	"sort"
+)
+
+// dnsLabel matches the codes used on branches, paths and k8s names.
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type EnvironmentInputDto struct {
+	Code               string                `json:"code"`
+	Label              string                `json:"label"`
+	AccentColor        string                `json:"accent_color"`
+	DefaultActive      bool                  `json:"default_active"`
+	DefaultReplicas    entity.ResourceObject `json:"default_replicas"`
+	Concurrences       []string              `json:"concurrences"`
+	RequireApproval    bool                  `json:"require_approval"`
+	DestinationCluster string                `json:"destination_cluster"`
+	Project            string                `json:"project"`
+	SecretsPath        string                `json:"secrets_path"`
+}
+
+type ManageEnvironmentsUseCase interface {
+	Create(i EnvironmentInputDto) (*EnvironmentInputDto, []error)
+	Update(code string, i EnvironmentInputDto) (*EnvironmentInputDto, []error)
+	Delete(code string) []error
+	// Seed saves the environments when none is stored yet, it returns how many
+	// were saved.
+	Seed(environments []entity.EnvironmentEntity) (int, error)
+}
+
+type manageEnvironmentsUseCase struct {
+	*container.Container
+}
+
+func NewManageEnvironmentsUseCase(c *container.Container) ManageEnvironmentsUseCase {
+	return &manageEnvironmentsUseCase{c}
+}
+
+func (uc *manageEnvironmentsUseCase) Create(i EnvironmentInputDto) (*EnvironmentInputDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: environments/create", i)
+	environments, err := uc.environments()
+	if err != nil {
+		return nil, []error{err}
+	}
+	if _, ok := environments[i.Code]; ok {
+		return nil, []error{errors.NewConflictError("code", []string{fmt.Sprintf("environment %s already exists", i.Code)})}
+	}
+	return uc.save(i, environments)
+}
+
+func (uc *manageEnvironmentsUseCase) Update(code string, i EnvironmentInputDto) (*EnvironmentInputDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: environments/update", code, i)
+	environments, err := uc.environments()
+	if err != nil {
+		return nil, []error{err}
+	}
+	if _, ok := environments[code]; !ok {
+		return nil, []error{errors.NewInputError("code", []string{fmt.Sprintf("environment %s not found", code)})}
+	}
+	if i.Code == "" {
+		i.Code = code
+	} else if i.Code != code {
+		return nil, []error{errors.NewInputError("code", []string{"code cannot be changed"})}
+	}
+	return uc.save(i, environments)
+}
+
+// Delete removes an environment no other environment competes with and no
+// application is deployed to.
+func (uc *manageEnvironmentsUseCase) Delete(code string) []error {
+	uc.Logger.Debug("RECEIVED REQUEST: environments/delete", code)
+	environments, err := uc.environments()
+	if err != nil {
+		return []error{err}
+	}
+	if _, ok := environments[code]; !ok {
+		return []error{errors.NewInputError("code", []string{fmt.Sprintf("environment %s not found", code)})}
+	}
+	var referenced []string
+	for _, e := range environments {
+		for _, c := range e.Concurrences() {
+			if c == code {
+				referenced = append(referenced, e.Code())
+			}
+		}
+	}
+	if len(referenced) > 0 {
+		sort.Strings(referenced)
+		return []error{errors.NewConflictError("code", []string{fmt.Sprintf("environment is a concurrence of %v", referenced)})}
+	}
+	_, total, err := uc.Repositories.ApplicationRepository.List(entity.ApplicationFilter{Environment: code, Limit: 1})
+	if err != nil {
+		return []error{err}
+	}
+	if total > 0 {
+		return []error{errors.NewConflictError("code", []string{fmt.Sprintf("environment is used by %d application(s)", total)})}
+	}
+	if err := uc.Repositories.EnvironmentRepository.Delete(code); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// Seed doesn't validate the environments, their concurrences may reference
+// the ones saved after them.
+func (uc *manageEnvironmentsUseCase) Seed(environments []entity.EnvironmentEntity) (int, error) {
+	current, err := uc.Repositories.EnvironmentRepository.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(current) > 0 {
+		return 0, nil
+	}
+	for k, e := range environments {
+		if err := uc.Repositories.EnvironmentRepository.Save(e); err != nil {
+			return k, err
+		}
+	}
+	return len(environments), nil
+}
+
+func (uc *manageEnvironmentsUseCase) environments() (map[string]entity.EnvironmentEntity, error) {
+	list, err := uc.Repositories.EnvironmentRepository.List()
+	if err != nil {
+		return nil, err
+	}
+	environments := make(map[string]entity.EnvironmentEntity, len(list))
+	for _, e := range list {
+		environments[e.Code()] = e
+	}
+	return environments, nil
+}
+
+func (uc *manageEnvironmentsUseCase) save(i EnvironmentInputDto, environments map[string]entity.EnvironmentEntity) (*EnvironmentInputDto, []error) {
+	if errs := validateEnvironment(i, environments); len(errs) > 0 {
+		return nil, errs
+	}
+	if i.Concurrences == nil {
+		i.Concurrences = []string{}
+	}
+	err := uc.Repositories.EnvironmentRepository.Save(entity.NewEnvironmentEntity(&entity.EnvironmentConfig{
+		Code:               i.Code,
+		Label:              i.Label,
+		AccentColor:        i.AccentColor,
+		DefaultActive:      i.DefaultActive,
+		DefaultReplicas:    i.DefaultReplicas,
+		Concurrences:       i.Concurrences,
+		RequireApproval:    i.RequireApproval,
+		DestinationCluster: i.DestinationCluster,
+		Project:            i.Project,
+		SecretsPath:        i.SecretsPath,
+	}))
+	if err != nil {
+		return nil, []error{err}
+	}
+	return &i, nil
+}
+
+func validateEnvironment(i EnvironmentInputDto, environments map[string]entity.EnvironmentEntity) []error {
+	var errs []error
+	if !dnsLabel.MatchString(i.Code) {
+		errs = append(errs, errors.NewInputError("code", []string{"code must have only lowercase letters, numbers and hyphens"}))
+	}
+	for _, field := range []struct{ name, value string }{
+		{"label", i.Label},
+		{"destination_cluster", i.DestinationCluster},
+		{"project", i.Project},
+		{"secrets_path", i.SecretsPath},
+	} {
+		if field.value == "" {
+			errs = append(errs, errors.NewInputError(field.name, []string{field.name + " cannot be empty"}))
+		}
+	}
+	seen := make(map[string]bool)
+	for _, c := range i.Concurrences {
+		switch {
+		case c == i.Code:
+			errs = append(errs, errors.NewInputError("concurrences", []string{"environment cannot be a concurrence of itself"}))
+		case seen[c]:
+			errs = append(errs, errors.NewInputError("concurrences", []string{fmt.Sprintf("environment %s is repeated", c)}))
+		case environments[c] == nil:
+			errs = append(errs, errors.NewInputError("concurrences", []string{fmt.Sprintf("environment %s not found", c)}))
+		}
+		seen[c] = true
+	}
+	replicas := i.DefaultReplicas
+	for _, field := range []struct {
+		name  string
+		value entity.NumberValueObject
+	}{
+		{"default_replicas.min", replicas.Min},
+		{"default_replicas.max", replicas.Max},
+	} {
+		value := field.value
+		if value.Step <= 0 {
+			errs = append(errs, errors.NewInputError(field.name, []string{"step must be greater than zero"}))
+		}
+		if value.Min < 0 || value.Min > value.Max {
+			errs = append(errs, errors.NewInputError(field.name, []string{"min must be between zero and max"}))
+		} else if value.Value < value.Min || value.Value > value.Max {
+			errs = append(errs, errors.NewInputError(field.name, []string{fmt.Sprintf("value must be between %v and %v", value.Min, value.Max)}))
+		}
+	}
+	if replicas.Min.Value > replicas.Max.Value {
+		errs = append(errs, errors.NewInputError("default_replicas", []string{"min value cannot be greater than the max value"}))
+	}
+	return errs
+}
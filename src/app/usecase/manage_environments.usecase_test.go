@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"reflect"
+	"testing"
+)
+
+func TestValidateEnvironment(t *testing.T) {
+	replicas := func(min, max float32) entity.ResourceObject {
+		return entity.ResourceObject{
+			Min: entity.NumberValueObject{Value: min, Step: 1, Min: 1, Max: 10},
+			Max: entity.NumberValueObject{Value: max, Step: 1, Min: 1, Max: 10},
+		}
+	}
+	valid := EnvironmentInputDto{
+		Code:               "prd",
+		Label:              "Production",
+		DefaultReplicas:    replicas(2, 4),
+		Concurrences:       []string{"stg"},
+		DestinationCluster: "https://kubernetes.default.svc",
+		Project:            "default",
+		SecretsPath:        "secrets/prd",
+	}
+	environments := map[string]entity.EnvironmentEntity{
+		"stg": entity.NewEnvironmentEntity(&entity.EnvironmentConfig{Code: "stg"}),
+	}
+	tests := []struct {
+		name     string
+		change   func(i *EnvironmentInputDto)
+		expected []error
+	}{
+		{
+			name:   "valid environment",
+			change: func(i *EnvironmentInputDto) {},
+		},
+		{
+			name: "invalid code and empty fields",
+			change: func(i *EnvironmentInputDto) {
+				i.Code = "Prd"
+				i.Label = ""
+				i.SecretsPath = ""
+			},
+			expected: []error{
+				errors.NewInputError("code", []string{"code must have only lowercase letters, numbers and hyphens"}),
+				errors.NewInputError("label", []string{"label cannot be empty"}),
+				errors.NewInputError("secrets_path", []string{"secrets_path cannot be empty"}),
+			},
+		},
+		{
+			name:   "concurrences must exist",
+			change: func(i *EnvironmentInputDto) { i.Concurrences = []string{"stg", "prd", "stg", "dev"} },
+			expected: []error{
+				errors.NewInputError("concurrences", []string{"environment cannot be a concurrence of itself"}),
+				errors.NewInputError("concurrences", []string{"environment stg is repeated"}),
+				errors.NewInputError("concurrences", []string{"environment dev not found"}),
+			},
+		},
+		{
+			name: "replicas out of their bounds",
+			change: func(i *EnvironmentInputDto) {
+				i.DefaultReplicas.Min.Step = 0
+				i.DefaultReplicas.Max.Value = 12
+			},
+			expected: []error{
+				errors.NewInputError("default_replicas.min", []string{"step must be greater than zero"}),
+				errors.NewInputError("default_replicas.max", []string{"value must be between 1 and 10"}),
+			},
+		},
+		{
+			name:   "replicas bounds inverted",
+			change: func(i *EnvironmentInputDto) { i.DefaultReplicas.Max.Min = 11 },
+			expected: []error{
+				errors.NewInputError("default_replicas.max", []string{"min must be between zero and max"}),
+			},
+		},
+		{
+			name:   "min replicas greater than the max",
+			change: func(i *EnvironmentInputDto) { i.DefaultReplicas = replicas(5, 3) },
+			expected: []error{
+				errors.NewInputError("default_replicas", []string{"min value cannot be greater than the max value"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := valid
+			i.Concurrences = append([]string{}, valid.Concurrences...)
+			tt.change(&i)
+			errs := validateEnvironment(i, environments)
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"regexp"
+	"strings"
+)
+
+var slackChannel = regexp.MustCompile(`^[a-z0-9_-]{1,80}$`)
+
+type ManageSquadsUseCase interface {
+	Create(i SquadDto) (*SquadDto, []error)
+	Update(code string, i SquadDto) (*SquadDto, []error)
+	Delete(code string) []error
+	// Seed saves the squads when none is stored yet, it returns how many were
+	// saved.
+	Seed(squads []entity.SquadEntity) (int, error)
+}
+
+type manageSquadsUseCase struct {
+	*container.Container
+}
+
+func NewManageSquadsUseCase(c *container.Container) ManageSquadsUseCase {
+	return &manageSquadsUseCase{c}
+}
+
+func (uc *manageSquadsUseCase) Create(i SquadDto) (*SquadDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: squads/create", i)
+	squads, err := uc.squads()
+	if err != nil {
+		return nil, []error{err}
+	}
+	if _, ok := squads[i.Code]; ok {
+		return nil, []error{errors.NewConflictError("code", []string{fmt.Sprintf("squad %s already exists", i.Code)})}
+	}
+	return uc.save(i, squads)
+}
+
+// Update doesn't change the namespace of a squad with applications, they
+// are deployed to the current one.
+func (uc *manageSquadsUseCase) Update(code string, i SquadDto) (*SquadDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: squads/update", code, i)
+	squads, err := uc.squads()
+	if err != nil {
+		return nil, []error{err}
+	}
+	current, ok := squads[code]
+	if !ok {
+		return nil, []error{errors.NewInputError("code", []string{fmt.Sprintf("squad %s not found", code)})}
+	}
+	if i.Code == "" {
+		i.Code = code
+	} else if i.Code != code {
+		return nil, []error{errors.NewInputError("code", []string{"code cannot be changed"})}
+	}
+	if squadNamespace(i) != current.Namespace() {
+		total, err := uc.applications(code)
+		if err != nil {
+			return nil, []error{err}
+		}
+		if total > 0 {
+			return nil, []error{errors.NewConflictError("namespace", []string{fmt.Sprintf("namespace cannot be changed, it's used by %d application(s)", total)})}
+		}
+	}
+	return uc.save(i, squads)
+}
+
+// Delete removes a squad without applications.
+func (uc *manageSquadsUseCase) Delete(code string) []error {
+	uc.Logger.Debug("RECEIVED REQUEST: squads/delete", code)
+	squads, err := uc.squads()
+	if err != nil {
+		return []error{err}
+	}
+	if _, ok := squads[code]; !ok {
+		return []error{errors.NewInputError("code", []string{fmt.Sprintf("squad %s not found", code)})}
+	}
+	total, err := uc.applications(code)
+	if err != nil {
+		return []error{err}
+	}
+	if total > 0 {
+		return []error{errors.NewConflictError("code", []string{fmt.Sprintf("squad has %d application(s)", total)})}
+	}
+	if err := uc.Repositories.SquadRepository.Delete(code); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+func (uc *manageSquadsUseCase) Seed(squads []entity.SquadEntity) (int, error) {
+	current, err := uc.Repositories.SquadRepository.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(current) > 0 {
+		return 0, nil
+	}
+	for k, s := range squads {
+		if err := uc.Repositories.SquadRepository.Save(s); err != nil {
+			return k, err
+		}
+	}
+	return len(squads), nil
+}
+
+func (uc *manageSquadsUseCase) squads() (map[string]entity.SquadEntity, error) {
+	list, err := uc.Repositories.SquadRepository.List()
+	if err != nil {
+		return nil, err
+	}
+	squads := make(map[string]entity.SquadEntity, len(list))
+	for _, s := range list {
+		squads[s.Code()] = s
+	}
+	return squads, nil
+}
+
+func (uc *manageSquadsUseCase) applications(code string) (int, error) {
+	_, total, err := uc.Repositories.ApplicationRepository.List(entity.ApplicationFilter{Squad: code, Limit: 1})
+	return total, err
+}
+
+func (uc *manageSquadsUseCase) save(i SquadDto, squads map[string]entity.SquadEntity) (*SquadDto, []error) {
+	i.SlackChannel = strings.TrimPrefix(i.SlackChannel, "#")
+	if errs := validateSquad(i, squads); len(errs) > 0 {
+		return nil, errs
+	}
+	config := &entity.SquadConfig{
+		Code:             i.Code,
+		Label:            i.Label,
+		Owners:           i.Owners,
+		SlackChannel:     i.SlackChannel,
+		CostCenter:       i.CostCenter,
+		DefaultReviewers: i.DefaultReviewers,
+	}
+	// The namespace is only stored when overridden
+	if i.Namespace != i.Code {
+		config.Namespace = i.Namespace
+	}
+	squad := entity.NewSquadEntity(config)
+	if err := uc.Repositories.SquadRepository.Save(squad); err != nil {
+		return nil, []error{err}
+	}
+	out := newSquadDto(squad)
+	return &out, nil
+}
+
+// squadNamespace returns the namespace the squad will have.
+func squadNamespace(i SquadDto) string {
+	if i.Namespace != "" {
+		return i.Namespace
+	}
+	return i.Code
+}
+
+func validateSquad(i SquadDto, squads map[string]entity.SquadEntity) []error {
+	var errs []error
+	if !dnsLabel.MatchString(i.Code) {
+		errs = append(errs, errors.NewInputError("code", []string{"code must have only lowercase letters, numbers and hyphens"}))
+	}
+	if i.Label == "" {
+		errs = append(errs, errors.NewInputError("label", []string{"label cannot be empty"}))
+	}
+	if i.Namespace != "" && !dnsLabel.MatchString(i.Namespace) {
+		errs = append(errs, errors.NewInputError("namespace", []string{"namespace must have only lowercase letters, numbers and hyphens"}))
+	}
+	for _, s := range squads {
+		if s.Code() != i.Code && s.Namespace() == squadNamespace(i) {
+			errs = append(errs, errors.NewConflictError("namespace", []string{fmt.Sprintf("namespace %s is used by %s squad", s.Namespace(), s.Code())}))
+		}
+	}
+	if i.SlackChannel != "" && !slackChannel.MatchString(i.SlackChannel) {
+		errs = append(errs, errors.NewInputError("slack_channel", []string{"slack channel must have only lowercase letters, numbers, hyphens and underscores"}))
+	}
+	for _, field := range []struct {
+		name   string
+		values []string
+	}{
+		{"owners", i.Owners},
+		{"default_reviewers", i.DefaultReviewers},
+	} {
+		seen := make(map[string]bool)
+		for _, v := range field.values {
+			if strings.TrimSpace(v) == "" {
+				errs = append(errs, errors.NewInputError(field.name, []string{"values cannot be empty"}))
+			} else if seen[v] {
+				errs = append(errs, errors.NewInputError(field.name, []string{fmt.Sprintf("%s is repeated", v)}))
+			}
+			seen[v] = true
+		}
+	}
+	return errs
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"reflect"
+	"testing"
+)
+
+func TestValidateSquad(t *testing.T) {
+	valid := SquadDto{
+		Code:             "payments",
+		Label:            "Payments",
+		Owners:           []string{"ana@example.com"},
+		SlackChannel:     "payments-team",
+		DefaultReviewers: []string{"ana", "bruno"},
+	}
+	squads := map[string]entity.SquadEntity{
+		"payments": entity.NewSquadEntity(&entity.SquadConfig{Code: "payments"}),
+		"checkout": entity.NewSquadEntity(&entity.SquadConfig{Code: "checkout", Namespace: "shop"}),
+	}
+	tests := []struct {
+		name     string
+		change   func(i *SquadDto)
+		expected []error
+	}{
+		{
+			name:   "valid squad",
+			change: func(i *SquadDto) {},
+		},
+		{
+			name:   "own namespace",
+			change: func(i *SquadDto) { i.Namespace = "payments" },
+		},
+		{
+			name: "invalid code, label and namespace",
+			change: func(i *SquadDto) {
+				i.Code = "-payments"
+				i.Label = ""
+				i.Namespace = "Payments"
+			},
+			expected: []error{
+				errors.NewInputError("code", []string{"code must have only lowercase letters, numbers and hyphens"}),
+				errors.NewInputError("label", []string{"label cannot be empty"}),
+				errors.NewInputError("namespace", []string{"namespace must have only lowercase letters, numbers and hyphens"}),
+			},
+		},
+		{
+			name:   "namespace of another squad",
+			change: func(i *SquadDto) { i.Namespace = "shop" },
+			expected: []error{
+				errors.NewConflictError("namespace", []string{"namespace shop is used by checkout squad"}),
+			},
+		},
+		{
+			name:   "code used as namespace by another squad",
+			change: func(i *SquadDto) { i.Code = "shop" },
+			expected: []error{
+				errors.NewConflictError("namespace", []string{"namespace shop is used by checkout squad"}),
+			},
+		},
+		{
+			name:   "invalid slack channel",
+			change: func(i *SquadDto) { i.SlackChannel = "#Payments" },
+			expected: []error{
+				errors.NewInputError("slack_channel", []string{"slack channel must have only lowercase letters, numbers, hyphens and underscores"}),
+			},
+		},
+		{
+			name: "empty and repeated owners and reviewers",
+			change: func(i *SquadDto) {
+				i.Owners = []string{"ana@example.com", " ", "ana@example.com"}
+				i.DefaultReviewers = []string{"bruno", "bruno"}
+			},
+			expected: []error{
+				errors.NewInputError("owners", []string{"values cannot be empty"}),
+				errors.NewInputError("owners", []string{"ana@example.com is repeated"}),
+				errors.NewInputError("default_reviewers", []string{"bruno is repeated"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := valid
+			tt.change(&i)
+			errs := validateSquad(i, squads)
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 type ProgressUseCase interface {
 	Exec(ID string) ([]entity.Progress, error)
+	After(ID string, after int64) ([]entity.Progress, error)
 	IsFinished(ID string) bool
 }
 
@@ -19,11 +20,15 @@ func NewProgressUseCase(c *container.Container) ProgressUseCase {
 }
 
 func (uc *progressUseCase) Exec(ID string) ([]entity.Progress, error) {
-	messages, err := uc.Repositories.ProgressRepository.GetMessages(ID)
+	return uc.After(ID, 0)
+}
+
+func (uc *progressUseCase) After(ID string, after int64) ([]entity.Progress, error) {
+	messages, err := uc.Repositories.ProgressRepository.GetMessagesAfter(ID, after)
 	if err != nil {
 		return nil, err
 	}
-	var progress []entity.Progress
+	progress := []entity.Progress{}
 	for _, message := range messages {
 		progress = append(progress, message.ToStruct())
 	}
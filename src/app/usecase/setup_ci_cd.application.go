@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/app/workers"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"strings"
+	"time"
+)
+
+// Processes changing an application already set up share the same lifecycle:
+// they are started from the application record, run their own steps graph
+// and, once finished with success, update the record.
+
+// applicationInput returns the current setup input of the application.
+func (uc *setupCiCdUseCase) applicationInput(application *entity.Application) (*CiCdInputDto, error) {
+	input := []byte(application.Input)
+	if len(input) == 0 {
+		var err error
+		input, err = uc.Repositories.ProgressRepository.GetInput(application.ProcessID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(input) == 0 {
+		return nil, errors.NewInputError("slug", []string{"setup of the application not found"})
+	}
+	i := &CiCdInputDto{}
+	if err := json.Unmarshal(input, i); err != nil {
+		return nil, err
+	}
+	i.DryRun = false
+	return i, nil
+}
+
+func (uc *setupCiCdUseCase) getApplication(slug string) (*entity.Application, error) {
+	application, err := uc.Repositories.ApplicationRepository.Get(slug)
+	if err != nil {
+		return nil, err
+	}
+	if application == nil {
+		return nil, errors.NewInputError("slug", []string{"application not found"})
+	}
+	return application, nil
+}
+
+// startApplicationProcess locks the application and queues a process of the
+// given kind with its input, environments are the ones changed by it.
+func (uc *setupCiCdUseCase) startApplicationProcess(application *entity.Application, kind entity.JobKind, i *CiCdInputDto, environments []string) CiCdOutputDto {
+	if application.Status == entity.ApplicationDecommissionPending {
+		return CiCdOutputDto{Errors: []error{uc.pendingDecommissionError(application.Slug)}}
+	}
+	processID := uc.MessageManager.GenerateID()
+	if err := uc.lockApplication(application.Slug, processID); err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	input, err := json.Marshal(i)
+	if err == nil {
+		err = uc.Repositories.ProgressRepository.SaveInput(processID, input)
+	}
+	if err == nil {
+		err = uc.Repositories.ProgressRepository.SaveMetadata(entity.ProcessMetadata{
+			ID:           processID,
+			Kind:         kind,
+			Squad:        application.Squad,
+			Application:  application.Slug,
+			Template:     application.Template,
+			Environments: environments,
+			CreatedAt:    time.Now(),
+		})
+	}
+	if err == nil {
+		err = uc.enqueue(processID, kind, "")
+	}
+	if err != nil {
+		uc.Logger.Error("Error starting process", kind, err.Error())
+		uc.unlockApplication(application.Slug, processID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	return CiCdOutputDto{Errors: nil, ProcessId: processID}
+}
+
+// pendingDecommissionError tells which process must be retried to finish the
+// decommission of the application.
+func (uc *setupCiCdUseCase) pendingDecommissionError(slug string) error {
+	message := "application decommission waiting for its pull requests"
+	pending, _, err := uc.Repositories.ProgressRepository.ListMetadata(entity.ProcessFilter{
+		Application: slug,
+		Status:      string(entity.ResultPending),
+		Limit:       1,
+	})
+	if err != nil {
+		uc.Logger.Error("Error listing processes: %s", err.Error())
+	} else if len(pending) > 0 {
+		message += fmt.Sprintf(", retry process %s once they are merged", pending[0].ID)
+	}
+	return errors.NewConflictError("slug", []string{message})
+}
+
+func (uc *setupCiCdUseCase) retryApplicationProcess(ID, slug string, kind entity.JobKind, status entity.ProcessStatus) CiCdOutputDto {
+	steps, _, err := uc.Repositories.ProgressRepository.GetCheckpoints(ID)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	for _, step := range steps {
+		if step == "finish-"+string(kind) {
+			return CiCdOutputDto{Errors: []error{errors.NewInputError("id", []string{"process already finished with success"})}}
+		}
+	}
+	if err := uc.lockApplication(slug, ID); err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	if err := uc.enqueue(ID, kind, status); err != nil {
+		uc.unlockApplication(slug, ID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	return CiCdOutputDto{Errors: nil, ProcessId: ID}
+}
+
+// loadApplicationProcess rebuilds a process changing an application. It's not
+// validated again, the limits may have changed since it was started. The
+// environments of the application not changed by the process are kept apart.
+func (uc *setupCiCdUseCase) loadApplicationProcess(ctx context.Context, ID string) (*processData, []error) {
+	input, err := uc.Repositories.ProgressRepository.GetInput(ID)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if input == nil {
+		return nil, []error{errors.NewInputError("id", []string{"process not found"})}
+	}
+	var i CiCdInputDto
+	if err := json.Unmarshal(input, &i); err != nil {
+		return nil, []error{err}
+	}
+	e, errs := uc.makeEntity(i, ID)
+	dm, err := uc.defaultManifests()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	application, err := uc.getApplication(e.ApplicationSlug())
+	if err != nil {
+		return nil, []error{err}
+	}
+	steps, created, err := uc.Repositories.ProgressRepository.GetCheckpoints(ID)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if created == nil {
+		created = application.CreatedData
+	}
+	if created != nil {
+		*e.CreatedData() = *created
+	}
+	pd := uc.newProcessData(ctx, ID, e, dm, false)
+	pd.templatesRef = i.templatesRef(e.Template())
+	for _, step := range steps {
+		pd.checkpoints[step] = true
+	}
+	ai, err := uc.applicationInput(application)
+	if err != nil {
+		return nil, []error{err}
+	}
+	changed := make(map[string]bool)
+	for _, env := range e.Envs() {
+		changed[env.Env().Code()] = true
+	}
+	for _, env := range ai.Envs {
+		if changed[env.Code] {
+			continue
+		}
+		ee, err := uc.Repositories.EnvironmentRepository.Get(env.Code)
+		if err != nil {
+			return nil, []error{errors.NewInputError("envs."+env.Code, []string{err.Error()})}
+		}
+		pd.unchangedEnvs = append(pd.unchangedEnvs, entity.NewSetupEnvData(ee, env.Replicas.Min, env.Replicas.Max))
+	}
+	return pd, nil
+}
+
+// runApplicationProcess runs the steps of a process changing an application,
+// done is called when it finishes with success to update the application.
+func (uc *setupCiCdUseCase) runApplicationProcess(pd *processData, kind entity.JobKind, label string, g *setupGraph, done func(pd *processData)) {
+	defer func() {
+		if r := recover(); r != nil {
+			uc.Logger.Error("Recovered in process", kind, r)
+			uc.finishApplicationProcess(pd, kind, label, []string{"Process interrupted by internal error"}, true, nil)
+		}
+	}()
+	uc.Logger.Debug("PROCESSING", kind, pd.id)
+	ud := updateProgressData{
+		ID:      pd.id,
+		Step:    "pre-process-" + string(kind),
+		Message: fmt.Sprintf("%s %s", label, pd.data.ApplicationSlug()),
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(ud, "")
+	if len(pd.checkpoints) > 0 {
+		uc.updateProgress(ud, fmt.Sprintf("Resuming process, %d steps already done will be skipped", len(pd.checkpoints)))
+	}
+	additionalData, err := uc.runSteps(pd, g)
+	if pullRequestsPending(err) {
+		uc.pendApplicationProcess(pd, kind, label, additionalData)
+		return
+	}
+	uc.finishApplicationProcess(pd, kind, label, additionalData, err != nil, done)
+}
+
+// pendApplicationProcess finishes a process waiting for its pull requests,
+// without the checkpoint of its end so it can be retried once they are
+// merged. Its compensations are kept for a rollback.
+func (uc *setupCiCdUseCase) pendApplicationProcess(pd *processData, kind entity.JobKind, label string, additionalData []string) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "finish-" + string(kind),
+		Message: label + " waiting for pull requests",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	if uc.interrupted(pd) {
+		return
+	}
+	defer uc.markAsFinished(pd.id, entity.ProcessFinished, entity.ResultPending)
+	defer uc.cleanWorkspace(pd.id, false)
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range additionalData {
+		uc.updateProgress(data, v)
+	}
+	uc.updateProgress(data, fmt.Sprintf("Once they are merged the process is finished with POST ci-cd/setup/%s/retry", pd.id))
+}
+
+func (uc *setupCiCdUseCase) finishApplicationProcess(pd *processData, kind entity.JobKind, label string, additionalData []string, errs bool, done func(pd *processData)) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "finish-" + string(kind),
+		Message: label + " finish with success",
+		Type:    "success",
+		IsNode:  true,
+	}
+	if uc.interrupted(pd) {
+		return
+	}
+	status := entity.ProcessFinished
+	result := entity.ResultSuccess
+	if errs {
+		data.Type = "error"
+		data.Message = label + " finish with errors"
+		result = entity.ResultError
+	}
+	if errs && workers.CancelRequested(pd.ctx) {
+		status = entity.ProcessCancelled
+		result = entity.ResultCancelled
+		data.Step = "cancel-setup"
+		data.Type = "cancelled"
+		data.Message = "Process cancelled"
+	}
+	defer uc.markAsFinished(pd.id, status, result)
+	defer uc.cleanWorkspace(pd.id, errs)
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range additionalData {
+		uc.updateProgress(data, v)
+	}
+	if errs {
+		uc.updateProgress(data, fmt.Sprintf("The process can be resumed with POST ci-cd/setup/%s/retry", pd.id))
+		if c, _ := uc.Repositories.ProgressRepository.GetCompensations(pd.id); len(c) > 0 {
+			uc.updateProgress(data, fmt.Sprintf("%d created resources can be rolled back with POST ci-cd/setup/%s/rollback", len(c), pd.id))
+		}
+		return
+	}
+	if done != nil {
+		done(pd)
+	}
+	if err := uc.Repositories.ProgressRepository.ClearCompensations(pd.id); err != nil {
+		uc.Logger.Error("Error clearing compensations: %s", err.Error())
+	}
+	uc.checkpoint(pd, data.Step)
+}
+
+// updateApplication changes the application record of the process.
+func (uc *setupCiCdUseCase) updateApplication(pd *processData, update func(a *entity.Application, i *CiCdInputDto)) {
+	application, err := uc.getApplication(pd.data.ApplicationSlug())
+	if err != nil {
+		uc.Logger.Error("Error reading application: %s", err.Error())
+		return
+	}
+	i, err := uc.applicationInput(application)
+	if err != nil {
+		uc.Logger.Error("Error reading application input: %s", err.Error())
+		return
+	}
+	pd.mutex.Lock()
+	created := *pd.data.CreatedData()
+	pd.mutex.Unlock()
+	if application.CreatedData != nil && created.RepositoryUrl == "" {
+		created.RepositoryUrl = application.CreatedData.RepositoryUrl
+	}
+	application.CreatedData = &created
+	application.UpdatedAt = time.Now()
+	update(application, i)
+	input, err := json.Marshal(i)
+	if err != nil {
+		uc.Logger.Error("Error saving application input: %s", err.Error())
+		return
+	}
+	application.Input = input
+	application.Environments = envCodes(i.Envs)
+	if err := uc.Repositories.ApplicationRepository.Save(*application); err != nil {
+		uc.Logger.Error("Error saving application: %s", err.Error())
+	}
+}
+
+func envCodes(envs []EnvInputDto) []string {
+	var codes []string
+	for _, env := range envs {
+		codes = append(codes, env.Code)
+	}
+	return codes
+}
+
+func (p *processData) envsLabel() string {
+	var codes []string
+	for _, env := range p.data.Envs() {
+		codes = append(codes, env.Env().Code())
+	}
+	return strings.Join(codes, "-")
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/service"
+	"strings"
+)
+
+// Data shared between decommission steps.
+const (
+	gitOpsRemovedData = "git-ops-removed"
+	gitOpsMergedData  = "git-ops-merged"
+)
+
+// errPullRequestsPending stops a process whose pull requests aren't merged
+// yet, it isn't a failure: the process is retried once they are.
+var errPullRequestsPending = errors.New("pull requests waiting for approval")
+
+func pullRequestsPending(err error) bool {
+	return errors.Is(err, errPullRequestsPending)
+}
+
+// decommissionSteps lists every step of the decommission process, the
+// resources used by the running application are only deleted once the PRs
+// removing its manifests are merged. While any of them is open the process
+// finishes as pending, keeping the application record, and a retry goes on.
+func (uc *setupCiCdUseCase) decommissionSteps() []*setupStep {
+	return []*setupStep{
+		{
+			code:     "clone-templates",
+			provides: []string{templatesData},
+			run:      uc.cloneTemplates,
+		},
+		{
+			code:         "remove-git-ops",
+			manifestType: entity.GitOpsManifests,
+			requires:     []string{templatesData},
+			provides:     []string{gitOpsRemovedData},
+			run:          uc.removeGitOps,
+		},
+		{
+			code:     "check-git-ops-removal",
+			requires: []string{gitOpsRemovedData},
+			provides: []string{gitOpsMergedData},
+			run:      uc.checkGitOpsRemoval,
+		},
+		{
+			code:         "delete-secrets",
+			manifestType: entity.SecretManifests,
+			requires:     []string{templatesData, gitOpsMergedData},
+			run:          uc.deleteSecrets,
+		},
+		{
+			code:         "delete-registry",
+			manifestType: entity.RegistryManifests,
+			requires:     []string{templatesData, gitOpsMergedData},
+			run:          uc.deleteRegistry,
+		},
+		{
+			code:         "archive-wiki",
+			manifestType: entity.WikiManifests,
+			requires:     []string{templatesData, gitOpsMergedData},
+			run:          uc.archiveWiki,
+		},
+	}
+}
+
+// Decommission queues the removal of everything the setup of the application
+// created.
+func (uc *setupCiCdUseCase) Decommission(slug string) CiCdOutputDto {
+	uc.Logger.Debug("RECEIVED REQUEST: applications/decommission", slug)
+	application, err := uc.getApplication(slug)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	i, err := uc.applicationInput(application)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	return uc.startApplicationProcess(application, entity.DecommissionJob, i, application.Environments)
+}
+
+func (uc *setupCiCdUseCase) decommission(pd *processData) {
+	uc.runApplicationProcess(pd, entity.DecommissionJob, "Decommission", uc.decommissionGraph, func(pd *processData) {
+		if err := uc.Repositories.ApplicationRepository.Delete(pd.data.ApplicationSlug()); err != nil {
+			uc.Logger.Error("Error deleting application: %s", err.Error())
+		}
+	})
+}
+
+func (uc *setupCiCdUseCase) decommissionBranch(pd *processData, additionalName string) string {
+	return fmt.Sprintf("decommission/%s/%s/%s", pd.data.ApplicationSlug(), pd.id, additionalName)
+}
+
+// removeGitOps opens the PRs removing the application from the GitOps
+// repositories, the Argo Applications PR and the manifests and config maps
+// PRs. All of them are merged or left open by the same flag, nothing makes the
+// manifests PRs wait for the Argo one. The PRs left open are reported by the check of the
+// removal.
+func (uc *setupCiCdUseCase) removeGitOps(pd *processData, gm []*entity.Manifest) ([]string, error) {
+	if err := uc.cloneGitOps(pd); err != nil {
+		return nil, err
+	}
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "remove-git-ops-manifests",
+		Message: "Removing GitOps manifests",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, m := range gm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's manifests already removed, skipping", m.Code))
+			continue
+		}
+		data.Type = "progress"
+		ge, err := uc.Services.GitOpsService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
+			return []string{}, err
+		}
+		branch := uc.decommissionBranch(pd, m.Code)
+		uc.updateProgress(data, "Creating repositories branch for changes")
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsToolsDestinationDir, pd.gitOpsToolsBranch, branch); err != nil {
+			return []string{}, err
+		}
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, branch); err != nil {
+			return []string{}, err
+		}
+		if uc.config.SetupCiCd.ExternalConfigMap {
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.configMapDestinationDir, pd.configMapBranch, branch); err != nil {
+				return []string{}, err
+			}
+		}
+
+		merge := true
+		for _, e := range pd.data.Envs() {
+			uc.updateProgress(data, fmt.Sprintf("Removing %s manifests from %s environment", m.Code, e.Env().Code()))
+			if err := uc.Services.GitOpsService.RemoveGitOpsManifests(ge, pd.gitOpsToolsDestinationDir, e); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error removing %s gitOps manifests from %s environment", m.Code, e.Env().Code()))
+				return []string{}, err
+			}
+			if e.Env().RequireApproval() {
+				merge = false
+			}
+		}
+		prd := pullRequestData{
+			pd:              data,
+			localDir:        pd.gitOpsToolsDestinationDir,
+			repository:      uc.config.SetupCiCd.GitOpsToolsRepository,
+			targetBranch:    pd.gitOpsToolsBranch,
+			actualBranch:    branch,
+			message:         fmt.Sprintf("chore: remove %s - %s applications [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label),
+			title:           fmt.Sprintf("Decommission %s's %s applications", pd.data.ApplicationSlug(), m.Label),
+			merge:           merge,
+			ctx:             pd.ctx,
+			templatesCommit: pd.templatesCommit,
+		}
+		if _, err := uc.makePr(prd, true); err != nil {
+			return []string{}, err
+		}
+
+		uc.updateProgress(data, fmt.Sprintf("Removing %s k8s manifests", m.Code))
+		if err := uc.Services.GitOpsService.RemoveK8sManifests(ge, pd.gitOpsDestinationDir, pd.configMapDestinationDir); err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error removing %s k8s manifests", m.Code))
+			return []string{}, err
+		}
+		prd.localDir = pd.gitOpsDestinationDir
+		prd.repository = uc.config.SetupCiCd.GitOpsRepository
+		prd.targetBranch = pd.gitOpsBranch
+		prd.message = fmt.Sprintf("chore: remove %s - %s manifests [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label)
+		prd.title = fmt.Sprintf("Decommission %s's %s manifests", pd.data.ApplicationSlug(), m.Label)
+		if _, err := uc.makePr(prd, true); err != nil {
+			return []string{}, err
+		}
+		if uc.config.SetupCiCd.ExternalConfigMap {
+			prd.localDir = pd.configMapDestinationDir
+			prd.repository = uc.config.SetupCiCd.ConfigMapRepository
+			prd.targetBranch = pd.configMapBranch
+			if _, err := uc.makePr(prd, true); err != nil {
+				return []string{}, err
+			}
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's manifests removed for %s's service", m.Code, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
+
+// checkGitOpsRemoval looks for the removal PRs not merged, the ones whose
+// decline is still registered as compensation of the process. While any of
+// them is open the application is kept as pending decommission.
+func (uc *setupCiCdUseCase) checkGitOpsRemoval(pd *processData, _ []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "check-git-ops-removal",
+		Message: "Checking GitOps removal pull requests",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	if pd.isDone(data.Step) {
+		return nil, nil
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	compensations, err := uc.Repositories.ProgressRepository.GetCompensations(pd.id)
+	if err != nil {
+		uc.updateProgressError(data, err, "Error reading the pull requests of the process")
+		return []string{}, err
+	}
+	var open []string
+	for _, c := range compensations {
+		if c.Kind != entity.DeclinePullRequestCompensation {
+			continue
+		}
+		pr, err := uc.Services.GitApiService.GetPullRequest(pd.ctx, c.Repository, c.PullRequestId)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error reading PR #%d on %s", c.PullRequestId, c.Repository))
+			return []string{}, err
+		}
+		switch pr.State {
+		case service.PullRequestMerged:
+		case service.PullRequestOpen:
+			open = append(open, " -- "+pr.Links.Html.Href)
+		default:
+			err := fmt.Errorf("PR #%d on %s is %s", c.PullRequestId, c.Repository, strings.ToLower(pr.State))
+			uc.updateProgressError(data, err, "The application removal wasn't merged")
+			return []string{}, err
+		}
+	}
+	if len(open) > 0 {
+		uc.updateProgress(data, fmt.Sprintf("%d PRs removing %s are not merged, its resources are kept until they are", len(open), pd.data.ApplicationSlug()))
+		uc.setApplicationStatus(pd.data.ApplicationSlug(), entity.ApplicationDecommissionPending)
+		return append([]string{"Pull requests waiting for approval:"}, open...), errPullRequestsPending
+	}
+	data.Type = "success"
+	uc.updateProgress(data, "GitOps removal pull requests merged")
+	uc.checkpoint(pd, data.Step)
+	return nil, nil
+}
+
+func (uc *setupCiCdUseCase) setApplicationStatus(slug string, status entity.ApplicationStatus) {
+	application, err := uc.getApplication(slug)
+	if err != nil {
+		uc.Logger.Error("Error reading application: %s", err.Error())
+		return
+	}
+	application.Status = status
+	if err := uc.Repositories.ApplicationRepository.Save(*application); err != nil {
+		uc.Logger.Error("Error saving application: %s", err.Error())
+	}
+}
+
+func (uc *setupCiCdUseCase) deleteSecrets(pd *processData, manifests []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "delete-secrets",
+		Message: "Deleting Secrets",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range manifests {
+		step := fmt.Sprintf("%s/%s", data.Step, v.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s already deleted, skipping", v.Label))
+			continue
+		}
+		secret, err := uc.Services.SecretService.LoadData(pd.data, v, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", v.Code))
+			return []string{}, err
+		}
+		for _, env := range pd.data.Envs() {
+			if err := pd.ctx.Err(); err != nil {
+				uc.updateProgressError(data, err, "Secrets deletion interrupted")
+				return []string{}, err
+			}
+			data.Type = "progress"
+			location, path := secret.Config().GetRootPath(env), secret.Config().GetSecretPath(env)
+			uc.updateProgress(data, fmt.Sprintf("Deleting %s %s - %s", v.Label, location, path))
+			if err := uc.Services.SecretApiService.Delete(location, path); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error deleting %s %s - %s", v.Label, location, path))
+				return []string{}, err
+			}
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's secrets deleted for %s's service", v.Label, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
+
+func (uc *setupCiCdUseCase) deleteRegistry(pd *processData, manifests []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "delete-registry",
+		Message: "Deleting Registry",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range manifests {
+		step := fmt.Sprintf("%s/%s", data.Step, v.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s already deleted, skipping", v.Label))
+			continue
+		}
+		data.Type = "progress"
+		registry, err := uc.Services.RegistryService.LoadData(pd.data, v, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", v.Code))
+			return []string{}, err
+		}
+		uc.updateProgress(data, fmt.Sprintf("Deleting %s %s", v.Label, *registry.Name()))
+		if err := uc.Services.RegistryApiService.Delete(pd.ctx, registry); err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error deleting %s %s", v.Label, *registry.Name()))
+			return []string{}, err
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s %s deleted", v.Label, *registry.Name()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
+
+func (uc *setupCiCdUseCase) archiveWiki(pd *processData, manifests []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "archive-wiki",
+		Message: "Archiving Wiki",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range manifests {
+		step := fmt.Sprintf("%s/%s", data.Step, v.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s wiki already archived, skipping", v.Label))
+			continue
+		}
+		if err := pd.ctx.Err(); err != nil {
+			uc.updateProgressError(data, err, "Wiki archiving interrupted")
+			return []string{}, err
+		}
+		data.Type = "progress"
+		wiki, err := uc.Services.WikiService.LoadData(pd.data, v, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", v.Code))
+			return []string{}, err
+		}
+		pd.mutex.Lock()
+		pageId := pd.data.CreatedData().WikiPageId
+		pd.mutex.Unlock()
+		output, err := uc.Services.WikiService.ArchiveServicePage(wiki, pageId, pd.templatesDestinationDir)
+		for _, o := range output {
+			uc.updateProgress(data, o)
+		}
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error archiving %s wiki", strings.ToLower(v.Label)))
+			return []string{}, err
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's wiki archived for %s's service", v.Label, pd.data.ApplicationName()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
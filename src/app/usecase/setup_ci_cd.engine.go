@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"sync"
+)
+
+// setupStep is a unit of work of the setup process. Steps declare the data
+// they require and provide, so they can be ordered and the independent ones
+// can run concurrently.
+type setupStep struct {
+	code string
+	// manifestType skips the step when the setup has no manifest of this
+	// type, an empty type means the step always runs.
+	manifestType entity.ManifestType
+	requires     []string
+	provides     []string
+	run          func(pd *processData, manifests []*entity.Manifest) ([]string, error)
+}
+
+// setupGraph holds the steps in levels, each step only depends on steps of
+// the previous levels.
+type setupGraph struct {
+	levels [][]*setupStep
+}
+
+func newSetupGraph(steps []*setupStep) (*setupGraph, error) {
+	providers := make(map[string]*setupStep)
+	for _, s := range steps {
+		for _, p := range s.provides {
+			if other, ok := providers[p]; ok {
+				return nil, errors.New(fmt.Sprintf("%s is provided by both %s and %s steps", p, other.code, s.code))
+			}
+			providers[p] = s
+		}
+	}
+	deps := make(map[*setupStep][]*setupStep)
+	for _, s := range steps {
+		for _, r := range s.requires {
+			p, ok := providers[r]
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("%s step requires %s, but no step provides it", s.code, r))
+			}
+			deps[s] = append(deps[s], p)
+		}
+	}
+	g := &setupGraph{}
+	placed := make(map[*setupStep]bool)
+	for len(placed) < len(steps) {
+		var level []*setupStep
+		for _, s := range steps {
+			if placed[s] {
+				continue
+			}
+			ready := true
+			for _, d := range deps[s] {
+				if !placed[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, s)
+			}
+		}
+		if len(level) == 0 {
+			return nil, errors.New("setup steps have a cyclic dependency")
+		}
+		for _, s := range level {
+			placed[s] = true
+		}
+		g.levels = append(g.levels, level)
+	}
+	return g, nil
+}
+
+type setupStepResult struct {
+	data []string
+	err  error
+}
+
+// runSteps runs the graph level by level, the steps of a level run
+// concurrently. It stops after the first level with a failed step.
+func (uc *setupCiCdUseCase) runSteps(pd *processData, g *setupGraph) ([]string, error) {
+	var additionalData []string
+	for _, level := range g.levels {
+		results := make([]setupStepResult, len(level))
+		var wg sync.WaitGroup
+		for i, s := range level {
+			var manifests []*entity.Manifest
+			if s.manifestType != "" {
+				manifests = uc.getManifests(pd, s.manifestType)
+				if len(manifests) == 0 {
+					continue
+				}
+			}
+			wg.Add(1)
+			go func(i int, s *setupStep, manifests []*entity.Manifest) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						uc.Logger.Error("Recovered in step "+s.code, r)
+						results[i].err = errors.New(fmt.Sprintf("%s step interrupted by internal error", s.code))
+					}
+				}()
+				results[i].data, results[i].err = s.run(pd, manifests)
+			}(i, s, manifests)
+		}
+		wg.Wait()
+		var err error
+		for _, r := range results {
+			additionalData = append(additionalData, r.data...)
+			if r.err != nil && err == nil {
+				err = r.err
+			}
+		}
+		if err != nil {
+			return additionalData, err
+		}
+	}
+	return additionalData, nil
+}
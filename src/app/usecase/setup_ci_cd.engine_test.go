@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func step(code string, requires []string, provides ...string) *setupStep {
+	return &setupStep{code: code, requires: requires, provides: provides}
+}
+
+func TestNewSetupGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []*setupStep
+		levels [][]string
+		err    string
+	}{
+		{
+			name:   "independent steps share a level",
+			steps:  []*setupStep{step("a", nil, "x"), step("b", nil, "y")},
+			levels: [][]string{{"a", "b"}},
+		},
+		{
+			name: "steps run after their providers",
+			steps: []*setupStep{
+				step("wiki", []string{"envs", "registry"}),
+				step("k8s", []string{"clone"}, "envs"),
+				step("clone", nil, "clone"),
+				step("registry", nil, "registry"),
+			},
+			levels: [][]string{{"clone", "registry"}, {"k8s"}, {"wiki"}},
+		},
+		{
+			name:   "a step can require several data of one provider",
+			steps:  []*setupStep{step("a", nil, "x", "y"), step("b", []string{"x", "y"})},
+			levels: [][]string{{"a"}, {"b"}},
+		},
+		{
+			name:  "missing provider",
+			steps: []*setupStep{step("a", []string{"x"})},
+			err:   "a step requires x, but no step provides it",
+		},
+		{
+			name:  "repeated provider",
+			steps: []*setupStep{step("a", nil, "x"), step("b", nil, "x")},
+			err:   "x is provided by both a and b steps",
+		},
+		{
+			name:  "cycle",
+			steps: []*setupStep{step("a", []string{"y"}, "x"), step("b", []string{"x"}, "y"), step("c", nil, "z")},
+			err:   "cyclic dependency",
+		},
+		{
+			name:  "step requiring itself",
+			steps: []*setupStep{step("a", []string{"x"}, "x")},
+			err:   "cyclic dependency",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newSetupGraph(tt.steps)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var levels [][]string
+			for _, level := range g.levels {
+				var codes []string
+				for _, s := range level {
+					codes = append(codes, s.code)
+				}
+				levels = append(levels, codes)
+			}
+			if !reflect.DeepEqual(levels, tt.levels) {
+				t.Fatalf("expected levels %v, got %v", tt.levels, levels)
+			}
+		})
+	}
+}
+
+// The graphs of the processes are built from the real steps.
+func TestNewSetupCiCdUseCaseGraphs(t *testing.T) {
+	uc := &setupCiCdUseCase{}
+	for name, steps := range map[string][]*setupStep{
+		"setup":            uc.setupSteps(),
+		"decommission":     uc.decommissionSteps(),
+		"add environments": uc.addEnvironmentsSteps(),
+		"change resources": uc.changeResourcesSteps(),
+		"promote":          uc.promoteSteps(),
+	} {
+		if _, err := newSetupGraph(steps); err != nil {
+			t.Errorf("%s steps: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"strings"
+)
+
+type AddEnvironmentsInputDto struct {
+	Envs []EnvInputDto `json:"envs"`
+}
+
+// addEnvironmentsSteps lists every step adding environments to an application
+// already set up. The process entity only has the new environments, so the
+// steps shared with the setup only create their resources.
+func (uc *setupCiCdUseCase) addEnvironmentsSteps() []*setupStep {
+	return []*setupStep{
+		{
+			code:     "clone-templates",
+			provides: []string{templatesData},
+			run:      uc.cloneTemplates,
+		},
+		{
+			code:         "setup-secrets",
+			manifestType: entity.SecretManifests,
+			requires:     []string{templatesData},
+			provides:     []string{secretsData},
+			run:          uc.setupSecret,
+		},
+		{
+			code:         "add-git-ops",
+			manifestType: entity.GitOpsManifests,
+			requires:     []string{templatesData},
+			provides:     []string{environmentsData},
+			run:          uc.addGitOps,
+		},
+		{
+			code:         "setup-pipeline-environments",
+			manifestType: entity.PipelineManifests,
+			requires:     []string{templatesData},
+			provides:     []string{pipelineData},
+			run:          uc.setupPipelineEnvironments,
+		},
+		{
+			code:         "refresh-wiki",
+			manifestType: entity.WikiManifests,
+			requires:     []string{templatesData, environmentsData},
+			provides:     []string{wikiData},
+			run:          uc.refreshWiki,
+		},
+	}
+}
+
+// AddEnvironments queues the setup of new environments for an application
+// already set up.
+func (uc *setupCiCdUseCase) AddEnvironments(slug string, i AddEnvironmentsInputDto) CiCdOutputDto {
+	uc.Logger.Debug("RECEIVED REQUEST: applications/environments", slug, i)
+	if len(i.Envs) == 0 {
+		return CiCdOutputDto{Errors: []error{errors.NewInputError("envs", []string{"envs cannot be empty"})}}
+	}
+	application, err := uc.getApplication(slug)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	input, err := uc.applicationInput(application)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	var errs []error
+	existing := make(map[string]bool)
+	for _, env := range input.Envs {
+		existing[env.Code] = true
+	}
+	for _, env := range i.Envs {
+		if existing[env.Code] {
+			errs = append(errs, errors.NewInputError("envs."+env.Code, []string{"environment already set up for the application"}))
+		}
+		existing[env.Code] = true
+	}
+	if len(errs) > 0 {
+		return CiCdOutputDto{Errors: errs}
+	}
+	// The whole application is validated, the new environments may not fit
+	// its template or resources
+	full := *input
+	full.Envs = append(append([]EnvInputDto{}, input.Envs...), i.Envs...)
+	e, errs := uc.makeEntity(full, "")
+	errs = append(errs, uc.Services.CiCdService.ValidateSetup(e)...)
+	if len(errs) > 0 {
+		uc.Logger.Debug("ERRORS VALIDATE ADD ENVIRONMENTS:", errs)
+		return CiCdOutputDto{Errors: errs}
+	}
+	input.Envs = i.Envs
+	return uc.startApplicationProcess(application, entity.AddEnvironmentJob, input, envCodes(i.Envs))
+}
+
+func (uc *setupCiCdUseCase) addEnvironments(pd *processData) {
+	uc.runApplicationProcess(pd, entity.AddEnvironmentJob, "Add environment", uc.addEnvsGraph, func(pd *processData) {
+		uc.updateApplication(pd, func(_ *entity.Application, i *CiCdInputDto) {
+			for _, env := range pd.data.Envs() {
+				i.Envs = append(i.Envs, EnvInputDto{
+					Code: env.Env().Code(),
+					Replicas: entity.LimitsIntData{
+						Min: env.ReplicasMin(),
+						Max: env.ReplicasMax(),
+					},
+				})
+			}
+		})
+	})
+}
+
+// addGitOps renders the overlays of the new environments on the existing k8s
+// manifests, then creates their Argo Applications like the setup does.
+func (uc *setupCiCdUseCase) addGitOps(pd *processData, gm []*entity.Manifest) ([]string, error) {
+	if err := uc.cloneGitOps(pd); err != nil {
+		return nil, err
+	}
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "create-k8s-overlays",
+		Message: "Creating K8s overlays",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	var extraData []string
+	for _, m := range gm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's k8s overlays already created, skipping", m.Code))
+			continue
+		}
+		data.Type = "progress"
+		uc.updateProgress(data, "Creating repositories branch for changes")
+		customBranch := pd.customBranch(fmt.Sprintf("overlays/%s/%s", pd.envsLabel(), m.Code))
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
+			return []string{}, err
+		}
+		if uc.config.SetupCiCd.ExternalConfigMap {
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.configMapDestinationDir, pd.configMapBranch, customBranch); err != nil {
+				return []string{}, err
+			}
+		}
+		ge, err := uc.Services.GitOpsService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
+			return []string{}, err
+		}
+		uc.updateProgress(data, fmt.Sprintf("Configuring %s k8s overlays for %s environments", m.Code, pd.envsLabel()))
+		kd, environments, err := uc.Services.GitOpsService.SetupK8sOverlays(ge, pd.templatesDestinationDir, pd.gitOpsDestinationDir, pd.configMapDestinationDir)
+		extraData = append(extraData, kd...)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error creating k8s overlays from %s templates", m.Code))
+			return []string{}, err
+		}
+		// The new environments are added to the created ones
+		pd.updateCreatedData(func(c *entity.CreatedData) { c.Environments = append(c.Environments, environments...) })
+		var overlays []string
+		for _, env := range pd.data.Envs() {
+			overlays = append(overlays, ge.Config().K8sApplicationDestinationPath+"/overlays/"+env.Env().Code())
+		}
+		if err := uc.validateManifests(data, pd.gitOpsDestinationDir, overlays...); err != nil {
+			return []string{}, err
+		}
+		prd := pullRequestData{
+			pd:              data,
+			localDir:        pd.gitOpsDestinationDir,
+			repository:      uc.config.SetupCiCd.GitOpsRepository,
+			targetBranch:    pd.gitOpsBranch,
+			actualBranch:    customBranch,
+			message:         fmt.Sprintf("feat: add %s - %s overlays for %s [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label, pd.envsLabel()),
+			title:           fmt.Sprintf("Create %s's %s overlays for %s", pd.data.ApplicationSlug(), m.Label, pd.envsLabel()),
+			merge:           true,
+			requireApproval: requireApproval(pd.data.Envs()),
+			ctx:             pd.ctx,
+			templatesCommit: pd.templatesCommit,
+		}
+		if _, err := uc.makePr(prd, true); err != nil {
+			return []string{}, err
+		}
+		if uc.config.SetupCiCd.ExternalConfigMap {
+			prd.localDir = pd.configMapDestinationDir
+			prd.targetBranch = pd.configMapBranch
+			prd.repository = uc.config.SetupCiCd.ConfigMapRepository
+			if _, err := uc.makePr(prd, true); err != nil {
+				return []string{}, err
+			}
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's overlays created for %s's service", m.Code, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
+	}
+	// The overlays are always merged like on the setup, only the Argo
+	// Applications respect the approval required by each environment
+	gd, err := uc.createGitOpsManifests(pd, gm)
+	return append(extraData, gd...), err
+}
+
+// setupPipelineEnvironments creates the deployment environments of the new
+// environments on the application repository. The environments not sent are
+// removed by the api, so the existing ones are sent with them.
+func (uc *setupCiCdUseCase) setupPipelineEnvironments(pd *processData, pm []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "setup-pipeline-environments",
+		Message: "Setting up Pipeline environments",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	envs := append(append([]entity.SetupEnvData{}, pd.unchangedEnvs...), pd.data.Envs()...)
+	for _, m := range pm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's pipeline environments already set up, skipping", m.Code))
+			continue
+		}
+		data.Type = "progress"
+		pe, err := uc.Services.PipelineService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
+			return []string{}, err
+		}
+		uc.updateProgress(data, fmt.Sprintf("Setting up environments' variables on %s repository", pd.data.ApplicationName()))
+		if err := uc.Services.GitApiService.SetRepositoryEnvironmentsVariables(pd.ctx, pd.data.ApplicationName(), uc.getPipelineEnvironments(pe, envs)); err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error setting up environments' variables on %s repository", pd.data.ApplicationName()))
+			return []string{}, err
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's pipeline environments set up for %s's service", m.Code, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
+
+func (uc *setupCiCdUseCase) refreshWiki(pd *processData, manifests []*entity.Manifest) ([]string, error) {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "refresh-wiki",
+		Message: "Updating Wiki",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, v := range manifests {
+		step := fmt.Sprintf("%s/%s", data.Step, v.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s wiki already updated, skipping", v.Label))
+			continue
+		}
+		if err := pd.ctx.Err(); err != nil {
+			uc.updateProgressError(data, err, "Wiki update interrupted")
+			return []string{}, err
+		}
+		data.Type = "progress"
+		wiki, err := uc.Services.WikiService.LoadData(pd.data, v, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", v.Code))
+			return []string{}, err
+		}
+		pd.mutex.Lock()
+		pageId := pd.data.CreatedData().WikiPageId
+		pd.mutex.Unlock()
+		output, err := uc.Services.WikiService.RefreshServicePage(wiki, pageId, pd.templatesDestinationDir)
+		for _, o := range output {
+			uc.updateProgress(data, o)
+		}
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error updating %s wiki", strings.ToLower(v.Label)))
+			return []string{}, err
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's wiki updated for %s's service", v.Label, pd.data.ApplicationName()))
+		uc.checkpoint(pd, step)
+	}
+	return nil, nil
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/app/workers"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"strings"
+	"time"
+)
+
+// enqueue queues a job for the process, previousStatus is restored when the
+// job cannot be queued.
+func (uc *setupCiCdUseCase) enqueue(ID string, kind entity.JobKind, previousStatus entity.ProcessStatus) error {
+	if err := uc.Repositories.ProgressRepository.SetStatus(ID, entity.ProcessQueued); err != nil {
+		return err
+	}
+	uc.updateMetadata(ID, func(m *entity.ProcessMetadata) { m.Status = entity.ProcessQueued })
+	job := entity.Job{ID: ID, Kind: kind, EnqueuedAt: time.Now()}
+	if err := uc.Repositories.JobQueueRepository.Enqueue(job); err != nil {
+		uc.Logger.Error("Error enqueuing job", ID, err.Error())
+		if err := uc.Repositories.ProgressRepository.SetStatus(ID, previousStatus); err != nil {
+			uc.Logger.Error("Error restoring process status", ID, err.Error())
+		}
+		uc.updateMetadata(ID, func(m *entity.ProcessMetadata) { m.Status = previousStatus })
+		return err
+	}
+	uc.updateProgress(updateProgressData{
+		ID:      ID,
+		Step:    "queue",
+		Message: "Process queued",
+		Type:    "progress",
+		IsNode:  true,
+	}, "")
+	if position, err := uc.Repositories.JobQueueRepository.Position(ID); err == nil && position > 0 {
+		uc.Waiting(job, position)
+	}
+	return nil
+}
+
+func (uc *setupCiCdUseCase) Handle(ctx context.Context, job entity.Job) error {
+	if err := uc.Repositories.ProgressRepository.SetStatus(job.ID, entity.ProcessRunning); err != nil {
+		return err
+	}
+	uc.updateMetadata(job.ID, func(m *entity.ProcessMetadata) {
+		now := time.Now()
+		m.Status = entity.ProcessRunning
+		m.Result = ""
+		m.StartedAt = &now
+		m.FinishedAt = nil
+	})
+	if job.Attempts > 1 {
+		uc.updateProgress(updateProgressData{
+			ID:      job.ID,
+			Step:    "queue",
+			Message: fmt.Sprintf("Process resumed by another worker, attempt %d", job.Attempts),
+			Type:    "progress",
+		}, "")
+	}
+	switch job.Kind {
+	case entity.SetupCiCdJob:
+		pd, errs := uc.loadProcess(ctx, job.ID)
+		if len(errs) > 0 {
+			uc.fail(job.ID, errs)
+			return errs[0]
+		}
+		uc.process(pd)
+	case entity.RollbackCiCdJob:
+		uc.rollbackProcess(ctx, job.ID)
+	case entity.DecommissionJob, entity.AddEnvironmentJob, entity.ChangeResourcesJob, entity.PromoteJob:
+		pd, errs := uc.loadApplicationProcess(ctx, job.ID)
+		if len(errs) > 0 {
+			uc.fail(job.ID, errs)
+			return errs[0]
+		}
+		switch job.Kind {
+		case entity.DecommissionJob:
+			uc.decommission(pd)
+		case entity.AddEnvironmentJob:
+			uc.addEnvironments(pd)
+		case entity.ChangeResourcesJob:
+			uc.changeResources(pd)
+		case entity.PromoteJob:
+			uc.promote(pd)
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown job kind: %s", job.Kind))
+	}
+	return nil
+}
+
+func (uc *setupCiCdUseCase) Waiting(job entity.Job, position int) {
+	uc.updateProgress(updateProgressData{
+		ID:      job.ID,
+		Step:    "queue",
+		Message: fmt.Sprintf("Waiting for a worker, position %d in queue", position),
+		Type:    "progress",
+	}, "")
+}
+
+func (uc *setupCiCdUseCase) Abandon(job entity.Job, reason error) {
+	uc.fail(job.ID, []error{reason})
+}
+
+func (uc *setupCiCdUseCase) rollbackProcess(ctx context.Context, ID string) {
+	workDir := strings.Replace(uc.config.SetupCiCd.RootDestinationsPath, "{{process-id}}", ID, -1) + "/rollback"
+	status := entity.ProcessFinished
+	result := entity.ResultError
+	defer func() {
+		if r := recover(); r != nil {
+			uc.Logger.Error("Recovered in rollback", r)
+		}
+		// An interrupted rollback runs again from the pending compensations
+		if workers.Interrupted(ctx) {
+			return
+		}
+		uc.cleanWorkspace(ID, result != entity.ResultSuccess)
+		uc.markAsFinished(ID, status, result)
+	}()
+	uc.rollback(ctx, ID, workDir)
+	if workers.CancelRequested(ctx) {
+		status = entity.ProcessCancelled
+		result = entity.ResultCancelled
+		uc.notifyCancelled(ID)
+	} else if pending, err := uc.Repositories.ProgressRepository.GetCompensations(ID); err == nil && len(pending) == 0 {
+		result = entity.ResultSuccess
+	}
+}
+
+// fail finishes a process that could not run.
+func (uc *setupCiCdUseCase) fail(ID string, errs []error) {
+	data := updateProgressData{
+		ID:      ID,
+		Step:    "finish-setup",
+		Message: "Process finish with errors",
+		Type:    "error",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	for _, err := range errs {
+		uc.updateProgress(data, "Error: "+err.Error())
+	}
+	uc.markAsFinished(ID, entity.ProcessFinished, entity.ResultError)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"time"
+)
+
+// lockApplication reserves the application for the process while it's queued
+// or running, so two processes never push to the same branches. A lock left
+// by a process that is no longer running is taken over.
+func (uc *setupCiCdUseCase) lockApplication(slug, ID string) error {
+	holder, err := uc.Repositories.ApplicationRepository.Lock(slug, ID)
+	if err != nil {
+		return err
+	}
+	if holder == ID {
+		return nil
+	}
+	status, err := uc.Repositories.ProgressRepository.GetStatus(holder)
+	if err != nil {
+		return err
+	}
+	if status.Done() {
+		replaced, err := uc.Repositories.ApplicationRepository.ReplaceLock(slug, holder, ID)
+		if err != nil {
+			return err
+		}
+		if replaced {
+			return nil
+		}
+	}
+	return errors.NewConflictError("application.name", []string{
+		fmt.Sprintf("a setup for application %s is already running in process %s", slug, holder),
+	})
+}
+
+func (uc *setupCiCdUseCase) unlockApplication(slug, ID string) {
+	if err := uc.Repositories.ApplicationRepository.Unlock(slug, ID); err != nil {
+		uc.Logger.Error("Error unlocking application", slug, err.Error())
+	}
+}
+
+// checkApplication rejects the setup of an application already set up by
+// another process.
+func (uc *setupCiCdUseCase) checkApplication(slug, ID string) error {
+	application, err := uc.Repositories.ApplicationRepository.Get(slug)
+	if err != nil {
+		return err
+	}
+	if application == nil || application.ProcessID == ID {
+		return nil
+	}
+	return errors.NewConflictError("application.name", []string{
+		fmt.Sprintf(
+			"application %s was already set up by process %s at %s",
+			slug,
+			application.ProcessID,
+			application.CreatedAt.Format(time.RFC3339),
+		),
+	})
+}
+
+func (uc *setupCiCdUseCase) saveApplication(pd *processData, created entity.CreatedData) {
+	var environments []string
+	for _, env := range pd.data.Envs() {
+		environments = append(environments, env.Env().Code())
+	}
+	input, err := uc.Repositories.ProgressRepository.GetInput(pd.id)
+	if err != nil {
+		uc.Logger.Error("Error reading process input: %s", err.Error())
+	}
+	createdBy := ""
+	if metadata, err := uc.Repositories.ProgressRepository.GetMetadata(pd.id); err != nil {
+		uc.Logger.Error("Error reading process metadata: %s", err.Error())
+	} else if metadata != nil {
+		createdBy = metadata.CreatedBy
+	}
+	created.RepositoryUrl = uc.config.GitConfig.GetRepositoryUrl(pd.data.ApplicationName())
+	now := time.Now()
+	err = uc.Repositories.ApplicationRepository.Save(entity.Application{
+		Slug:         pd.data.ApplicationSlug(),
+		Name:         pd.data.ApplicationName(),
+		Squad:        pd.data.Squad().Code(),
+		Template:     pd.data.Template().Code(),
+		Environments: environments,
+		ProcessID:    pd.id,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		CreatedData:  &created,
+		Input:        input,
+	})
+	if err != nil {
+		uc.Logger.Error("Error saving application: %s", err.Error())
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type repositoryDiff struct {
+	repository string
+	diff       string
+}
+
+// setupPlan collects what a dry-run process would have changed, so it can be
+// reported at the end instead of being pushed to the remote services.
+type setupPlan struct {
+	mutex     sync.Mutex
+	diffs     []*repositoryDiff
+	resources []string
+}
+
+func newSetupPlan() *setupPlan {
+	return &setupPlan{}
+}
+
+func (p *setupPlan) addDiff(repository string, diff string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, d := range p.diffs {
+		if d.repository == repository {
+			d.diff += diff
+			return
+		}
+	}
+	p.diffs = append(p.diffs, &repositoryDiff{repository: repository, diff: diff})
+}
+
+func (p *setupPlan) addResource(format string, v ...any) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.resources = append(p.resources, fmt.Sprintf(format, v...))
+}
+
+func (p *setupPlan) report() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var b strings.Builder
+	b.WriteString("Dry run plan\n")
+	b.WriteString("\nResources that would be created:\n")
+	if len(p.resources) == 0 {
+		b.WriteString(" -- none\n")
+	}
+	for _, r := range p.resources {
+		b.WriteString(" -- " + r + "\n")
+	}
+	for _, d := range p.diffs {
+		b.WriteString(fmt.Sprintf("\nDiff for %s repository:\n", d.repository))
+		if d.diff == "" {
+			b.WriteString("(no changes)\n")
+			continue
+		}
+		b.WriteString(d.diff)
+	}
+	return b.String()
+}
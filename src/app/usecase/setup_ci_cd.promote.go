@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+)
+
+type PromoteInputDto struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// promoteSteps lists every step promoting the image of an application between
+// environments. The process entity has the source and the target
+// environments, in this order.
+func (uc *setupCiCdUseCase) promoteSteps() []*setupStep {
+	return []*setupStep{
+		{
+			code:     "clone-templates",
+			provides: []string{templatesData},
+			run:      uc.cloneTemplates,
+		},
+		{
+			code:         "promote-image",
+			manifestType: entity.GitOpsManifests,
+			requires:     []string{templatesData},
+			run:          uc.promoteImage,
+		},
+	}
+}
+
+// Promote queues the promotion of the image deployed on the source
+// environment of an application to the target one.
+func (uc *setupCiCdUseCase) Promote(slug string, i PromoteInputDto) CiCdOutputDto {
+	uc.Logger.Debug("RECEIVED REQUEST: applications/promote", slug, i)
+	var errs []error
+	if i.Source == "" {
+		errs = append(errs, errors.NewInputError("source", []string{"source cannot be empty"}))
+	}
+	if i.Target == "" {
+		errs = append(errs, errors.NewInputError("target", []string{"target cannot be empty"}))
+	} else if i.Target == i.Source {
+		errs = append(errs, errors.NewInputError("target", []string{"target cannot be the source environment"}))
+	}
+	if len(errs) > 0 {
+		return CiCdOutputDto{Errors: errs}
+	}
+	application, err := uc.getApplication(slug)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	input, err := uc.applicationInput(application)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	var source, target *EnvInputDto
+	for k, env := range input.Envs {
+		switch env.Code {
+		case i.Source:
+			source = &input.Envs[k]
+		case i.Target:
+			target = &input.Envs[k]
+		}
+	}
+	if source == nil {
+		errs = append(errs, errors.NewInputError("source", []string{"environment not set up for the application"}))
+	}
+	if target == nil {
+		errs = append(errs, errors.NewInputError("target", []string{"environment not set up for the application"}))
+	}
+	if len(errs) > 0 {
+		return CiCdOutputDto{Errors: errs}
+	}
+	input.Envs = []EnvInputDto{*source, *target}
+	return uc.startApplicationProcess(application, entity.PromoteJob, input, []string{i.Source, i.Target})
+}
+
+func (uc *setupCiCdUseCase) promote(pd *processData) {
+	uc.runApplicationProcess(pd, entity.PromoteJob, "Promote", uc.promoteGraph, nil)
+}
+
+// promoteImage opens a PR setting on the overlay of the target environment
+// the images of the source one, merged when the target environment doesn't
+// require approval.
+func (uc *setupCiCdUseCase) promoteImage(pd *processData, gm []*entity.Manifest) ([]string, error) {
+	if len(pd.data.Envs()) != 2 {
+		return nil, errors.NewInputError("envs", []string{"promotion needs a source and a target environment"})
+	}
+	source, target := pd.data.Envs()[0], pd.data.Envs()[1]
+	if err := uc.cloneGitOps(pd); err != nil {
+		return nil, err
+	}
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "promote-image",
+		Message: fmt.Sprintf("Promoting image from %s to %s", source.Env().Label(), target.Env().Label()),
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	var extraData []string
+	for _, m := range gm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's image already promoted, skipping", m.Code))
+			continue
+		}
+		data.Type = "progress"
+		ge, err := uc.Services.GitOpsService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
+			return []string{}, err
+		}
+		uc.updateProgress(data, "Creating repositories branch for changes")
+		customBranch := pd.customBranch(fmt.Sprintf("promote/%s/%s", target.Env().Code(), m.Code))
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
+			return []string{}, err
+		}
+		images, err := uc.Services.GitOpsService.PromoteImage(ge, pd.gitOpsDestinationDir, source, target)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error promoting %s image to %s environment", m.Code, target.Env().Code()))
+			return []string{}, err
+		}
+		for _, image := range images {
+			uc.updateProgress(data, image)
+		}
+		if err := uc.validateManifests(data, pd.gitOpsDestinationDir, ge.Config().K8sApplicationDestinationPath+"/overlays/"+target.Env().Code()); err != nil {
+			return []string{}, err
+		}
+		prd := pullRequestData{
+			pd:              data,
+			localDir:        pd.gitOpsDestinationDir,
+			repository:      uc.config.SetupCiCd.GitOpsRepository,
+			targetBranch:    pd.gitOpsBranch,
+			actualBranch:    customBranch,
+			message:         fmt.Sprintf("feat: promote %s - %s from %s to %s environment [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label, source.Env().Label(), target.Env().Label()),
+			title:           fmt.Sprintf("Promote %s from %s to %s environment", pd.data.ApplicationSlug(), source.Env().Label(), target.Env().Label()),
+			merge:           !target.Env().RequireApproval(),
+			ctx:             pd.ctx,
+			templatesCommit: pd.templatesCommit,
+		}
+		if prUrl, err := uc.makePr(prd, true); err != nil {
+			return []string{}, err
+		} else if prUrl != "" {
+			extraData = append(extraData, " -- "+prUrl)
+		}
+		data.Type = "success"
+		uc.updateProgress(data, fmt.Sprintf("%s's image promoted to %s environment of %s's service", m.Code, target.Env().Code(), pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
+	}
+	if len(extraData) > 0 {
+		extraData = append([]string{"Pull requests waiting for approval:"}, extraData...)
+	}
+	return extraData, nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"sort"
+)
+
+type RenderedFileDto struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type RenderOutputDto struct {
+	Template        string `json:"template"`
+	TemplatesCommit string `json:"templatesCommit"`
+	// Files are the generated files, their path starts with the repository
+	// they would be pushed to, or wiki for the wiki pages
+	Files []RenderedFileDto `json:"files"`
+}
+
+// Render generates the files a setup with the input would push, on a scratch
+// workspace removed afterwards. Only the templates repository is cloned, the
+// files are rendered on empty directories instead of the clones of the
+// GitOps and application repositories.
+func (uc *setupCiCdUseCase) Render(ctx context.Context, code string, i CiCdInputDto) (*RenderOutputDto, []error) {
+	uc.Logger.Debug("RECEIVED REQUEST: templates/render", code, i)
+	ID := "render-" + uc.MessageManager.GenerateID()
+	i.Template = code
+	e, errs := uc.makeEntity(i, ID)
+	if len(errs) == 0 {
+		errs = append(errs, uc.Services.CiCdService.ValidateSetup(e)...)
+	}
+	if i.TemplatesRef != "" && !entity.ValidTemplatesRef(i.TemplatesRef) {
+		errs = append(errs, errors.NewInputError("templatesRef", []string{"templates ref must be a tag or a commit"}))
+	}
+	dm, err := uc.defaultManifests()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	// The renders cloning the templates at the same time are limited, a
+	// render waits for the others until its request is cancelled
+	select {
+	case uc.renders <- struct{}{}:
+		defer func() { <-uc.renders }()
+	case <-ctx.Done():
+		return nil, []error{ctx.Err()}
+	}
+	pd := uc.newProcessData(ctx, ID, e, dm, false)
+	pd.templatesRef = i.templatesRef(e.Template())
+	defer uc.cleanWorkspace(ID, false)
+	commit, err := uc.renderTemplates(pd)
+	if err != nil {
+		return nil, []error{err}
+	}
+	out := &RenderOutputDto{Template: code, TemplatesCommit: commit, Files: []RenderedFileDto{}}
+	for _, dir := range []struct{ name, path string }{
+		{uc.config.SetupCiCd.GitOpsRepository, pd.gitOpsDestinationDir},
+		{uc.config.SetupCiCd.GitOpsToolsRepository, pd.gitOpsToolsDestinationDir},
+		{uc.config.SetupCiCd.ConfigMapRepository, pd.configMapDestinationDir},
+		{pd.data.ApplicationName(), pd.applicationDestination},
+	} {
+		files, err := uc.Services.DirectoryService.ReadFiles(dir.path)
+		if err != nil {
+			return nil, []error{err}
+		}
+		out.Files = append(out.Files, renderedFiles(dir.name, files)...)
+	}
+	for _, m := range uc.getManifests(pd, entity.WikiManifests) {
+		wiki, err := uc.Services.WikiService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			return nil, []error{err}
+		}
+		title, content, err := uc.Services.WikiService.RenderServicePage(wiki, pd.templatesDestinationDir)
+		if err != nil {
+			return nil, []error{err}
+		}
+		out.Files = append(out.Files, RenderedFileDto{Path: "wiki/" + title + ".html", Content: string(content)})
+	}
+	return out, nil
+}
+
+// renderTemplates clones the templates at the ref of the setup and renders
+// the k8s, GitOps and pipeline manifests, it returns the commit of the
+// templates.
+func (uc *setupCiCdUseCase) renderTemplates(pd *processData) (string, error) {
+	ds := uc.Services.DirectoryService
+	gs := uc.Services.GitService
+	if err := gs.CloneRepository(pd.ctx, pd.templatesRepository, pd.templatesBranch, pd.templatesDestinationDir); err != nil {
+		return "", err
+	}
+	if pd.templatesRef != "" {
+		if err := gs.Checkout(pd.ctx, pd.templatesDestinationDir, entity.TemplatesRevision(pd.templatesRef)); err != nil {
+			return "", err
+		}
+	}
+	commit, err := gs.HeadCommit(pd.ctx, pd.templatesDestinationDir)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.readPinnedTemplate(pd); err != nil {
+		return "", err
+	}
+	pd.templatesCommit = commit
+	pd.data.CreatedData().TemplatesCommit = commit
+	for _, dir := range []string{pd.gitOpsDestinationDir, pd.gitOpsToolsDestinationDir, pd.applicationDestination} {
+		if err := ds.CreateDirectory(dir); err != nil {
+			return "", err
+		}
+	}
+	if uc.config.SetupCiCd.ExternalConfigMap {
+		if err := ds.CreateDirectory(pd.configMapDestinationDir); err != nil {
+			return "", err
+		}
+	}
+	for _, m := range uc.getManifests(pd, entity.GitOpsManifests) {
+		ge, err := uc.Services.GitOpsService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			return "", err
+		}
+		_, environments, err := uc.Services.GitOpsService.SetupK8sManifests(ge, pd.templatesDestinationDir, pd.gitOpsDestinationDir, pd.configMapDestinationDir)
+		if err != nil {
+			return "", err
+		}
+		// The wiki shows the created environments
+		pd.data.CreatedData().Environments = append(pd.data.CreatedData().Environments, environments...)
+		for _, env := range pd.data.Envs() {
+			if err := uc.Services.GitOpsService.SetupGitOpsManifests(ge, pd.templatesDestinationDir, pd.gitOpsToolsDestinationDir, env); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, m := range uc.getManifests(pd, entity.PipelineManifests) {
+		pe, err := uc.Services.PipelineService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			return "", err
+		}
+		if err := uc.Services.PipelineService.SetupPipeline(pe, pd.templatesDestinationDir, pd.applicationDestination); err != nil {
+			return "", err
+		}
+	}
+	return commit, nil
+}
+
+func renderedFiles(repository string, files map[string][]byte) []RenderedFileDto {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	rendered := make([]RenderedFileDto, 0, len(paths))
+	for _, path := range paths {
+		rendered = append(rendered, RenderedFileDto{Path: repository + "/" + path, Content: string(files[path])})
+	}
+	return rendered
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+)
+
+type ChangeResourcesInputDto struct {
+	Resources *entity.ResourcesDataObject `json:"resources"`
+	Envs      []EnvInputDto               `json:"envs"`
+}
+
+// changeResourcesSteps lists every step changing the resources and replicas
+// of an application already set up. The process entity only has the
+// environments whose replicas are changed.
+func (uc *setupCiCdUseCase) changeResourcesSteps() []*setupStep {
+	return []*setupStep{
+		{
+			code:     "clone-templates",
+			provides: []string{templatesData},
+			run:      uc.cloneTemplates,
+		},
+		{
+			code:         "update-resources",
+			manifestType: entity.GitOpsManifests,
+			requires:     []string{templatesData},
+			run:          uc.updateResources,
+		},
+	}
+}
+
+// ChangeResources queues the change of the resources of an application and
+// of the replicas of its environments.
+func (uc *setupCiCdUseCase) ChangeResources(slug string, i ChangeResourcesInputDto) CiCdOutputDto {
+	uc.Logger.Debug("RECEIVED REQUEST: applications/resources", slug, i)
+	if i.Resources == nil && len(i.Envs) == 0 {
+		return CiCdOutputDto{Errors: []error{errors.NewInputError("resources", []string{"resources or envs must be informed"})}}
+	}
+	application, err := uc.getApplication(slug)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	input, err := uc.applicationInput(application)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	full := *input
+	if i.Resources != nil {
+		full.Application.Resources = *i.Resources
+	}
+	full.Envs = append([]EnvInputDto{}, input.Envs...)
+	var errs []error
+	for _, env := range i.Envs {
+		found := false
+		for k, v := range full.Envs {
+			if v.Code == env.Code {
+				full.Envs[k].Replicas = env.Replicas
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, errors.NewInputError("envs."+env.Code, []string{"environment not set up for the application"}))
+		}
+	}
+	if len(errs) > 0 {
+		return CiCdOutputDto{Errors: errs}
+	}
+	e, errs := uc.makeEntity(full, "")
+	errs = append(errs, uc.Services.CiCdService.ValidateSetup(e)...)
+	if len(errs) > 0 {
+		uc.Logger.Debug("ERRORS VALIDATE CHANGE RESOURCES:", errs)
+		return CiCdOutputDto{Errors: errs}
+	}
+	full.Envs = i.Envs
+	return uc.startApplicationProcess(application, entity.ChangeResourcesJob, &full, envCodes(i.Envs))
+}
+
+func (uc *setupCiCdUseCase) changeResources(pd *processData) {
+	uc.runApplicationProcess(pd, entity.ChangeResourcesJob, "Change resources", uc.resourcesGraph, func(pd *processData) {
+		uc.updateApplication(pd, func(_ *entity.Application, i *CiCdInputDto) {
+			i.Application.Resources = entity.ResourcesDataObject{
+				Cpu: entity.LimitsFloatData{
+					Min: pd.data.ApplicationMinCpu(),
+					Max: pd.data.ApplicationMaxCpu(),
+				},
+				Memory: entity.LimitsFloatData{
+					Min: pd.data.ApplicationMemoryMin(),
+					Max: pd.data.ApplicationMemoryMax(),
+				},
+			}
+			for _, env := range pd.data.Envs() {
+				for k, v := range i.Envs {
+					if v.Code == env.Env().Code() {
+						i.Envs[k].Replicas = entity.LimitsIntData{Min: env.ReplicasMin(), Max: env.ReplicasMax()}
+					}
+				}
+			}
+		})
+	})
+}
+
+// updateResources opens a PR changing the resources on the k8s manifests, it's
+// only merged when no environment of the application requires approval. The
+// replicas are changed by a PR for each environment.
+func (uc *setupCiCdUseCase) updateResources(pd *processData, gm []*entity.Manifest) ([]string, error) {
+	if err := uc.cloneGitOps(pd); err != nil {
+		return nil, err
+	}
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "update-k8s-resources",
+		Message: "Updating K8s resources",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	merge := !requireApproval(append(append([]entity.SetupEnvData{}, pd.unchangedEnvs...), pd.data.Envs()...))
+	var extraData []string
+	prd := pullRequestData{
+		pd:              data,
+		localDir:        pd.gitOpsDestinationDir,
+		repository:      uc.config.SetupCiCd.GitOpsRepository,
+		ctx:             pd.ctx,
+		templatesCommit: pd.templatesCommit,
+	}
+	for _, m := range gm {
+		data.Type = "progress"
+		ge, err := uc.Services.GitOpsService.LoadData(pd.data, m, pd.templatesDestinationDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
+			return []string{}, err
+		}
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's resources already updated, skipping", m.Code))
+		} else {
+			uc.updateProgress(data, "Creating repositories branch for changes")
+			customBranch := pd.customBranch("resources/" + m.Code)
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
+				return []string{}, err
+			}
+			uc.updateProgress(data, fmt.Sprintf("Updating %s k8s resources", m.Code))
+			if err := uc.Services.GitOpsService.UpdateK8sResources(ge, pd.gitOpsDestinationDir); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error updating %s k8s resources", m.Code))
+				return []string{}, err
+			}
+			if err := uc.validateManifests(data, pd.gitOpsDestinationDir, ge.Config().K8sApplicationDestinationPath); err != nil {
+				return []string{}, err
+			}
+			prd.targetBranch = pd.gitOpsBranch
+			prd.actualBranch = customBranch
+			prd.message = fmt.Sprintf("feat: update %s - %s resources [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label)
+			prd.title = fmt.Sprintf("Update %s's %s resources", pd.data.ApplicationSlug(), m.Label)
+			prd.merge = merge
+			if prUrl, err := uc.makePr(prd, true); err != nil {
+				return []string{}, err
+			} else if prUrl != "" {
+				extraData = append(extraData, " -- "+prUrl)
+			}
+			data.Type = "success"
+			uc.updateProgress(data, fmt.Sprintf("%s's resources updated for %s's service", m.Code, pd.data.ApplicationSlug()))
+			uc.checkpoint(pd, step)
+		}
+		for _, e := range pd.data.Envs() {
+			step := fmt.Sprintf("%s/%s/%s", data.Step, e.Env().Code(), m.Code)
+			if pd.isDone(step) {
+				uc.updateProgress(data, fmt.Sprintf("%s's replicas already updated for %s environment, skipping", m.Code, e.Env().Code()))
+				continue
+			}
+			data.Type = "progress"
+			uc.updateProgress(data, fmt.Sprintf("Creating repositories branch for changes on %s environment", e.Env().Code()))
+			customBranch := pd.customBranch(fmt.Sprintf("resources/%s/%s", e.Env().Code(), m.Code))
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
+				return []string{}, err
+			}
+			uc.updateProgress(data, fmt.Sprintf("Updating replicas for %s environment", e.Env().Code()))
+			if err := uc.Services.GitOpsService.UpdateK8sReplicas(ge, pd.gitOpsDestinationDir, e); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error updating %s replicas on environment %s", m.Code, e.Env().Code()))
+				return []string{}, err
+			}
+			if err := uc.validateManifests(data, pd.gitOpsDestinationDir, ge.Config().K8sApplicationDestinationPath+"/overlays/"+e.Env().Code()); err != nil {
+				return []string{}, err
+			}
+			prd.targetBranch = pd.gitOpsBranch
+			prd.actualBranch = customBranch
+			prd.message = fmt.Sprintf("feat: update %s - %s replicas at %s environment [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label, e.Env().Label())
+			prd.title = fmt.Sprintf("Scale %s at %s environment with %s", pd.data.ApplicationSlug(), e.Env().Label(), m.Label)
+			prd.merge = !e.Env().RequireApproval()
+			if prUrl, err := uc.makePr(prd, true); err != nil {
+				return []string{}, err
+			} else if prUrl != "" {
+				extraData = append(extraData, " -- "+prUrl)
+			}
+			data.Type = "success"
+			uc.updateProgress(data, fmt.Sprintf("%s's replicas updated for %s's environment of %s's service", m.Code, e.Env().Code(), pd.data.ApplicationSlug()))
+			uc.checkpoint(pd, step)
+		}
+	}
+	if len(extraData) > 0 {
+		extraData = append([]string{"Pull requests waiting for approval:"}, extraData...)
+	}
+	return extraData, nil
+}
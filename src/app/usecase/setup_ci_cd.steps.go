@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"strings"
+)
+
+// Data shared between setup steps, used to declare their dependencies.
+const (
+	applicationRepositoryData = "application-repository"
+	templatesData             = "templates"
+	secretsData               = "secrets"
+	registryUrlData           = "registry-url"
+	gitOpsPathData            = "git-ops-path"
+	configMapPathData         = "config-map-path"
+	environmentsData          = "environments"
+	pipelineData              = "pipeline"
+	wikiData                  = "wiki"
+)
+
+// setupSteps lists every step of the setup process, a new manifest type only
+// needs a new step here.
+func (uc *setupCiCdUseCase) setupSteps() []*setupStep {
+	return []*setupStep{
+		{
+			code:     "clone-application",
+			provides: []string{applicationRepositoryData},
+			run:      uc.cloneApplication,
+		},
+		{
+			code:     "clone-templates",
+			provides: []string{templatesData},
+			run:      uc.cloneTemplates,
+		},
+		{
+			code:         "setup-secrets",
+			manifestType: entity.SecretManifests,
+			requires:     []string{templatesData},
+			provides:     []string{secretsData},
+			run:          uc.setupSecret,
+		},
+		{
+			code:         "create-registry",
+			manifestType: entity.RegistryManifests,
+			requires:     []string{templatesData},
+			provides:     []string{registryUrlData},
+			run:          uc.createRegistry,
+		},
+		{
+			code:         "setup-git-ops",
+			manifestType: entity.GitOpsManifests,
+			requires:     []string{templatesData},
+			provides:     []string{gitOpsPathData, configMapPathData, environmentsData},
+			run:          uc.setupGitOps,
+		},
+		{
+			code:         "create-pipeline",
+			manifestType: entity.PipelineManifests,
+			requires:     []string{templatesData, applicationRepositoryData},
+			provides:     []string{pipelineData},
+			run:          uc.createPipeline,
+		},
+		{
+			code:         "setup-wiki",
+			manifestType: entity.WikiManifests,
+			requires:     []string{templatesData, registryUrlData, gitOpsPathData, configMapPathData, environmentsData},
+			provides:     []string{wikiData},
+			run:          uc.setupWiki,
+		},
+	}
+}
+
+func (uc *setupCiCdUseCase) cloneApplication(pd *processData, _ []*entity.Manifest) ([]string, error) {
+	step := strings.ToLower("pre-process-setup-ci-cd-automation")
+	return nil, uc.stepClone(pd.ctx, pd.id, pd.data.ApplicationName(), pd.data.ApplicationName(), pd.applicationBranch, pd.applicationDestination, step)
+}
+
+func (uc *setupCiCdUseCase) cloneTemplates(pd *processData, _ []*entity.Manifest) ([]string, error) {
+	if err := uc.stepClone(pd.ctx, pd.id, "Templates", pd.templatesRepository, pd.templatesBranch, pd.templatesDestinationDir, ""); err != nil {
+		return nil, err
+	}
+	return nil, uc.pinTemplates(pd)
+}
+
+func (uc *setupCiCdUseCase) setupGitOps(pd *processData, gm []*entity.Manifest) ([]string, error) {
+	if err := uc.cloneGitOps(pd); err != nil {
+		return nil, err
+	}
+	additionalData, err := uc.createK8sManifests(pd, gm)
+	if err != nil {
+		return additionalData, err
+	}
+	cgm, err := uc.createGitOpsManifests(pd, gm)
+	return append(additionalData, cgm...), err
+}
+
+func (uc *setupCiCdUseCase) cloneGitOps(pd *processData) error {
+	ud := updateProgressData{
+		ID:      pd.id,
+		Step:    strings.ToLower("clone-git-ops-repositories"),
+		Message: "Cloning GitOps repositories",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(ud, "")
+	if err := uc.stepClone(pd.ctx, pd.id, "GitOps", pd.gitOpsRepository, pd.gitOpsBranch, pd.gitOpsDestinationDir, ud.Step); err != nil {
+		return err
+	}
+	if err := uc.stepClone(pd.ctx, pd.id, "GitOps-Tools", pd.gitOpsToolsRepository, pd.gitOpsToolsBranch, pd.gitOpsToolsDestinationDir, ud.Step); err != nil {
+		return err
+	}
+	if uc.config.SetupCiCd.ExternalConfigMap {
+		return uc.stepClone(pd.ctx, pd.id, "ConfigMap", pd.configMapRepository, pd.configMapBranch, pd.configMapDestinationDir, ud.Step)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+)
+
+// templatesRef returns the ref of the templates repository asked for the
+// process, or the one the template is pinned to.
+func (i CiCdInputDto) templatesRef(template entity.TemplateEntity) string {
+	if i.TemplatesRef != "" {
+		return i.TemplatesRef
+	}
+	return template.TemplatesRef()
+}
+
+// pinTemplates checks out the templates at the ref of the process and records
+// the commit they are at. A process retried is checked out at the commit
+// recorded by its first run, so it generates the same manifests. The template
+// is read again from the checkout, its manifests and params may differ from
+// the ones of the cached templates.
+func (uc *setupCiCdUseCase) pinTemplates(pd *processData) error {
+	data := updateProgressData{
+		ID:      pd.id,
+		Step:    "clone-templates-repository",
+		Message: "",
+		Type:    "progress",
+	}
+	ref := pd.templatesRef
+	metadata, err := uc.Repositories.ProgressRepository.GetMetadata(pd.id)
+	if err != nil {
+		uc.updateProgressError(data, err, "Error reading process metadata")
+		return err
+	}
+	if metadata != nil && metadata.TemplatesCommit != "" {
+		ref = metadata.TemplatesCommit
+	}
+	if ref != "" {
+		uc.updateProgress(data, fmt.Sprintf("Checking out templates at %s", ref))
+		if err := uc.Services.GitService.Checkout(pd.ctx, pd.templatesDestinationDir, entity.TemplatesRevision(ref)); err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error checking out templates at %s", ref))
+			return err
+		}
+	}
+	commit, err := uc.Services.GitService.HeadCommit(pd.ctx, pd.templatesDestinationDir)
+	if err != nil {
+		uc.updateProgressError(data, err, "Error reading templates commit")
+		return err
+	}
+	if err := uc.readPinnedTemplate(pd); err != nil {
+		uc.updateProgressError(data, err, fmt.Sprintf("Error reading template at commit %s", commit))
+		return err
+	}
+	pd.templatesCommit = commit
+	pd.updateCreatedData(func(c *entity.CreatedData) {
+		c.TemplatesCommit = commit
+	})
+	uc.updateMetadata(pd.id, func(m *entity.ProcessMetadata) {
+		m.TemplatesRef = pd.templatesRef
+		m.TemplatesCommit = commit
+	})
+	data.Type = "success"
+	uc.updateProgress(data, fmt.Sprintf("Templates at commit %s", commit))
+	return nil
+}
+
+// readPinnedTemplate replaces the template of the setup with its definition
+// on the templates checked out. The steps using the template require the
+// templates data, so none of them runs while it's replaced.
+func (uc *setupCiCdUseCase) readPinnedTemplate(pd *processData) error {
+	template, err := uc.Repositories.TemplateRepository.Read(pd.data.Template().Code(), pd.templatesDestinationDir)
+	if err != nil {
+		return err
+	}
+	return pd.data.PinTemplate(template)
+}
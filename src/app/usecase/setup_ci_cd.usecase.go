@@ -1,14 +1,17 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/zahirsis/dev-portal-backend/config"
 	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/app/workers"
 	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
 	"github.com/zahirsis/dev-portal-backend/src/domain/service"
 	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,12 +24,17 @@ type updateProgressData struct {
 }
 
 type processData struct {
-	id                        string
-	data                      entity.SetupCiCdEntity
-	rootDestinationDir        string
-	templatesRepository       string
-	templatesBranch           string
-	templatesDestinationDir   string
+	id                      string
+	ctx                     context.Context
+	data                    entity.SetupCiCdEntity
+	rootDestinationDir      string
+	templatesRepository     string
+	templatesBranch         string
+	templatesDestinationDir string
+	// templatesRef is the ref of the templates repository checked out after
+	// the clone and templatesCommit the commit it was resolved to.
+	templatesRef              string
+	templatesCommit           string
 	gitOpsRepository          string
 	gitOpsBranch              string
 	gitOpsDestinationDir      string
@@ -39,25 +47,58 @@ type processData struct {
 	applicationBranch         string
 	applicationDestination    string
 	defaultManifests          []*entity.Manifest
+	// unchangedEnvs are the environments of an application already set up
+	// that are kept as they are by the process.
+	unchangedEnvs []entity.SetupEnvData
+	plan          *setupPlan
+	checkpoints   map[string]bool
+	// mutex guards checkpoints and the entity's created data, which are
+	// shared by the steps running concurrently.
+	mutex sync.Mutex
 }
 
+func (p *processData) isDone(step string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.checkpoints[step]
+}
+
+func (p *processData) updateCreatedData(update func(c *entity.CreatedData)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	update(p.data.CreatedData())
+}
+
+// customBranch has the id of the process, the branches a process pushes
+// are only forced again by its retries.
 func (p *processData) customBranch(additionalName string) string {
 	if additionalName == "" {
-		return fmt.Sprintf("feature/%s", p.data.ApplicationSlug())
+		return fmt.Sprintf("feature/%s/%s", p.data.ApplicationSlug(), p.id)
 	}
-	return fmt.Sprintf("feature/%s/%s", p.data.ApplicationSlug(), additionalName)
+	return fmt.Sprintf("feature/%s/%s/%s", p.data.ApplicationSlug(), p.id, additionalName)
+}
+
+type EnvInputDto struct {
+	Code     string               `json:"code"`
+	Replicas entity.LimitsIntData `json:"replicas"`
 }
 
 type CiCdInputDto struct {
-	Template string `json:"template"`
-	Envs     []struct {
-		Code     string               `json:"code"`
-		Replicas entity.LimitsIntData `json:"replicas"`
-	} `json:"envs"`
+	Template    string                 `json:"template"`
+	Envs        []EnvInputDto          `json:"envs"`
 	Manifests   []string               `json:"manifests"`
 	Squad       string                 `json:"squad"`
 	Application entity.ApplicationData `json:"application"`
 	Ingress     entity.IngressData     `json:"ingress"`
+	DryRun      bool                   `json:"dryRun"`
+	// Params are the values of the params declared by the template
+	Params map[string]interface{} `json:"params,omitempty"`
+	// TemplatesRef is a tag or commit of the templates repository, it
+	// overrides the one pinned by the template.
+	TemplatesRef string `json:"templatesRef,omitempty"`
+	// CreatedBy is the user requesting the setup, it's kept on the process
+	// metadata instead of the input.
+	CreatedBy string `json:"-"`
 }
 
 type CiCdOutputDto struct {
@@ -67,15 +108,66 @@ type CiCdOutputDto struct {
 
 type SetupCiCdUseCase interface {
 	Exec(i CiCdInputDto) CiCdOutputDto
+	Rollback(ID string) error
+	Retry(ID string) CiCdOutputDto
+	Cancel(ID string) error
+	Decommission(slug string) CiCdOutputDto
+	AddEnvironments(slug string, i AddEnvironmentsInputDto) CiCdOutputDto
+	ChangeResources(slug string, i ChangeResourcesInputDto) CiCdOutputDto
+	Promote(slug string, i PromoteInputDto) CiCdOutputDto
+	// Render returns the files the setup of the template would generate,
+	// without creating anything.
+	Render(ctx context.Context, code string, i CiCdInputDto) (*RenderOutputDto, []error)
+	workers.JobHandler
 }
 
 type setupCiCdUseCase struct {
 	*container.Container
-	config *config.Config
+	config            *config.Config
+	steps             *setupGraph
+	decommissionGraph *setupGraph
+	addEnvsGraph      *setupGraph
+	resourcesGraph    *setupGraph
+	promoteGraph      *setupGraph
+	progressMutex     sync.Mutex
+	progressLocks     map[string]*progressLock
+	// renders holds a slot for each render cloning the templates
+	renders chan struct{}
+}
+
+// progressLock serializes the progress of a process, it's removed when no
+// update of the process is waiting for it.
+type progressLock struct {
+	sync.Mutex
+	waiting int
 }
 
-func NewSetupCiCdUseCase(c *container.Container, cfg *config.Config) SetupCiCdUseCase {
-	return &setupCiCdUseCase{c, cfg}
+// NewSetupCiCdUseCase fails when the steps of a process can't be ordered.
+func NewSetupCiCdUseCase(c *container.Container, cfg *config.Config) (SetupCiCdUseCase, error) {
+	uc := &setupCiCdUseCase{
+		Container:     c,
+		config:        cfg,
+		progressLocks: make(map[string]*progressLock),
+		renders:       make(chan struct{}, max(cfg.SetupCiCd.MaxConcurrentRenders, 1)),
+	}
+	for _, g := range []struct {
+		name  string
+		graph **setupGraph
+		steps []*setupStep
+	}{
+		{"setup", &uc.steps, uc.setupSteps()},
+		{"decommission", &uc.decommissionGraph, uc.decommissionSteps()},
+		{"add environments", &uc.addEnvsGraph, uc.addEnvironmentsSteps()},
+		{"change resources", &uc.resourcesGraph, uc.changeResourcesSteps()},
+		{"promote", &uc.promoteGraph, uc.promoteSteps()},
+	} {
+		graph, err := newSetupGraph(g.steps)
+		if err != nil {
+			return nil, fmt.Errorf("building %s steps: %w", g.name, err)
+		}
+		*g.graph = graph
+	}
+	return uc, nil
 }
 
 func (uc *setupCiCdUseCase) Exec(i CiCdInputDto) CiCdOutputDto {
@@ -84,7 +176,10 @@ func (uc *setupCiCdUseCase) Exec(i CiCdInputDto) CiCdOutputDto {
 	var errs []error
 	e, errs := uc.makeEntity(i, processID)
 	errs = append(errs, uc.Services.CiCdService.ValidateSetup(e)...)
-	dm, err := uc.defaultManifests()
+	if i.TemplatesRef != "" && !entity.ValidTemplatesRef(i.TemplatesRef) {
+		errs = append(errs, errors.NewInputError("templatesRef", []string{"templates ref must be a tag or a commit"}))
+	}
+	_, err := uc.defaultManifests()
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -92,10 +187,136 @@ func (uc *setupCiCdUseCase) Exec(i CiCdInputDto) CiCdOutputDto {
 		uc.Logger.Debug("ERRORS VALIDATE SETUP:", errs)
 		return CiCdOutputDto{Errors: errs}
 	}
-	// process
+	if err := uc.checkApplication(e.ApplicationSlug(), processID); err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	// Dry runs don't push anything, so they don't need the application lock
+	if !i.DryRun {
+		if err := uc.lockApplication(e.ApplicationSlug(), processID); err != nil {
+			return CiCdOutputDto{Errors: []error{err}}
+		}
+	}
+	input, err := json.Marshal(i)
+	if err == nil {
+		err = uc.Repositories.ProgressRepository.SaveInput(processID, input)
+	}
+	if err != nil {
+		uc.Logger.Error("Error saving process input: %s", err.Error())
+		uc.unlockApplication(e.ApplicationSlug(), processID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	var environments []string
+	for _, env := range e.Envs() {
+		environments = append(environments, env.Env().Code())
+	}
+	err = uc.Repositories.ProgressRepository.SaveMetadata(entity.ProcessMetadata{
+		ID:           processID,
+		Kind:         entity.SetupCiCdJob,
+		Squad:        e.Squad().Code(),
+		Application:  e.ApplicationSlug(),
+		Template:     e.Template().Code(),
+		Environments: environments,
+		DryRun:       i.DryRun,
+		CreatedBy:    i.CreatedBy,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		uc.Logger.Error("Error saving process metadata: %s", err.Error())
+		uc.unlockApplication(e.ApplicationSlug(), processID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	if err := uc.enqueue(processID, entity.SetupCiCdJob, ""); err != nil {
+		uc.unlockApplication(e.ApplicationSlug(), processID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	return CiCdOutputDto{Errors: nil, ProcessId: processID}
+}
+
+func (uc *setupCiCdUseCase) Retry(ID string) CiCdOutputDto {
+	uc.Logger.Debug("RECEIVED REQUEST: ci-cd/setup/retry", ID)
+	status, err := uc.Repositories.ProgressRepository.GetStatus(ID)
+	if err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	if status == "" {
+		return CiCdOutputDto{Errors: []error{errors.NewInputError("id", []string{"process not found"})}}
+	}
+	if !status.Done() {
+		return CiCdOutputDto{Errors: []error{errors.NewInputError("id", []string{"process must be finished to be retried"})}}
+	}
+	if metadata, err := uc.Repositories.ProgressRepository.GetMetadata(ID); err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	} else if metadata != nil && metadata.Kind != "" && metadata.Kind != entity.SetupCiCdJob {
+		return uc.retryApplicationProcess(ID, metadata.Application, metadata.Kind, status)
+	}
+	pd, errs := uc.loadProcess(context.Background(), ID)
+	if len(errs) > 0 {
+		uc.Logger.Debug("ERRORS VALIDATE SETUP:", errs)
+		return CiCdOutputDto{Errors: errs}
+	}
+	if pd.isDone("finish-setup") {
+		return CiCdOutputDto{Errors: []error{errors.NewInputError("id", []string{"process already finished with success"})}}
+	}
+	slug := pd.data.ApplicationSlug()
+	if err := uc.checkApplication(slug, ID); err != nil {
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	if pd.plan == nil {
+		if err := uc.lockApplication(slug, ID); err != nil {
+			return CiCdOutputDto{Errors: []error{err}}
+		}
+	}
+	if err := uc.enqueue(ID, entity.SetupCiCdJob, status); err != nil {
+		uc.unlockApplication(slug, ID)
+		return CiCdOutputDto{Errors: []error{err}}
+	}
+	return CiCdOutputDto{Errors: nil, ProcessId: ID}
+}
+
+// loadProcess rebuilds a process from its saved input and checkpoints.
+func (uc *setupCiCdUseCase) loadProcess(ctx context.Context, ID string) (*processData, []error) {
+	input, err := uc.Repositories.ProgressRepository.GetInput(ID)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if input == nil {
+		return nil, []error{errors.NewInputError("id", []string{"process not found"})}
+	}
+	var i CiCdInputDto
+	if err := json.Unmarshal(input, &i); err != nil {
+		return nil, []error{err}
+	}
+	e, errs := uc.makeEntity(i, ID)
+	if len(errs) == 0 {
+		errs = append(errs, uc.Services.CiCdService.ValidateSetup(e)...)
+	}
+	dm, err := uc.defaultManifests()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	steps, created, err := uc.Repositories.ProgressRepository.GetCheckpoints(ID)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if created != nil {
+		*e.CreatedData() = *created
+	}
+	pd := uc.newProcessData(ctx, ID, e, dm, i.DryRun)
+	pd.templatesRef = i.templatesRef(e.Template())
+	for _, step := range steps {
+		pd.checkpoints[step] = true
+	}
+	return pd, nil
+}
+
+func (uc *setupCiCdUseCase) newProcessData(ctx context.Context, processID string, e entity.SetupCiCdEntity, dm []*entity.Manifest, dryRun bool) *processData {
 	sc := uc.config.SetupCiCd
 	data := &processData{
 		id:                        processID,
+		ctx:                       ctx,
 		data:                      e,
 		rootDestinationDir:        strings.Replace(sc.RootDestinationsPath, "{{process-id}}", processID, -1),
 		templatesRepository:       sc.TemplatesRepository,
@@ -113,9 +334,12 @@ func (uc *setupCiCdUseCase) Exec(i CiCdInputDto) CiCdOutputDto {
 		applicationBranch:         sc.ApplicationMainBranch,
 		applicationDestination:    strings.Replace(sc.ApplicationDestinationDir, "{{process-id}}", processID, -1),
 		defaultManifests:          dm,
+		checkpoints:               make(map[string]bool),
 	}
-	go uc.process(data)
-	return CiCdOutputDto{Errors: nil, ProcessId: processID}
+	if dryRun {
+		data.plan = newSetupPlan()
+	}
+	return data
 }
 
 func (uc *setupCiCdUseCase) process(pd *processData) {
@@ -126,7 +350,6 @@ func (uc *setupCiCdUseCase) process(pd *processData) {
 		}
 	}()
 	uc.Logger.Debug("PROCESSING", pd.id)
-	var additionalData []string
 
 	// Step: Pre Process Setup Ci/CD Automation
 	ud := updateProgressData{
@@ -137,105 +360,12 @@ func (uc *setupCiCdUseCase) process(pd *processData) {
 		IsNode:  true,
 	}
 	uc.updateProgress(ud, "")
-	if err := uc.stepClone(pd.id, pd.data.ApplicationName(), pd.data.ApplicationName(), pd.applicationBranch, pd.applicationDestination, ud.Step); err != nil {
-		uc.finish(pd, additionalData, true)
-		return
-	}
-	// Step: Clone templates repository
-	if err := uc.stepClone(pd.id, "Templates", pd.templatesRepository, pd.templatesBranch, pd.templatesDestinationDir, ""); err != nil {
-		uc.finish(pd, additionalData, true)
-		return
-	}
-	// Step: Create secrets
-	sm := uc.getManifests(pd, entity.SecretManifests)
-	if len(sm) > 0 {
-		sw, err := uc.setupSecret(pd, sm)
-		additionalData = append(additionalData, sw...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-	}
-
-	// Step: Create registry
-	rm := uc.getManifests(pd, entity.RegistryManifests)
-	if len(rm) > 0 {
-		cr, err := uc.createRegistry(pd, rm)
-		additionalData = append(additionalData, cr...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-	}
-	// Group Step: GitOps and k8s manifests
-	gm := uc.getManifests(pd, entity.GitOpsManifests)
-	if len(gm) > 0 {
-		ud := updateProgressData{
-			ID:      pd.id,
-			Step:    strings.ToLower("clone-git-ops-repositories"),
-			Message: "Cloning GitOps repositories",
-			Type:    "progress",
-			IsNode:  true,
-		}
-		uc.updateProgress(ud, "")
-		// Step: Clone gitOps repository
-		err := uc.stepClone(pd.id, "GitOps", pd.gitOpsRepository, pd.gitOpsBranch, pd.gitOpsDestinationDir, ud.Step)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-		// Step: Clone gitOps-tools repository
-		err = uc.stepClone(pd.id, "GitOps-Tools", pd.gitOpsToolsRepository, pd.gitOpsToolsBranch, pd.gitOpsToolsDestinationDir, ud.Step)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-		if uc.config.SetupCiCd.ExternalConfigMap {
-			// Step: Clone configMap repository
-			err = uc.stepClone(pd.id, "ConfigMap", pd.configMapRepository, pd.configMapBranch, pd.configMapDestinationDir, ud.Step)
-			if err != nil {
-				uc.finish(pd, additionalData, true)
-				return
-			}
-		}
-		// Step: Create k8s manifests
-		ck8s, err := uc.createK8sManifests(pd, gm)
-		additionalData = append(additionalData, ck8s...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-		// Step: Create GitOps manifests
-		cgm, err := uc.createGitOpsManifests(pd, gm)
-		additionalData = append(additionalData, cgm...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-	}
-	// Step Group: Pipeline
-	pm := uc.getManifests(pd, entity.PipelineManifests)
-	if len(pm) > 0 {
-		// Step: Create pipeline
-		cp, err := uc.createPipeline(pd, pm)
-		additionalData = append(additionalData, cp...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
-	}
-	// Step: Create wiki
-	wm := uc.getManifests(pd, entity.WikiManifests)
-	if len(wm) > 0 {
-		sw, err := uc.setupWiki(pd, wm)
-		additionalData = append(additionalData, sw...)
-		if err != nil {
-			uc.finish(pd, additionalData, true)
-			return
-		}
+	if len(pd.checkpoints) > 0 {
+		uc.updateProgress(ud, fmt.Sprintf("Resuming process, %d steps already done will be skipped", len(pd.checkpoints)))
 	}
+	additionalData, err := uc.runSteps(pd, uc.steps)
 	// Step: Mark as finish
-	uc.finish(pd, additionalData, false)
+	uc.finish(pd, additionalData, err != nil)
 }
 
 func (uc *setupCiCdUseCase) makeEntity(i CiCdInputDto, ID string) (entity.SetupCiCdEntity, []error) {
@@ -276,10 +406,12 @@ func (uc *setupCiCdUseCase) makeEntity(i CiCdInputDto, ID string) (entity.SetupC
 	}
 
 	var manifests []*entity.Manifest
-	for _, m := range template.Manifests() {
-		for _, v := range i.Manifests {
-			if m.Code == v {
-				manifests = append(manifests, m)
+	if template != nil {
+		for _, m := range template.Manifests() {
+			for _, v := range i.Manifests {
+				if m.Code == v {
+					manifests = append(manifests, m)
+				}
 			}
 		}
 	}
@@ -292,6 +424,7 @@ func (uc *setupCiCdUseCase) makeEntity(i CiCdInputDto, ID string) (entity.SetupC
 		Squad:       squad,
 		Application: i.Application,
 		Ingress:     i.Ingress,
+		Params:      i.Params,
 	}), errs
 }
 
@@ -305,8 +438,17 @@ func (uc *setupCiCdUseCase) setupSecret(pd *processData, manifests []*entity.Man
 	}
 	uc.updateProgress(data, "")
 	data.IsNode = false
+	if pd.isDone(data.Step) {
+		data.Type = "success"
+		uc.updateProgress(data, "Secrets already created, skipping")
+		return nil, nil
+	}
 	var extraData []string
 	for _, v := range manifests {
+		if err := pd.ctx.Err(); err != nil {
+			uc.updateProgressError(data, err, "Secrets creation interrupted")
+			return []string{}, err
+		}
 		data.Type = "progress"
 		uc.updateProgress(data, fmt.Sprintf("Creating %s for %s using %s manifests", v.Label, pd.data.ApplicationName(), v.Code))
 		secret, err := uc.Services.SecretService.LoadData(pd.data, v, pd.templatesDestinationDir)
@@ -315,11 +457,24 @@ func (uc *setupCiCdUseCase) setupSecret(pd *processData, manifests []*entity.Man
 			return []string{}, err
 		}
 		for _, env := range pd.data.Envs() {
-			err = uc.Services.SecretService.SetupNewSecret(secret, env)
+			if pd.plan != nil {
+				pd.plan.addResource("%s: %s - %s", v.Label, secret.Config().GetRootPath(env), secret.Config().GetSecretPath(env))
+				uc.updateProgress(data, fmt.Sprintf("Dry run: skipping %s's secrets creation on %s environment", v.Label, env.Env().Code()))
+				continue
+			}
+			created, err := uc.Services.SecretService.SetupNewSecret(secret, env)
 			if err != nil {
 				uc.updateProgressError(data, err, fmt.Sprintf("Error creating secret with %s manifests", v.Code))
 				return []string{}, err
 			}
+			if created {
+				uc.registerCompensation(pd.id, entity.Compensation{
+					Kind:        entity.DeleteSecretCompensation,
+					Description: fmt.Sprintf("Deleting %s secret %s - %s", v.Label, secret.Config().GetRootPath(env), secret.Config().GetSecretPath(env)),
+					Location:    secret.Config().GetRootPath(env),
+					Path:        secret.Config().GetSecretPath(env),
+				})
+			}
 			extraData = append(extraData, fmt.Sprintf(" -- %s: %s - %s", v.Label, secret.Config().GetRootPath(env), secret.Config().GetSecretPath(env)))
 			data.Type = "success"
 			uc.updateProgress(data, fmt.Sprintf("%s's secrets created for %s's service", v.Label, pd.data.ApplicationSlug()))
@@ -328,6 +483,7 @@ func (uc *setupCiCdUseCase) setupSecret(pd *processData, manifests []*entity.Man
 	if len(extraData) > 0 {
 		extraData = append([]string{"Secrets created:"}, extraData...)
 	}
+	uc.checkpoint(pd, data.Step)
 	return extraData, nil
 }
 
@@ -341,6 +497,11 @@ func (uc *setupCiCdUseCase) createRegistry(pd *processData, manifests []*entity.
 	}
 	uc.updateProgress(data, "")
 	data.IsNode = false
+	if pd.isDone(data.Step) {
+		data.Type = "success"
+		uc.updateProgress(data, "Registry already created, skipping")
+		return nil, nil
+	}
 	var extraData []string
 	for _, v := range manifests {
 		data.Type = "progress"
@@ -350,16 +511,31 @@ func (uc *setupCiCdUseCase) createRegistry(pd *processData, manifests []*entity.
 			uc.updateProgressError(data, err, fmt.Sprintf("Error creating registry with %s manifests", v.Code))
 			return []string{}, err
 		}
-		url, err := uc.Services.RegistryApiService.Create(registry)
+		if pd.plan != nil {
+			pd.plan.addResource("%s: %s (%s)", v.Label, *registry.Name(), registry.Config().Region)
+			uc.updateProgress(data, fmt.Sprintf("Dry run: skipping %s creation for %s", v.Label, pd.data.ApplicationName()))
+			continue
+		}
+		url, created, err := uc.Services.RegistryApiService.Create(pd.ctx, registry)
+		if created {
+			uc.registerCompensation(pd.id, entity.Compensation{
+				Kind:        entity.DeleteRegistryCompensation,
+				Description: fmt.Sprintf("Deleting %s %s", v.Label, *registry.Name()),
+				Name:        *registry.Name(),
+				Region:      registry.Config().Region,
+				RegistryId:  registry.Config().RegistryId,
+			})
+		}
 		if err != nil {
 			uc.updateProgressError(data, err, fmt.Sprintf("Error creating registry with %s manifests", v.Code))
 			return []string{}, err
 		}
 		extraData = append(extraData, fmt.Sprintf("Registry url: https://%s", url))
-		pd.data.CreatedData().RegistryUrl = url
+		pd.updateCreatedData(func(c *entity.CreatedData) { c.RegistryUrl = url })
 		data.Type = "success"
 		uc.updateProgress(data, fmt.Sprintf("%s's registry created for %s", v.Label, pd.data.ApplicationName()))
 	}
+	uc.checkpoint(pd, data.Step)
 	return extraData, nil
 }
 
@@ -376,17 +552,22 @@ func (uc *setupCiCdUseCase) createK8sManifests(pd *processData, gm []*entity.Man
 	var extraData []string
 
 	for _, m := range gm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's k8s manifests already created, skipping", m.Code))
+			continue
+		}
 		data.Type = "progress"
 		uc.updateProgress(data, "Creating repositories branch for changes")
 		customBranch := pd.customBranch(m.Code)
-		if err := uc.newBranchFromDefault(data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsDestinationDir, pd.gitOpsBranch, customBranch); err != nil {
 			return []string{}, err
 		}
-		if err := uc.newBranchFromDefault(data, pd.gitOpsToolsDestinationDir, pd.gitOpsToolsBranch, customBranch); err != nil {
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsToolsDestinationDir, pd.gitOpsToolsBranch, customBranch); err != nil {
 			return []string{}, err
 		}
 		if uc.config.SetupCiCd.ExternalConfigMap {
-			if err := uc.newBranchFromDefault(data, pd.configMapDestinationDir, pd.configMapBranch, customBranch); err != nil {
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.configMapDestinationDir, pd.configMapBranch, customBranch); err != nil {
 				return []string{}, err
 			}
 		}
@@ -411,27 +592,36 @@ func (uc *setupCiCdUseCase) createK8sManifests(pd *processData, gm []*entity.Man
 		}
 
 		uc.updateProgress(data, "Configuring k8s manifests for service")
-		kd, err := uc.Services.GitOpsService.SetupK8sManifests(ge, pd.templatesDestinationDir, pd.gitOpsDestinationDir, pd.configMapDestinationDir)
+		kd, environments, err := uc.Services.GitOpsService.SetupK8sManifests(ge, pd.templatesDestinationDir, pd.gitOpsDestinationDir, pd.configMapDestinationDir)
 		extraData = append(extraData, kd...)
 		if err != nil {
 			uc.updateProgressError(data, err, fmt.Sprintf("Error creating k8s manifests from %s templates", m.Code))
 			return []string{}, err
 		}
+		pd.updateCreatedData(func(c *entity.CreatedData) { c.Environments = append(c.Environments, environments...) })
+		if err := uc.validateManifests(data, pd.gitOpsDestinationDir, ge.Config().K8sApplicationDestinationPath); err != nil {
+			return []string{}, err
+		}
 		commitMessage := fmt.Sprintf("feat: add %s - %s manifests [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label)
 		prd := pullRequestData{
-			pd:           data,
-			localDir:     pd.gitOpsDestinationDir,
-			repository:   uc.config.SetupCiCd.GitOpsRepository,
-			targetBranch: pd.gitOpsBranch,
-			actualBranch: customBranch,
-			message:      commitMessage,
-			title:        fmt.Sprintf("Create %s's %s manifests", pd.data.ApplicationSlug(), m.Label),
-			merge:        true,
+			pd:              data,
+			localDir:        pd.gitOpsDestinationDir,
+			repository:      uc.config.SetupCiCd.GitOpsRepository,
+			targetBranch:    pd.gitOpsBranch,
+			actualBranch:    customBranch,
+			message:         commitMessage,
+			title:           fmt.Sprintf("Create %s's %s manifests", pd.data.ApplicationSlug(), m.Label),
+			merge:           true,
+			requireApproval: requireApproval(pd.data.Envs()),
+			ctx:             pd.ctx,
+			plan:            pd.plan,
+			templatesCommit: pd.templatesCommit,
 		}
 		if _, err := uc.makePr(prd, true); err != nil {
 			return []string{}, err
 		}
-		pd.data.CreatedData().GitOpsPath = uc.config.GitConfig.GetRepositoryUrl(prd.repository) + "/" + ge.Config().K8sApplicationDestinationPath
+		gitOpsPath := uc.config.GitConfig.GetRepositoryUrl(prd.repository) + "/" + ge.Config().K8sApplicationDestinationPath
+		pd.updateCreatedData(func(c *entity.CreatedData) { c.GitOpsPath = gitOpsPath })
 
 		prd.localDir = pd.gitOpsToolsDestinationDir
 		prd.targetBranch = pd.gitOpsToolsBranch
@@ -448,10 +638,12 @@ func (uc *setupCiCdUseCase) createK8sManifests(pd *processData, gm []*entity.Man
 				return []string{}, err
 			}
 		}
-		pd.data.CreatedData().ConfigMapPath = uc.config.GitConfig.GetRepositoryUrl(prd.repository) + "/src/" + pd.configMapBranch + "/" + ge.Config().K8sConfigMapDestinationPath
+		configMapPath := uc.config.GitConfig.GetRepositoryUrl(prd.repository) + "/src/" + pd.configMapBranch + "/" + ge.Config().K8sConfigMapDestinationPath
+		pd.updateCreatedData(func(c *entity.CreatedData) { c.ConfigMapPath = configMapPath })
 
 		data.Type = "success"
 		uc.updateProgress(data, fmt.Sprintf("%s's manifests created for %s's service", m.Code, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
 	}
 	return extraData, nil
 }
@@ -468,10 +660,13 @@ func (uc *setupCiCdUseCase) createGitOpsManifests(pd *processData, gm []*entity.
 	data.IsNode = false
 	var extraData []string
 	prd := pullRequestData{
-		pd:           data,
-		localDir:     pd.gitOpsToolsDestinationDir,
-		repository:   uc.config.SetupCiCd.GitOpsToolsRepository,
-		targetBranch: pd.gitOpsToolsBranch,
+		pd:              data,
+		localDir:        pd.gitOpsToolsDestinationDir,
+		repository:      uc.config.SetupCiCd.GitOpsToolsRepository,
+		targetBranch:    pd.gitOpsToolsBranch,
+		ctx:             pd.ctx,
+		plan:            pd.plan,
+		templatesCommit: pd.templatesCommit,
 	}
 	for _, m := range gm {
 		data.Type = "progress"
@@ -482,17 +677,25 @@ func (uc *setupCiCdUseCase) createGitOpsManifests(pd *processData, gm []*entity.
 			return []string{}, err
 		}
 		for _, e := range pd.data.Envs() {
+			step := fmt.Sprintf("%s/%s/%s", data.Step, e.Env().Code(), m.Code)
+			if pd.isDone(step) {
+				uc.updateProgress(data, fmt.Sprintf("%s's manifests already created for %s environment, skipping", m.Code, e.Env().Code()))
+				continue
+			}
 			data.Type = "progress"
 			// Create branch for changes
 			uc.updateProgress(data, fmt.Sprintf("Creating repositories branch for changes on %s environment", e.Env().Code()))
 			customBranch := pd.customBranch(fmt.Sprintf("%s/%s", e.Env().Code(), m.Code))
-			if err := uc.newBranchFromDefault(data, pd.gitOpsToolsDestinationDir, pd.gitOpsToolsBranch, customBranch); err != nil {
+			if err := uc.newBranchFromDefault(pd.ctx, data, pd.gitOpsToolsDestinationDir, pd.gitOpsToolsBranch, customBranch); err != nil {
 				return []string{}, err
 			}
 			uc.updateProgress(data, fmt.Sprintf("Creating manifests for %s environment", e.Env().Code()))
 			err = uc.Services.GitOpsService.SetupGitOpsManifests(ge, pd.templatesDestinationDir, pd.gitOpsToolsDestinationDir, e)
 			if err != nil {
-				uc.updateProgressError(data, err, fmt.Sprintf("Error creating manifests from %s gitOps templates on environment %s", m.Code))
+				uc.updateProgressError(data, err, fmt.Sprintf("Error creating manifests from %s gitOps templates on environment %s", m.Code, e.Env().Code()))
+				return []string{}, err
+			}
+			if err := uc.validateManifests(data, pd.gitOpsToolsDestinationDir, uc.Services.GitOpsService.GitOpsApplicationPath(ge, e)); err != nil {
 				return []string{}, err
 			}
 			commitMessage := fmt.Sprintf("feat: add %s - %s manifests at %s environment [Setup Ci/CD Automation]", pd.data.ApplicationSlug(), m.Label, e.Env().Label())
@@ -507,6 +710,7 @@ func (uc *setupCiCdUseCase) createGitOpsManifests(pd *processData, gm []*entity.
 			}
 			data.Type = "success"
 			uc.updateProgress(data, fmt.Sprintf("%s's manifests created for %s's environment of %s's service", m.Code, e.Env().Code(), pd.data.ApplicationSlug()))
+			uc.checkpoint(pd, step)
 		}
 	}
 	if len(extraData) > 0 {
@@ -515,6 +719,31 @@ func (uc *setupCiCdUseCase) createGitOpsManifests(pd *processData, gm []*entity.
 	return extraData, nil
 }
 
+// validateManifests checks the generated manifests under the paths of the
+// repository against the kubernetes schemas, every violation is sent to the
+// progress stream and fails the step before anything is pushed.
+func (uc *setupCiCdUseCase) validateManifests(data updateProgressData, repositoryDir string, paths ...string) error {
+	uc.updateProgress(data, "Validating manifests against kubernetes schemas")
+	result, err := uc.Services.ManifestSchemaService.Validate(repositoryDir, paths...)
+	if err != nil {
+		uc.updateProgressError(data, err, "Error validating manifests")
+		return err
+	}
+	for _, s := range result.Skipped {
+		uc.updateProgress(data, "Skipping validation of "+s)
+	}
+	if len(result.Violations) == 0 {
+		return nil
+	}
+	data.Type = "error"
+	for _, v := range result.Violations {
+		uc.updateProgress(data, v.String())
+	}
+	err = fmt.Errorf("%d schema violation(s) found on the generated manifests", len(result.Violations))
+	uc.updateProgress(data, "Error: "+err.Error())
+	return err
+}
+
 func (uc *setupCiCdUseCase) createPipeline(pd *processData, pm []*entity.Manifest) ([]string, error) {
 	data := updateProgressData{
 		ID:      pd.id,
@@ -528,46 +757,62 @@ func (uc *setupCiCdUseCase) createPipeline(pd *processData, pm []*entity.Manifes
 	var extraData []string
 
 	for _, m := range pm {
+		step := fmt.Sprintf("%s/%s", data.Step, m.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s's pipeline already created, skipping", m.Code))
+			continue
+		}
 		data.Type = "progress"
 		pe, err := uc.Services.PipelineService.LoadData(pd.data, m, pd.templatesDestinationDir)
 		if err != nil {
 			uc.updateProgressError(data, err, fmt.Sprintf("Error loading data from %s manifest", m.Code))
 			return []string{}, err
 		}
-		// Enabling pipelines and setting up variables
-		uc.updateProgress(data, fmt.Sprintf("Enabling pipelines on %s repository", pd.data.ApplicationName()))
-		if err := uc.Services.GitApiService.EnablePipelines(pd.data.ApplicationName()); err != nil {
-			uc.updateProgressError(data, err, fmt.Sprintf("Error enabling pipelines on %s repository", pd.data.ApplicationName()))
-			return extraData, err
-		}
-		// Setting up variables
-		uc.updateProgress(data, fmt.Sprintf("Setting up variables on %s repository", pd.data.ApplicationName()))
-		if err := uc.Services.GitApiService.SetRepositoryVariables(pd.data.ApplicationName(), uc.getRepositoryVariables(pe.Config().DefaultVariables)); err != nil {
-			uc.updateProgressError(data, err, fmt.Sprintf("Error setting up variables on %s repository", pd.data.ApplicationName()))
-			return extraData, err
-		}
-		// Setting up environment variables
-		var environments []*service.PipelineEnvironment
-		for _, e := range pd.data.Envs() {
-			uc.updateProgress(data, fmt.Sprintf("Setting up variables on %s repository for %s environment", pd.data.ApplicationName(), e.Env().Code()))
-			if _, ok := pe.Config().Environments[e.Env().Code()]; !ok {
-				continue
+		environments := uc.getPipelineEnvironments(pe, pd.data.Envs())
+		if pd.plan != nil {
+			pd.plan.addResource("Pipelines enabled on %s repository", pd.data.ApplicationName())
+			if reviewers := pd.data.Squad().DefaultReviewers(); len(reviewers) > 0 {
+				pd.plan.addResource("%d default reviewers on %s repository", len(reviewers), pd.data.ApplicationName())
+			}
+			pd.plan.addResource("%d repository variables on %s repository", len(pe.Config().DefaultVariables), pd.data.ApplicationName())
+			for _, e := range environments {
+				pd.plan.addResource("Deployment environment %s with %d variables on %s repository", e.Name, len(e.Variables), pd.data.ApplicationName())
+			}
+			uc.updateProgress(data, fmt.Sprintf("Dry run: skipping pipelines and variables setup on %s repository", pd.data.ApplicationName()))
+		} else {
+			// Enabling pipelines and setting up variables
+			uc.updateProgress(data, fmt.Sprintf("Enabling pipelines on %s repository", pd.data.ApplicationName()))
+			if err := uc.Services.GitApiService.EnablePipelines(pd.ctx, pd.data.ApplicationName()); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error enabling pipelines on %s repository", pd.data.ApplicationName()))
+				return extraData, err
+			}
+			if reviewers := pd.data.Squad().DefaultReviewers(); len(reviewers) > 0 {
+				uc.updateProgress(data, fmt.Sprintf("Adding %s's default reviewers on %s repository", pd.data.Squad().Label(), pd.data.ApplicationName()))
+				if err := uc.Services.GitApiService.AddDefaultReviewers(pd.ctx, pd.data.ApplicationName(), reviewers); err != nil {
+					uc.updateProgressError(data, err, fmt.Sprintf("Error adding default reviewers on %s repository", pd.data.ApplicationName()))
+					return extraData, err
+				}
+			}
+			// Setting up variables
+			uc.updateProgress(data, fmt.Sprintf("Setting up variables on %s repository", pd.data.ApplicationName()))
+			if err := uc.Services.GitApiService.SetRepositoryVariables(pd.ctx, pd.data.ApplicationName(), uc.getRepositoryVariables(pe.Config().DefaultVariables)); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error setting up variables on %s repository", pd.data.ApplicationName()))
+				return extraData, err
+			}
+			// Setting up environment variables
+			for _, e := range pd.data.Envs() {
+				uc.updateProgress(data, fmt.Sprintf("Setting up variables on %s repository for %s environment", pd.data.ApplicationName(), e.Env().Code()))
+			}
+			if err := uc.Services.GitApiService.SetRepositoryEnvironmentsVariables(pd.ctx, pd.data.ApplicationName(), environments); err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error setting up environments' variables on %s repository", pd.data.ApplicationName()))
+				return extraData, err
 			}
-			variables := uc.getRepositoryVariables(pe.Config().Environments[e.Env().Code()].Variables)
-			environments = append(environments, &service.PipelineEnvironment{
-				Name:      pe.Config().Environments[e.Env().Code()].Triggers[0].Deployment,
-				Variables: variables,
-			})
-		}
-		if err := uc.Services.GitApiService.SetRepositoryEnvironmentsVariables(pd.data.ApplicationName(), environments); err != nil {
-			uc.updateProgressError(data, err, fmt.Sprintf("Error setting up environments' variables on %s repository", pd.data.ApplicationName()))
-			return extraData, err
 		}
 
 		// Create branch for changes
 		uc.updateProgress(data, "Creating new branch for add pipeline files")
 		customBranch := pd.customBranch(m.Code)
-		if err := uc.newBranchFromDefault(data, pd.applicationDestination, pd.applicationBranch, customBranch); err != nil {
+		if err := uc.newBranchFromDefault(pd.ctx, data, pd.applicationDestination, pd.applicationBranch, customBranch); err != nil {
 			return []string{}, err
 		}
 		uc.updateProgress(data, fmt.Sprintf("Creating %s pipeline", m.Code))
@@ -578,20 +823,24 @@ func (uc *setupCiCdUseCase) createPipeline(pd *processData, pm []*entity.Manifes
 		}
 		commitMessage := "feat: add pipeline files [Setup Ci/CD Automation] [skip ci]"
 		prd := pullRequestData{
-			pd:           data,
-			localDir:     pd.applicationDestination,
-			repository:   pd.data.ApplicationName(),
-			targetBranch: pd.applicationBranch,
-			actualBranch: customBranch,
-			message:      commitMessage,
-			title:        fmt.Sprintf("Create pipeline [Setup Ci/CD Automation] [skip ci]"),
-			merge:        true,
+			pd:              data,
+			localDir:        pd.applicationDestination,
+			repository:      pd.data.ApplicationName(),
+			targetBranch:    pd.applicationBranch,
+			actualBranch:    customBranch,
+			message:         commitMessage,
+			title:           fmt.Sprintf("Create pipeline [Setup Ci/CD Automation] [skip ci]"),
+			merge:           true,
+			ctx:             pd.ctx,
+			plan:            pd.plan,
+			templatesCommit: pd.templatesCommit,
 		}
 		if _, err := uc.makePr(prd, true); err != nil {
 			return []string{}, err
 		}
 		data.Type = "success"
 		uc.updateProgress(data, fmt.Sprintf("%s's pipeline created for %s's service", m.Code, pd.data.ApplicationSlug()))
+		uc.checkpoint(pd, step)
 	}
 
 	return extraData, nil
@@ -609,6 +858,15 @@ func (uc *setupCiCdUseCase) setupWiki(pd *processData, manifests []*entity.Manif
 	data.IsNode = false
 	var extraData []string
 	for _, v := range manifests {
+		step := fmt.Sprintf("%s/%s", data.Step, v.Code)
+		if pd.isDone(step) {
+			uc.updateProgress(data, fmt.Sprintf("%s wiki already created, skipping", v.Label))
+			continue
+		}
+		if err := pd.ctx.Err(); err != nil {
+			uc.updateProgressError(data, err, "Wiki creation interrupted")
+			return []string{}, err
+		}
 		data.Type = "progress"
 		uc.updateProgress(data, fmt.Sprintf("Creating %s wiki for %s using %s manifests", v.Label, pd.data.ApplicationName(), v.Code))
 		wiki, err := uc.Services.WikiService.LoadData(pd.data, v, pd.templatesDestinationDir)
@@ -616,14 +874,36 @@ func (uc *setupCiCdUseCase) setupWiki(pd *processData, manifests []*entity.Manif
 			uc.updateProgressError(data, err, fmt.Sprintf("Error creating wiki with %s manifests", v.Code))
 			return []string{}, err
 		}
-		output, err := uc.Services.WikiService.SetupWiki(wiki, pd.templatesDestinationDir)
+		if pd.plan != nil {
+			title, _, err := uc.Services.WikiService.RenderServicePage(wiki, pd.templatesDestinationDir)
+			if err != nil {
+				uc.updateProgressError(data, err, fmt.Sprintf("Error rendering wiki with %s manifests", v.Code))
+				return []string{}, err
+			}
+			pd.plan.addResource("%s page: %s (space %s, parent %s)", v.Label, title, wiki.Config().SpaceId, wiki.Config().ServicesPageId)
+			uc.updateProgress(data, fmt.Sprintf("Dry run: skipping %s page creation for %s", v.Label, pd.data.ApplicationName()))
+			continue
+		}
+		pageId, url, err := uc.Services.WikiService.SetupWiki(wiki, pd.templatesDestinationDir)
+		if pageId != "" {
+			pd.updateCreatedData(func(c *entity.CreatedData) {
+				c.WikiPageId = pageId
+				c.WikiUrl = url
+			})
+			uc.registerCompensation(pd.id, entity.Compensation{
+				Kind:        entity.ArchiveWikiPageCompensation,
+				Description: fmt.Sprintf("Archiving %s page of %s", v.Label, pd.data.ApplicationName()),
+				PageId:      pageId,
+			})
+		}
 		if err != nil {
 			uc.updateProgressError(data, err, fmt.Sprintf("Error creating wiki with %s manifests", v.Code))
 			return []string{}, err
 		}
-		extraData = append(extraData, output...)
+		extraData = append(extraData, url)
 		data.Type = "success"
 		uc.updateProgress(data, fmt.Sprintf("%s's wiki created for %s's service", v.Label, pd.data.ApplicationName()))
+		uc.checkpoint(pd, step)
 	}
 	return extraData, nil
 }
@@ -643,38 +923,261 @@ func (uc *setupCiCdUseCase) finish(pd *processData, additionalData []string, err
 		data.Type = "success"
 		data.Message = "Process finish with success"
 	}
+	if pd.plan != nil {
+		data.Message += " (dry run)"
+	}
+	if uc.interrupted(pd) {
+		return
+	}
+	status := entity.ProcessFinished
+	result := entity.ResultSuccess
+	if errs {
+		result = entity.ResultError
+	}
+	if errs && workers.CancelRequested(pd.ctx) {
+		status = entity.ProcessCancelled
+		result = entity.ResultCancelled
+		data.Step = "cancel-setup"
+		data.Type = "cancelled"
+		data.Message = "Process cancelled"
+	}
 	uc.updateProgress(data, "")
 	data.IsNode = false
 	for _, v := range additionalData {
 		uc.updateProgress(data, v)
 	}
+	if errs && status != entity.ProcessCancelled && uc.config.SetupCiCd.AutoRollback {
+		uc.rollback(pd.ctx, pd.id, pd.rootDestinationDir+"/rollback")
+	} else if errs {
+		if c, _ := uc.Repositories.ProgressRepository.GetCompensations(pd.id); len(c) > 0 {
+			uc.updateProgress(data, fmt.Sprintf("%d created resources can be rolled back with POST ci-cd/setup/%s/rollback", len(c), pd.id))
+		}
+	} else {
+		if err := uc.Repositories.ProgressRepository.ClearCompensations(pd.id); err != nil {
+			uc.Logger.Error("Error clearing compensations: %s", err.Error())
+		}
+		uc.checkpoint(pd, data.Step)
+	}
 	uc.updateProgress(data, "Cleaning setup state")
 	defer func() {
 		if r := recover(); r != nil {
 			uc.Logger.Error("Recovered in finish: cleaning state", r)
-			uc.markAsFinished(pd.id)
+			uc.markAsFinished(pd.id, status, result)
 		}
 	}()
-	// TODO: uncomment when directory service is implemented
-	//err := uc.Services.DirectoryService.RemoveDirectory(pd.rootDestinationDir)
-	//if err != nil {
-	//	uc.Logger.Error(err.Error())
-	//}
-	uc.updateProgress(data, "Setup state cleaned")
-	uc.markAsFinished(pd.id)
-}
-
-func (uc *setupCiCdUseCase) markAsFinished(ID string) {
-	uc.Logger.Debug("FINISHING PROCESS", ID)
-	err := uc.Repositories.ProgressRepository.MarkAsFinished(ID)
+	if uc.cleanWorkspace(pd.id, errs) {
+		uc.updateProgress(data, "Setup state cleaned")
+	} else {
+		uc.updateProgress(data, fmt.Sprintf("Setup state kept at %s", pd.rootDestinationDir))
+	}
+	if pd.plan != nil {
+		uc.updateProgress(data, pd.plan.report())
+	}
+	if pd.plan == nil {
+		pd.mutex.Lock()
+		created := *pd.data.CreatedData()
+		pd.mutex.Unlock()
+		uc.updateMetadata(pd.id, func(m *entity.ProcessMetadata) { m.CreatedData = &created })
+		if !errs {
+			uc.saveApplication(pd, created)
+		}
+	}
+	uc.markAsFinished(pd.id, status, result)
+}
+
+func (uc *setupCiCdUseCase) Rollback(ID string) error {
+	status, err := uc.Repositories.ProgressRepository.GetStatus(ID)
+	if err != nil {
+		return err
+	}
+	if !status.Done() {
+		return errors.NewInputError("id", []string{"process must be finished to be rolled back"})
+	}
+	compensations, err := uc.Repositories.ProgressRepository.GetCompensations(ID)
+	if err != nil {
+		return err
+	}
+	if len(compensations) == 0 {
+		return errors.NewInputError("id", []string{"process has nothing to roll back"})
+	}
+	metadata, err := uc.Repositories.ProgressRepository.GetMetadata(ID)
+	if err != nil {
+		return err
+	}
+	if metadata == nil {
+		return uc.enqueue(ID, entity.RollbackCiCdJob, status)
+	}
+	if err := uc.lockApplication(metadata.Application, ID); err != nil {
+		return err
+	}
+	if err := uc.enqueue(ID, entity.RollbackCiCdJob, status); err != nil {
+		uc.unlockApplication(metadata.Application, ID)
+		return err
+	}
+	return nil
+}
+
+func (uc *setupCiCdUseCase) Cancel(ID string) error {
+	status, err := uc.Repositories.ProgressRepository.GetStatus(ID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case entity.ProcessQueued:
+		removed, err := uc.Repositories.JobQueueRepository.Remove(ID)
+		if err != nil {
+			return err
+		}
+		if removed {
+			uc.notifyCancelled(ID)
+			uc.markAsFinished(ID, entity.ProcessCancelled, entity.ResultCancelled)
+			return nil
+		}
+		// A worker already took the job
+		return uc.Repositories.JobQueueRepository.RequestCancel(ID)
+	case entity.ProcessRunning:
+		return uc.Repositories.JobQueueRepository.RequestCancel(ID)
+	default:
+		return errors.NewInputError("id", []string{"process is not running"})
+	}
+}
+
+// interrupted tells if the process stopped because its job will run again,
+// it's left running with its lock and workspace for the next attempt.
+func (uc *setupCiCdUseCase) interrupted(pd *processData) bool {
+	if !workers.Interrupted(pd.ctx) {
+		return false
+	}
+	uc.updateProgress(updateProgressData{
+		ID:      pd.id,
+		Step:    "queue",
+		Message: "Process interrupted, it will be resumed by a worker",
+		Type:    "progress",
+		IsNode:  true,
+	}, "")
+	return true
+}
+
+func (uc *setupCiCdUseCase) notifyCancelled(ID string) {
+	uc.updateProgress(updateProgressData{
+		ID:      ID,
+		Step:    "cancel-setup",
+		Message: "Process cancelled",
+		Type:    "cancelled",
+		IsNode:  true,
+	}, "")
+}
+
+// rollback runs the registered compensations in reverse order. The ones that
+// fail are kept so the rollback can be requested again.
+func (uc *setupCiCdUseCase) rollback(ctx context.Context, ID, workDir string) {
+	data := updateProgressData{
+		ID:      ID,
+		Step:    "rollback-setup",
+		Message: "Rolling back created resources",
+		Type:    "progress",
+		IsNode:  true,
+	}
+	uc.updateProgress(data, "")
+	data.IsNode = false
+	compensations, err := uc.Repositories.ProgressRepository.GetCompensations(ID)
+	if err != nil {
+		uc.updateProgressError(data, err, "Error loading rollback actions")
+		return
+	}
+	var failed []entity.Compensation
+	for i := len(compensations) - 1; i >= 0; i-- {
+		c := compensations[i]
+		data.Type = "progress"
+		uc.updateProgress(data, c.Description)
+		message, err := uc.Services.CompensationService.Compensate(ctx, c, workDir)
+		if err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error %s", strings.ToLower(c.Description)))
+			failed = append([]entity.Compensation{c}, failed...)
+			continue
+		}
+		data.Type = "success"
+		if message != "" {
+			uc.updateProgress(data, fmt.Sprintf("Done: %s, %s", c.Description, message))
+			continue
+		}
+		uc.updateProgress(data, fmt.Sprintf("Done: %s", c.Description))
+	}
+	if err := uc.Repositories.ProgressRepository.ClearCompensations(ID); err != nil {
+		uc.Logger.Error("Error clearing compensations: %s", err.Error())
+	}
+	for _, c := range failed {
+		if err := uc.Repositories.ProgressRepository.SaveCompensation(ID, c); err != nil {
+			uc.Logger.Error("Error saving compensation: %s", err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		data.Type = "error"
+		uc.updateProgress(data, fmt.Sprintf("Rollback finished with %d pending actions", len(failed)))
+		return
+	}
+	if err := uc.Repositories.ProgressRepository.ClearCheckpoints(ID); err != nil {
+		uc.Logger.Error("Error clearing checkpoints: %s", err.Error())
+	}
+	data.Type = "success"
+	uc.updateProgress(data, "Rollback finished with success")
+}
+
+func (uc *setupCiCdUseCase) checkpoint(pd *processData, step string) {
+	if pd.plan != nil {
+		return
+	}
+	pd.mutex.Lock()
+	defer pd.mutex.Unlock()
+	pd.checkpoints[step] = true
+	if err := uc.Repositories.ProgressRepository.SaveCheckpoint(pd.id, step, pd.data.CreatedData()); err != nil {
+		uc.Logger.Error("Error saving checkpoint: %s", err.Error())
+	}
+}
+
+func (uc *setupCiCdUseCase) registerCompensation(ID string, c entity.Compensation) {
+	if err := uc.Repositories.ProgressRepository.SaveCompensation(ID, c); err != nil {
+		uc.Logger.Error("Error saving compensation: %s", err.Error())
+	}
+}
+
+func (uc *setupCiCdUseCase) markAsFinished(ID string, status entity.ProcessStatus, result entity.ProcessResult) {
+	uc.Logger.Debug("FINISHING PROCESS", ID, status)
+	err := uc.Repositories.ProgressRepository.SetStatus(ID, status)
 	if err != nil {
 		uc.Logger.Error("Error marking process as finish: %s", err.Error())
 	}
+	slug := ""
+	uc.updateMetadata(ID, func(m *entity.ProcessMetadata) {
+		now := time.Now()
+		m.Status = status
+		m.Result = result
+		m.FinishedAt = &now
+		slug = m.Application
+	})
+	if slug != "" {
+		uc.unlockApplication(slug, ID)
+	}
 	uc.MessageManager.Close(ID)
 	uc.Logger.Debug("PROCESSING FINISHED", ID)
 }
 
-func (uc *setupCiCdUseCase) stepClone(ID, name, repository, branch, destination, step string) error {
+func (uc *setupCiCdUseCase) updateMetadata(ID string, update func(m *entity.ProcessMetadata)) {
+	m, err := uc.Repositories.ProgressRepository.GetMetadata(ID)
+	if err != nil {
+		uc.Logger.Error("Error reading process metadata: %s", err.Error())
+		return
+	}
+	if m == nil {
+		return
+	}
+	update(m)
+	if err := uc.Repositories.ProgressRepository.SaveMetadata(*m); err != nil {
+		uc.Logger.Error("Error saving process metadata: %s", err.Error())
+	}
+}
+
+func (uc *setupCiCdUseCase) stepClone(ctx context.Context, ID, name, repository, branch, destination, step string) error {
 	data := updateProgressData{
 		ID:      ID,
 		Step:    step,
@@ -689,8 +1192,15 @@ func (uc *setupCiCdUseCase) stepClone(ID, name, repository, branch, destination,
 	}
 	data.IsNode = false
 
+	if exists, err := uc.Services.DirectoryService.DirectoryExists(destination); err == nil && exists {
+		uc.updateProgress(data, fmt.Sprintf("Removing previous %s clone from %s", repository, destination))
+		if err := uc.Services.DirectoryService.RemoveDirectory(destination); err != nil {
+			uc.updateProgressError(data, err, fmt.Sprintf("Error removing %s", destination))
+			return err
+		}
+	}
 	uc.updateProgress(data, fmt.Sprintf("Cloning %s on branch %s into %s", repository, branch, destination))
-	err := uc.Services.GitService.CloneRepository(repository, branch, destination)
+	err := uc.Services.GitService.CloneRepository(ctx, repository, branch, destination)
 	if err != nil {
 		uc.updateProgressError(data, err, fmt.Sprintf("Error cloning %s into %s", repository, destination))
 		return err
@@ -718,9 +1228,40 @@ func (uc *setupCiCdUseCase) updateProgress(data updateProgressData, customMessag
 		Kind:    data.Type,
 		Node:    data.IsNode,
 	}
+	// Saving and broadcasting together keeps the broadcast of the process in
+	// index order
+	unlock := uc.lockProgress(data.ID)
+	defer unlock()
+	index, err := uc.Repositories.ProgressRepository.SaveMessage(data.ID, entity.NewProgressEntity(update))
+	if err != nil {
+		uc.Logger.Error("Error saving progress: %s", err.Error())
+	}
+	update.Index = index
 	jsonUpdate, _ := json.Marshal(update)
 	uc.MessageManager.Broadcast(data.ID, jsonUpdate)
-	_ = uc.Repositories.ProgressRepository.SaveMessage(data.ID, entity.NewProgressEntity(update))
+}
+
+// lockProgress locks the progress of the process, the other processes aren't
+// blocked by it.
+func (uc *setupCiCdUseCase) lockProgress(id string) func() {
+	uc.progressMutex.Lock()
+	lock, ok := uc.progressLocks[id]
+	if !ok {
+		lock = &progressLock{}
+		uc.progressLocks[id] = lock
+	}
+	lock.waiting++
+	uc.progressMutex.Unlock()
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		uc.progressMutex.Lock()
+		defer uc.progressMutex.Unlock()
+		lock.waiting--
+		if lock.waiting == 0 {
+			delete(uc.progressLocks, id)
+		}
+	}
 }
 
 func (uc *setupCiCdUseCase) getManifests(pd *processData, manifestType entity.ManifestType) []*entity.Manifest {
@@ -738,20 +1279,20 @@ func (uc *setupCiCdUseCase) getManifests(pd *processData, manifestType entity.Ma
 	return m
 }
 
-func (uc *setupCiCdUseCase) newBranchFromDefault(data updateProgressData, path, defaultBranch, newBranch string) error {
+func (uc *setupCiCdUseCase) newBranchFromDefault(ctx context.Context, data updateProgressData, path, defaultBranch, newBranch string) error {
 	uc.updateProgress(data, fmt.Sprintf("Checking out default branch on %s", path))
-	err := uc.Services.GitService.Checkout(path, defaultBranch)
+	err := uc.Services.GitService.Checkout(ctx, path, defaultBranch)
 	if err != nil {
 		uc.updateProgressError(data, err, fmt.Sprintf("Error checking out default branch on %s", path))
 		return err
 	}
 	uc.updateProgress(data, fmt.Sprintf("Pulling default branch on %s", path))
-	err = uc.Services.GitService.Pull(path, defaultBranch)
+	err = uc.Services.GitService.Pull(ctx, path, defaultBranch)
 	if err != nil {
 		uc.updateProgressError(data, err, fmt.Sprintf("Error pulling default branch on %s", path))
 	}
 	uc.updateProgress(data, fmt.Sprintf("Creating %s branch on %s", newBranch, path))
-	err = uc.Services.GitService.Branch(path, newBranch)
+	err = uc.Services.GitService.Branch(ctx, path, newBranch)
 	if err != nil {
 		uc.updateProgressError(data, err, fmt.Sprintf("Error creating %s branch on %s", newBranch, path))
 		return err
@@ -768,10 +1309,17 @@ type pullRequestData struct {
 	message      string
 	title        string
 	merge        bool
+	// requireApproval tells the merged changes reach an environment requiring
+	// approval, their revert on a rollback is left for review
+	requireApproval bool
+	ctx             context.Context
+	plan            *setupPlan
+	// templatesCommit is written on the pull request description
+	templatesCommit string
 }
 
 func (uc *setupCiCdUseCase) makePr(data pullRequestData, commit bool) (string, error) {
-	hasChanges, err := uc.Services.GitService.HasChanges(data.localDir)
+	hasChanges, err := uc.Services.GitService.HasChanges(data.ctx, data.localDir)
 	if err != nil {
 		uc.updateProgressError(data.pd, err, fmt.Sprintf("Error checking changes on %s", data.localDir))
 		return "", err
@@ -782,32 +1330,80 @@ func (uc *setupCiCdUseCase) makePr(data pullRequestData, commit bool) (string, e
 	}
 	if commit {
 		uc.updateProgress(data.pd, fmt.Sprintf("Committing changes on %s", data.localDir))
-		if err := uc.Services.GitService.Commit(data.localDir, data.message); err != nil {
+		if err := uc.Services.GitService.Commit(data.ctx, data.localDir, data.message); err != nil {
 			uc.updateProgressError(data.pd, err, fmt.Sprintf("Error committing changes on %s", data.localDir))
 			return "", err
 		}
 	}
+	if data.plan != nil {
+		uc.updateProgress(data.pd, fmt.Sprintf("Dry run: collecting changes on %s", data.localDir))
+		diff, err := uc.Services.GitService.Diff(data.ctx, data.localDir, data.targetBranch, data.actualBranch)
+		if err != nil {
+			uc.updateProgressError(data.pd, err, fmt.Sprintf("Error collecting changes on %s", data.localDir))
+			return "", err
+		}
+		data.plan.addDiff(data.repository, diff)
+		return "", nil
+	}
+	commitHash, err := uc.Services.GitService.HeadCommit(data.ctx, data.localDir)
+	if err != nil {
+		uc.updateProgressError(data.pd, err, fmt.Sprintf("Error reading last commit on %s", data.localDir))
+		return "", err
+	}
 	uc.updateProgress(data.pd, fmt.Sprintf("Pushing changes on %s", data.localDir))
-	if err := uc.Services.GitService.Push(data.localDir, data.actualBranch); err != nil {
+	if err := uc.Services.GitService.Push(data.ctx, data.localDir, data.actualBranch); err != nil {
 		uc.updateProgressError(data.pd, err, fmt.Sprintf("Error pushing changes on %s", data.localDir))
 		return "", err
 	}
-	pr, err := uc.Services.GitApiService.CreatePullRequest(data.repository, data.actualBranch, data.targetBranch, data.title, data.message)
+	compensation := entity.Compensation{
+		Kind:         entity.DeleteBranchCompensation,
+		Description:  fmt.Sprintf("Deleting %s branch on %s", data.actualBranch, data.repository),
+		Repository:   data.repository,
+		Branch:       data.actualBranch,
+		TargetBranch: data.targetBranch,
+		Commit:       commitHash,
+	}
+	description := data.message
+	if data.templatesCommit != "" {
+		description += fmt.Sprintf("\n\nGenerated from %s at commit %s", uc.config.SetupCiCd.TemplatesRepository, data.templatesCommit)
+	}
+	pr, err := uc.Services.GitApiService.CreatePullRequest(data.ctx, data.repository, data.actualBranch, data.targetBranch, data.title, description)
 	if err != nil {
+		uc.registerCompensation(data.pd.ID, compensation)
 		uc.updateProgressError(data.pd, err, fmt.Sprintf("Error creating PR on %s", data.repository))
 		return "", err
 	}
+	compensation.Kind = entity.DeclinePullRequestCompensation
+	compensation.Description = fmt.Sprintf("Declining PR #%d (%s) on %s", pr.Id, data.actualBranch, data.repository)
+	compensation.PullRequestId = pr.Id
 	if data.merge {
-		err := uc.Services.GitApiService.MergePullRequest(data.repository, pr.Id)
+		mergeCommit, err := uc.Services.GitApiService.MergePullRequest(data.ctx, data.repository, pr.Id)
 		if err != nil {
+			uc.registerCompensation(data.pd.ID, compensation)
 			uc.updateProgressError(data.pd, err, fmt.Sprintf("Error merging PR on %s", data.repository))
 			return "", err
 		}
+		compensation.Kind = entity.RevertPullRequestCompensation
+		compensation.MergeCommit = mergeCommit
+		compensation.RequireApproval = data.requireApproval
+		compensation.Description = fmt.Sprintf("Reverting PR #%d (%s) on %s", pr.Id, data.actualBranch, data.repository)
+		uc.registerCompensation(data.pd.ID, compensation)
 		return "", nil
 	}
+	uc.registerCompensation(data.pd.ID, compensation)
 	return pr.Links.Html.Href, nil
 }
 
+// requireApproval tells if a change of the environments needs approval.
+func requireApproval(envs []entity.SetupEnvData) bool {
+	for _, e := range envs {
+		if e.Env().RequireApproval() {
+			return true
+		}
+	}
+	return false
+}
+
 func (uc *setupCiCdUseCase) getRepositoryVariables(variables []*entity.PipelineVariable) []*service.PipelineVariable {
 	var v []*service.PipelineVariable
 	for _, variable := range variables {
@@ -820,6 +1416,23 @@ func (uc *setupCiCdUseCase) getRepositoryVariables(variables []*entity.PipelineV
 	return v
 }
 
+// getPipelineEnvironments returns the deployment environments of the pipeline
+// with their variables, environments unknown to the pipeline are left out.
+func (uc *setupCiCdUseCase) getPipelineEnvironments(pe entity.PipelineEntity, envs []entity.SetupEnvData) []*service.PipelineEnvironment {
+	var environments []*service.PipelineEnvironment
+	for _, e := range envs {
+		env, ok := pe.Config().Environments[e.Env().Code()]
+		if !ok {
+			continue
+		}
+		environments = append(environments, &service.PipelineEnvironment{
+			Name:      env.Triggers[0].Deployment,
+			Variables: uc.getRepositoryVariables(env.Variables),
+		})
+	}
+	return environments
+}
+
 func (uc *setupCiCdUseCase) defaultManifests() ([]*entity.Manifest, error) {
 	var m []*entity.Manifest
 	r, err := uc.Repositories.ManifestRepository.ListDefault()
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+)
+
+// cleanWorkspace removes the clones of the process, a failed process keeps
+// them when configured so, until the janitor removes them. It tells if the
+// workspace was removed.
+func (uc *setupCiCdUseCase) cleanWorkspace(ID string, failed bool) bool {
+	if failed && uc.config.SetupCiCd.KeepWorkspaceOnFailure {
+		return false
+	}
+	dir := strings.Replace(uc.config.SetupCiCd.RootDestinationsPath, "{{process-id}}", ID, -1)
+	// The mirrors must forget the worktrees, their branches would be taken
+	if err := uc.Services.GitService.RemoveWorktrees(context.Background(), dir); err != nil {
+		uc.Logger.Error("Error removing process worktrees", ID, err.Error())
+	}
+	if err := uc.Services.DirectoryService.RemoveDirectory(dir); err != nil {
+		uc.Logger.Error("Error removing process workspace", ID, err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"strings"
+	"time"
+)
+
+type WorkspaceDto struct {
+	ID         string               `json:"id"`
+	Path       string               `json:"path"`
+	Size       int64                `json:"size"`
+	ModifiedAt time.Time            `json:"modifiedAt"`
+	Status     entity.ProcessStatus `json:"status,omitempty"`
+	Result     entity.ProcessResult `json:"result,omitempty"`
+}
+
+type WorkspacesOutputDto struct {
+	Path       string         `json:"path"`
+	Size       int64          `json:"size"`
+	Workspaces []WorkspaceDto `json:"workspaces"`
+}
+
+type CleanWorkspacesOutputDto struct {
+	Removed []WorkspaceDto `json:"removed"`
+	Freed   int64          `json:"freed"`
+}
+
+type WorkspacesUseCase interface {
+	// Usage lists the process workspaces on the disk of this instance.
+	Usage() (*WorkspacesOutputDto, error)
+	// Clean removes the workspaces of the finished processes, and the ones
+	// not changed for longer than the TTL that no process is using.
+	Clean() (*CleanWorkspacesOutputDto, error)
+}
+
+type workspacesUseCase struct {
+	*container.Container
+	config *config.Config
+}
+
+func NewWorkspacesUseCase(c *container.Container, cfg *config.Config) WorkspacesUseCase {
+	return &workspacesUseCase{c, cfg}
+}
+
+// root returns the directory holding the workspaces, empty when the
+// workspaces aren't directories named after the process.
+func (uc *workspacesUseCase) root() string {
+	root, found := strings.CutSuffix(uc.config.SetupCiCd.RootDestinationsPath, "/{{process-id}}")
+	if !found || strings.Contains(root, "{{process-id}}") {
+		return ""
+	}
+	return root
+}
+
+func (uc *workspacesUseCase) Usage() (*WorkspacesOutputDto, error) {
+	root := uc.root()
+	out := &WorkspacesOutputDto{Path: root, Workspaces: []WorkspaceDto{}}
+	if root == "" {
+		return out, nil
+	}
+	directories, err := uc.Services.DirectoryService.ListDirectories(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range directories {
+		size, err := uc.Services.DirectoryService.DirectorySize(d.Path)
+		if err != nil {
+			uc.Logger.Error("Error reading workspace size", d.Path, err.Error())
+		}
+		w := WorkspaceDto{ID: d.Name, Path: d.Path, Size: size, ModifiedAt: d.ModifiedAt}
+		if metadata, err := uc.Repositories.ProgressRepository.GetMetadata(d.Name); err != nil {
+			return nil, err
+		} else if metadata != nil {
+			w.Status = metadata.Status
+			w.Result = metadata.Result
+		}
+		out.Size += size
+		out.Workspaces = append(out.Workspaces, w)
+	}
+	return out, nil
+}
+
+func (uc *workspacesUseCase) Clean() (*CleanWorkspacesOutputDto, error) {
+	usage, err := uc.Usage()
+	if err != nil {
+		return nil, err
+	}
+	out := &CleanWorkspacesOutputDto{Removed: []WorkspaceDto{}}
+	for _, w := range usage.Workspaces {
+		if !uc.expired(w) {
+			continue
+		}
+		uc.Logger.Info("Removing workspace", w.Path, w.Status, w.Size)
+		if err := uc.Services.GitService.RemoveWorktrees(context.Background(), w.Path); err != nil {
+			uc.Logger.Error("Error removing workspace worktrees", w.Path, err.Error())
+		}
+		if err := uc.Services.DirectoryService.RemoveDirectory(w.Path); err != nil {
+			uc.Logger.Error("Error removing workspace", w.Path, err.Error())
+			continue
+		}
+		out.Removed = append(out.Removed, w)
+		out.Freed += w.Size
+	}
+	uc.Logger.Info("Workspaces usage", usage.Path, usage.Size-out.Freed, len(usage.Workspaces)-len(out.Removed))
+	return out, nil
+}
+
+// expired tells if the workspace can be removed. The workspaces of running
+// processes are kept, as well as the failed ones kept for debugging while
+// younger than the TTL.
+func (uc *workspacesUseCase) expired(w WorkspaceDto) bool {
+	old := time.Since(w.ModifiedAt) > uc.config.SetupCiCd.WorkspaceTTL
+	switch {
+	case w.Status == "":
+		return old
+	case !w.Status.Done():
+		return false
+	case w.Result != entity.ResultSuccess && uc.config.SetupCiCd.KeepWorkspaceOnFailure:
+		return old
+	}
+	return true
+}
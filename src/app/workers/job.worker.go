@@ -0,0 +1,248 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/infrastructure/container"
+	"sync"
+	"time"
+)
+
+// ErrCancelRequested is the cause of the cancellation of a job's context when
+// the job is cancelled. Otherwise its lease was lost or the pool is stopping,
+// the job will run again and must not be finished by the handler.
+var ErrCancelRequested = errors.New("job cancel requested")
+
+var errLeaseLost = errors.New("job lease lost")
+
+// errJobPanicked is returned by a handler that panicked, its job is abandoned
+// as running it again would likely panic again.
+var errJobPanicked = errors.New("job interrupted by internal error")
+
+// CancelRequested tells if the job of the context was cancelled on purpose.
+func CancelRequested(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrCancelRequested)
+}
+
+// Interrupted tells if the job of the context must stop without finishing, it
+// will run again on this or another worker.
+func Interrupted(ctx context.Context) bool {
+	return ctx.Err() != nil && !CancelRequested(ctx)
+}
+
+type JobHandler interface {
+	// Handle runs the job, ctx is cancelled when the job is cancelled, its
+	// lease is lost or the pool stops.
+	Handle(ctx context.Context, job entity.Job) error
+	// Waiting is called when the position of a queued job changes.
+	Waiting(job entity.Job, position int)
+	// Abandon is called when a job can't run, it was delivered more than the
+	// allowed attempts, it panicked or it has no handler, with the reason.
+	Abandon(job entity.Job, reason error)
+}
+
+type Pool struct {
+	*container.Container
+	cfg       *config.WorkersConfig
+	handlers  map[entity.JobKind]JobHandler
+	fallback  JobHandler
+	tasks     []task
+	owner     string
+	positions map[string]int
+}
+
+// task is a function run periodically by the pool, beside the jobs.
+type task struct {
+	interval time.Duration
+	run      func()
+}
+
+func NewPool(c *container.Container, cfg *config.WorkersConfig) *Pool {
+	return &Pool{
+		Container: c,
+		cfg:       cfg,
+		handlers:  make(map[entity.JobKind]JobHandler),
+		owner:     c.MessageManager.GenerateID(),
+		positions: make(map[string]int),
+	}
+}
+
+func (p *Pool) Register(kind entity.JobKind, handler JobHandler) {
+	p.handlers[kind] = handler
+}
+
+// Fallback sets the handler abandoning the jobs of the kinds without one.
+func (p *Pool) Fallback(handler JobHandler) {
+	p.fallback = handler
+}
+
+// Schedule runs f every interval once the pool is started.
+func (p *Pool) Schedule(interval time.Duration, f func()) {
+	p.tasks = append(p.tasks, task{interval, f})
+}
+
+func (p *Pool) Start(ctx context.Context) {
+	p.Logger.Info("Starting workers", p.cfg.Size)
+	for i := 0; i < p.cfg.Size; i++ {
+		go p.work(ctx)
+	}
+	go p.every(ctx, p.cfg.ReapInterval, p.reap)
+	go p.every(ctx, p.cfg.QueueReportInterval, p.report)
+	for _, t := range p.tasks {
+		go p.every(ctx, t.interval, t.run)
+	}
+}
+
+func (p *Pool) every(ctx context.Context, interval time.Duration, f func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f()
+		}
+	}
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := p.Repositories.JobQueueRepository.Dequeue(p.cfg.VisibilityTimeout)
+		if err != nil {
+			p.Logger.Error("Error dequeuing job", err.Error())
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.cfg.PollInterval):
+			}
+			continue
+		}
+		p.run(ctx, *job)
+	}
+}
+
+func (p *Pool) run(parent context.Context, job entity.Job) {
+	p.Logger.Debug("RUNNING JOB", job.ID, job.Kind, job.Attempts)
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		p.Logger.Error("No handler for job kind, dropping job", job.ID, job.Kind)
+		if p.fallback != nil {
+			p.fallback.Abandon(job, fmt.Errorf("unknown job kind %s", job.Kind))
+		}
+		p.ack(job.ID)
+		return
+	}
+	if job.Attempts > p.cfg.MaxAttempts {
+		p.Logger.Error("Job exceeded max attempts, dropping job", job.ID, job.Attempts)
+		handler.Abandon(job, fmt.Errorf("process abandoned after %d attempts", job.Attempts-1))
+		p.ack(job.ID)
+		return
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	var leaseLost bool
+	var mutex sync.Mutex
+	heartbeat := func() {
+		extended, err := p.Repositories.JobQueueRepository.Extend(job.ID, p.cfg.VisibilityTimeout)
+		if err != nil {
+			p.Logger.Error("Error extending job lease", job.ID, err.Error())
+		} else if !extended {
+			p.Logger.Error("Job lease lost, stopping job", job.ID)
+			mutex.Lock()
+			leaseLost = true
+			mutex.Unlock()
+			cancel(errLeaseLost)
+			return
+		}
+		if requested, _ := p.Repositories.JobQueueRepository.IsCancelRequested(job.ID); requested {
+			cancel(ErrCancelRequested)
+		}
+	}
+	heartbeat()
+	go p.every(ctx, p.cfg.HeartbeatInterval, heartbeat)
+
+	err := p.handle(ctx, handler, job)
+	if err != nil {
+		p.Logger.Error("Error handling job", job.ID, err.Error())
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	// A job whose lease was lost runs on another worker
+	if !leaseLost && errors.Is(err, errJobPanicked) {
+		handler.Abandon(job, err)
+		p.ack(job.ID)
+		return
+	}
+	// An interrupted job is left to the reaper, it's delivered again
+	if !leaseLost && !Interrupted(ctx) {
+		p.ack(job.ID)
+	}
+}
+
+func (p *Pool) handle(ctx context.Context, handler JobHandler, job entity.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.Logger.Error("Recovered in job", job.ID, r)
+			err = errJobPanicked
+		}
+	}()
+	return handler.Handle(ctx, job)
+}
+
+func (p *Pool) ack(ID string) {
+	if err := p.Repositories.JobQueueRepository.Ack(ID); err != nil {
+		p.Logger.Error("Error acknowledging job", ID, err.Error())
+	}
+}
+
+func (p *Pool) reap() {
+	requeued, err := p.Repositories.JobQueueRepository.RequeueExpired()
+	if err != nil {
+		p.Logger.Error("Error requeuing expired jobs", err.Error())
+	}
+	for _, ID := range requeued {
+		p.Logger.Info("Job lease expired, job requeued", ID)
+	}
+}
+
+// report notifies the queued jobs whose position changed, only one instance
+// reports at a time.
+func (p *Pool) report() {
+	claimed, err := p.Repositories.JobQueueRepository.ClaimReporter(p.owner, 2*p.cfg.QueueReportInterval)
+	if err != nil {
+		p.Logger.Error("Error claiming queue reporter", err.Error())
+		return
+	}
+	if !claimed {
+		return
+	}
+	queued, err := p.Repositories.JobQueueRepository.Queued()
+	if err != nil {
+		p.Logger.Error("Error listing queued jobs", err.Error())
+		return
+	}
+	positions := make(map[string]int)
+	for i, ID := range queued {
+		positions[ID] = i + 1
+		if p.positions[ID] == i+1 {
+			continue
+		}
+		job, err := p.Repositories.JobQueueRepository.Get(ID)
+		if err != nil || job == nil {
+			continue
+		}
+		if handler, ok := p.handlers[job.Kind]; ok {
+			handler.Waiting(*job, i+1)
+		}
+	}
+	p.positions = positions
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApplicationStatus string
+
+// ApplicationDecommissionPending is an application whose decommission waits
+// for the pull requests removing it, nothing else is deleted until they are
+// merged.
+const ApplicationDecommissionPending ApplicationStatus = "decommission-pending"
+
+// Application is the record of an application whose setup finished with
+// success, it is used to reject a second setup for the same application and
+// makes the service catalog.
+type Application struct {
+	Slug         string       `json:"slug"`
+	Name         string       `json:"name"`
+	Squad        string       `json:"squad"`
+	Template     string       `json:"template"`
+	Environments []string     `json:"environments"`
+	ProcessID    string       `json:"processId"`
+	CreatedBy    string       `json:"createdBy,omitempty"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	UpdatedAt    time.Time    `json:"updatedAt"`
+	CreatedData  *CreatedData `json:"createdData,omitempty"`
+	// Input is the setup input with the changes made after the setup, so
+	// the application can be rebuilt by the processes changing it.
+	Input json.RawMessage `json:"input,omitempty"`
+	// Imported tells the application was set up outside the portal and
+	// recorded from its manifests.
+	Imported bool `json:"imported,omitempty"`
+	// Status is only set while a process waits to finish changing the
+	// application.
+	Status ApplicationStatus `json:"status,omitempty"`
+}
+
+type ApplicationFilter struct {
+	Squad       string
+	Template    string
+	Environment string
+	Offset      int
+	Limit       int
+}
@@ -1,22 +1,29 @@
 package entity
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
 
 type EnvironmentCreatedData struct {
-	Label           string
-	Code            string
-	Url             string
-	ApplicationName string
+	Label           string `json:"label"`
+	Code            string `json:"code"`
+	Url             string `json:"url"`
+	ApplicationName string `json:"applicationName"`
 }
 
 type CreatedData struct {
-	RegistryUrl   string
-	Environments  []*EnvironmentCreatedData
-	GitOpsPath    string
-	ConfigMapPath string
+	RegistryUrl   string                    `json:"registryUrl"`
+	Environments  []*EnvironmentCreatedData `json:"environments"`
+	GitOpsPath    string                    `json:"gitOpsPath"`
+	ConfigMapPath string                    `json:"configMapPath"`
+	WikiPageId    string                    `json:"wikiPageId,omitempty"`
+	WikiUrl       string                    `json:"wikiUrl,omitempty"`
+	RepositoryUrl string                    `json:"repositoryUrl,omitempty"`
+	// TemplatesCommit is the commit of the templates repository the manifests
+	// were generated from.
+	TemplatesCommit string `json:"templatesCommit,omitempty"`
 }
 
 type SetupEnvData interface {
@@ -74,6 +81,18 @@ type SetupCiCdEntity interface {
 	IngressHost(env string) string
 	IngressPath(env string) string
 	IngressFull(env string) string
+	// InputParams are the values of the template params given to the setup.
+	InputParams() map[string]interface{}
+	// Params are the values of the template params used by the manifests
+	// shared by the environments, the default or zero value of the ones not
+	// given and of the ones limited to some environments.
+	Params() map[string]interface{}
+	// EnvParams are the values of the template params used by the manifests
+	// of the environment.
+	EnvParams(env string) map[string]interface{}
+	// PinTemplate replaces the template with its definition at the commit
+	// of the templates the setup is pinned to.
+	PinTemplate(template TemplateEntity) error
 	CreatedData() *CreatedData
 }
 
@@ -85,6 +104,7 @@ type SetupCiCdData struct {
 	Squad       SquadEntity
 	Application ApplicationData
 	Ingress     IngressData
+	Params      map[string]interface{}
 }
 
 type setupCiCdEntity struct {
@@ -96,6 +116,7 @@ type setupCiCdEntity struct {
 	squad           SquadEntity
 	application     ApplicationData
 	ingress         IngressData
+	params          map[string]interface{}
 	createdData     *CreatedData
 }
 
@@ -199,6 +220,65 @@ func (s *setupCiCdEntity) IngressFull(env string) string {
 	return s.IngressHost(env) + s.IngressPath(env)
 }
 
+// PinTemplate takes the chosen manifests from the pinned definition by their
+// code, a manifest it doesn't have can't be set up.
+func (s *setupCiCdEntity) PinTemplate(template TemplateEntity) error {
+	manifests := make([]*Manifest, 0, len(s.manifests))
+	for _, v := range s.manifests {
+		var pinned *Manifest
+		for _, m := range template.Manifests() {
+			if m.Code == v.Code {
+				pinned = m
+				break
+			}
+		}
+		if pinned == nil {
+			return fmt.Errorf("manifest %s not found on template %s", v.Code, template.Code())
+		}
+		manifests = append(manifests, pinned)
+	}
+	s.template = template
+	s.manifests = manifests
+	return nil
+}
+
+func (s *setupCiCdEntity) InputParams() map[string]interface{} {
+	return s.params
+}
+
+func (s *setupCiCdEntity) Params() map[string]interface{} {
+	return s.paramValues("")
+}
+
+func (s *setupCiCdEntity) EnvParams(env string) map[string]interface{} {
+	return s.paramValues(env)
+}
+
+// paramValues skips the invalid values, they are reported by the validation
+// of the setup. The value given to a param limited to some environments is
+// only used by the manifests of those environments.
+func (s *setupCiCdEntity) paramValues(env string) map[string]interface{} {
+	params := make(map[string]interface{})
+	if s.template == nil {
+		return params
+	}
+	for _, p := range s.template.Params() {
+		scoped := len(p.Environments) > 0 && (env == "" || !p.AppliesTo([]string{env}))
+		if v, ok := s.params[p.Name]; ok && !scoped {
+			if value, err := p.Value(v); err == nil {
+				params[p.Name] = value
+				continue
+			}
+		}
+		if p.Default != nil {
+			params[p.Name] = p.Default
+		} else {
+			params[p.Name] = p.Zero()
+		}
+	}
+	return params
+}
+
 func (s *setupCiCdEntity) CreatedData() *CreatedData {
 	return s.createdData
 }
@@ -237,6 +317,7 @@ func NewSetupCiCdEntity(options SetupCiCdData) SetupCiCdEntity {
 			CustomPath:     options.Ingress.CustomPath,
 			Authentication: options.Ingress.Authentication,
 		},
+		params: options.Params,
 		createdData: &CreatedData{
 			RegistryUrl:   "",
 			Environments:  []*EnvironmentCreatedData{},
@@ -255,10 +336,10 @@ func (s *setupCiCdEntity) replaceCommonValues(value, envCode string) string {
 	value = strings.ReplaceAll(value, "{squadName}", s.Squad().Code())
 	value = strings.ReplaceAll(value, "{{squadName}}", s.Squad().Code())
 	value = strings.ReplaceAll(value, "{{.SquadName}}", s.Squad().Code())
-	value = strings.ReplaceAll(value, "<namespace>", s.Squad().Code())
-	value = strings.ReplaceAll(value, "{namespace}", s.Squad().Code())
-	value = strings.ReplaceAll(value, "{{namespace}}", s.Squad().Code())
-	value = strings.ReplaceAll(value, "{{.Namespace}}", s.Squad().Code())
+	value = strings.ReplaceAll(value, "<namespace>", s.Squad().Namespace())
+	value = strings.ReplaceAll(value, "{namespace}", s.Squad().Namespace())
+	value = strings.ReplaceAll(value, "{{namespace}}", s.Squad().Namespace())
+	value = strings.ReplaceAll(value, "{{.Namespace}}", s.Squad().Namespace())
 	value = strings.ReplaceAll(value, "<applicationName>", s.ApplicationSlug())
 	value = strings.ReplaceAll(value, "{applicationName}", s.ApplicationSlug())
 	value = strings.ReplaceAll(value, "{{applicationName}}", s.ApplicationSlug())
@@ -0,0 +1,35 @@
+package entity
+
+type CompensationKind string
+
+const (
+	DeleteRegistryCompensation     CompensationKind = "delete-registry"
+	DeleteSecretCompensation       CompensationKind = "delete-secret"
+	DeleteBranchCompensation       CompensationKind = "delete-branch"
+	DeclinePullRequestCompensation CompensationKind = "decline-pull-request"
+	RevertPullRequestCompensation  CompensationKind = "revert-pull-request"
+	ArchiveWikiPageCompensation    CompensationKind = "archive-wiki-page"
+)
+
+// Compensation describes how to undo a resource created by a setup process.
+// Only the fields used by its Kind are filled.
+type Compensation struct {
+	Kind         CompensationKind `json:"kind"`
+	Description  string           `json:"description"`
+	Repository   string           `json:"repository,omitempty"`
+	Branch       string           `json:"branch,omitempty"`
+	TargetBranch string           `json:"targetBranch,omitempty"`
+	Commit       string           `json:"commit,omitempty"`
+	// MergeCommit is the commit of the merged pull request on TargetBranch,
+	// reverted instead of Commit when set
+	MergeCommit string `json:"mergeCommit,omitempty"`
+	// RequireApproval leaves the revert pull request open to be reviewed
+	RequireApproval bool    `json:"requireApproval,omitempty"`
+	PullRequestId   int     `json:"pullRequestId,omitempty"`
+	Location        string  `json:"location,omitempty"`
+	Path            string  `json:"path,omitempty"`
+	Name            string  `json:"name,omitempty"`
+	Region          string  `json:"region,omitempty"`
+	RegistryId      *string `json:"registryId,omitempty"`
+	PageId          string  `json:"pageId,omitempty"`
+}
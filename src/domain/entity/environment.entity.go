@@ -11,6 +11,7 @@ type EnvironmentEntity interface {
 	DestinationCluster() string
 	Project() string
 	SecretsPath() string
+	Config() *EnvironmentConfig
 }
 
 type environmentEntity struct {
@@ -26,16 +27,16 @@ type environmentEntity struct {
 }
 
 type EnvironmentConfig struct {
-	Code               string
-	Label              string
-	AccentColor        string
-	DefaultActive      bool
-	DefaultReplicas    ResourceObject
-	Concurrences       []string
-	RequireApproval    bool
-	DestinationCluster string
-	Project            string
-	SecretsPath        string
+	Code               string         `json:"code"`
+	Label              string         `json:"label"`
+	AccentColor        string         `json:"accentColor"`
+	DefaultActive      bool           `json:"defaultActive"`
+	DefaultReplicas    ResourceObject `json:"defaultReplicas"`
+	Concurrences       []string       `json:"concurrences"`
+	RequireApproval    bool           `json:"requireApproval"`
+	DestinationCluster string         `json:"destinationCluster"`
+	Project            string         `json:"project"`
+	SecretsPath        string         `json:"secretsPath"`
 }
 
 func NewEnvironmentEntity(e *EnvironmentConfig) EnvironmentEntity {
@@ -55,6 +56,22 @@ func NewEnvironmentEntity(e *EnvironmentConfig) EnvironmentEntity {
 	}
 }
 
+// Config returns the config of the environment, to store it.
+func (t *environmentEntity) Config() *EnvironmentConfig {
+	return &EnvironmentConfig{
+		Code:               t.label.Code,
+		Label:              t.label.Label,
+		AccentColor:        t.accentColor,
+		DefaultActive:      t.defaultActive,
+		DefaultReplicas:    t.defaultReplicas,
+		Concurrences:       t.concurrences,
+		RequireApproval:    t.requireApproval,
+		DestinationCluster: t.destinationCluster,
+		Project:            t.project,
+		SecretsPath:        t.secretsPath,
+	}
+}
+
 func (t *environmentEntity) Code() string {
 	return t.label.Code
 }
@@ -35,7 +35,7 @@ type gitOpsEntity struct {
 }
 
 func NewGitOpsEntity(s SetupCiCdEntity, config *GitOpsConfig, tags []*Tag) GitOpsEntity {
-	config.replace("<namespace>", s.Squad().Code())
+	config.replace("<namespace>", s.Squad().Namespace())
 	config.replace("<applicationName>", s.ApplicationSlug())
 	return &gitOpsEntity{
 		data:   s,
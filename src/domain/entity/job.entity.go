@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type JobKind string
+
+const (
+	SetupCiCdJob       JobKind = "setup-ci-cd"
+	RollbackCiCdJob    JobKind = "rollback-ci-cd"
+	DecommissionJob    JobKind = "decommission"
+	AddEnvironmentJob  JobKind = "add-environment"
+	ChangeResourcesJob JobKind = "change-resources"
+	PromoteJob         JobKind = "promote"
+)
+
+// Job is a unit of work consumed by the workers, its ID is the ID of the
+// process it runs.
+type Job struct {
+	ID         string    `json:"id"`
+	Kind       JobKind   `json:"kind"`
+	Attempts   int       `json:"attempts"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
@@ -1,8 +1,8 @@
 package entity
 
 type Manifest struct {
-	Code  string       `json:"code"`
-	Label string       `json:"label"`
-	Type  ManifestType `json:"type"`
-	Dir   string       `json:"dir,omitempty"`
+	Code  string       `json:"code" yaml:"code"`
+	Label string       `json:"label" yaml:"label"`
+	Type  ManifestType `json:"type" yaml:"type"`
+	Dir   string       `json:"dir,omitempty" yaml:"dir,omitempty"`
 }
@@ -40,7 +40,7 @@ type pipelineEntity struct {
 }
 
 func NewPipelineEntity(s SetupCiCdEntity, config *PipelineConfig, tags []*Tag) PipelineEntity {
-	config.replace("<namespace>", s.Squad().Code())
+	config.replace("<namespace>", s.Squad().Namespace())
 	config.replace("<applicationName>", s.ApplicationSlug())
 	return &pipelineEntity{
 		data:   s,
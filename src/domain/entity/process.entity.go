@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+type ProcessStatus string
+
+const (
+	ProcessQueued    ProcessStatus = "queued"
+	ProcessRunning   ProcessStatus = "running"
+	ProcessFinished  ProcessStatus = "finished"
+	ProcessCancelled ProcessStatus = "cancelled"
+)
+
+// Done reports whether the process is no longer running, either because it
+// finished or because it was cancelled.
+func (s ProcessStatus) Done() bool {
+	return s == ProcessFinished || s == ProcessCancelled
+}
+
+type ProcessResult string
+
+const (
+	ResultSuccess   ProcessResult = "success"
+	ResultError     ProcessResult = "error"
+	ResultCancelled ProcessResult = "cancelled"
+	// ResultPending is a process waiting for its pull requests to be merged,
+	// a retry finishes it once they are.
+	ResultPending ProcessResult = "pending"
+)
+
+// ProcessMetadata summarizes a process, so it can be listed without reading
+// its messages.
+type ProcessMetadata struct {
+	ID           string   `json:"id"`
+	Kind         JobKind  `json:"kind,omitempty"`
+	Squad        string   `json:"squad"`
+	Application  string   `json:"application"`
+	Template     string   `json:"template"`
+	Environments []string `json:"environments"`
+	DryRun       bool     `json:"dryRun"`
+	CreatedBy    string   `json:"createdBy,omitempty"`
+	// TemplatesRef is the ref of the templates repository asked for the
+	// process and TemplatesCommit the commit it was resolved to, a retry
+	// uses the same commit.
+	TemplatesRef    string        `json:"templatesRef,omitempty"`
+	TemplatesCommit string        `json:"templatesCommit,omitempty"`
+	Status          ProcessStatus `json:"status"`
+	Result          ProcessResult `json:"result,omitempty"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	StartedAt       *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time    `json:"finishedAt,omitempty"`
+	CreatedData     *CreatedData  `json:"createdData,omitempty"`
+}
+
+type ProcessFilter struct {
+	Squad       string
+	Application string
+	// Status matches either the status or the result of the process.
+	Status string
+	Offset int
+	Limit  int
+}
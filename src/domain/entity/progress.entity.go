@@ -3,6 +3,7 @@ package entity
 import "time"
 
 type ProgressEntity interface {
+	Index() int64
 	Time() time.Time
 	Step() string
 	Message() string
@@ -12,6 +13,7 @@ type ProgressEntity interface {
 }
 
 type progressEntity struct {
+	index   int64
 	time    time.Time
 	step    string
 	message string
@@ -20,6 +22,8 @@ type progressEntity struct {
 }
 
 type Progress struct {
+	// Index is the position of the message in the process, starting at 1.
+	Index   int64     `json:"index"`
 	Time    time.Time `json:"time"`
 	Step    string    `json:"step"`
 	Message string    `json:"message"`
@@ -29,6 +33,7 @@ type Progress struct {
 
 func NewProgressEntity(progress Progress) ProgressEntity {
 	return &progressEntity{
+		index:   progress.Index,
 		time:    progress.Time,
 		step:    progress.Step,
 		message: progress.Message,
@@ -37,6 +42,10 @@ func NewProgressEntity(progress Progress) ProgressEntity {
 	}
 }
 
+func (t *progressEntity) Index() int64 {
+	return t.index
+}
+
 func (t *progressEntity) Time() time.Time {
 	return t.time
 }
@@ -59,6 +68,7 @@ func (t *progressEntity) IsNode() bool {
 
 func (t *progressEntity) ToStruct() Progress {
 	return Progress{
+		Index:   t.index,
 		Time:    t.time,
 		Step:    t.step,
 		Message: t.message,
@@ -20,7 +20,7 @@ type secretEntity struct {
 }
 
 func NewSecretEntity(s SetupCiCdEntity, config *SecretConfig, tags []*Tag) SecretEntity {
-	config.replace("<namespace>", s.Squad().Code())
+	config.replace("<namespace>", s.Squad().Namespace())
 	config.replace("<applicationName>", s.ApplicationSlug())
 	return &secretEntity{
 		config: config,
@@ -3,18 +3,59 @@ package entity
 type SquadEntity interface {
 	Code() string
 	Label() string
+	Owners() []string
+	SlackChannel() string
+	CostCenter() string
+	DefaultReviewers() []string
+	// Namespace is the k8s namespace of the squad's applications, the code of
+	// the squad unless overridden.
+	Namespace() string
+	Config() *SquadConfig
 }
 
 type squadEntity struct {
-	label DataLabelObject
+	label            DataLabelObject
+	owners           []string
+	slackChannel     string
+	costCenter       string
+	defaultReviewers []string
+	namespace        string
 }
 
-func NewSquadEntity(code string, label string) SquadEntity {
+type SquadConfig struct {
+	Code             string   `json:"code"`
+	Label            string   `json:"label"`
+	Owners           []string `json:"owners"`
+	SlackChannel     string   `json:"slackChannel"`
+	CostCenter       string   `json:"costCenter"`
+	DefaultReviewers []string `json:"defaultReviewers"`
+	Namespace        string   `json:"namespace"`
+}
+
+func NewSquadEntity(config *SquadConfig) SquadEntity {
 	return &squadEntity{
-		DataLabelObject{
-			Code:  code,
-			Label: label,
+		label: DataLabelObject{
+			Code:  config.Code,
+			Label: config.Label,
 		},
+		owners:           config.Owners,
+		slackChannel:     config.SlackChannel,
+		costCenter:       config.CostCenter,
+		defaultReviewers: config.DefaultReviewers,
+		namespace:        config.Namespace,
+	}
+}
+
+// Config returns the config of the squad, to store it.
+func (t *squadEntity) Config() *SquadConfig {
+	return &SquadConfig{
+		Code:             t.label.Code,
+		Label:            t.label.Label,
+		Owners:           t.owners,
+		SlackChannel:     t.slackChannel,
+		CostCenter:       t.costCenter,
+		DefaultReviewers: t.defaultReviewers,
+		Namespace:        t.namespace,
 	}
 }
 
@@ -25,3 +66,26 @@ func (t *squadEntity) Code() string {
 func (t *squadEntity) Label() string {
 	return t.label.Label
 }
+
+func (t *squadEntity) Owners() []string {
+	return t.owners
+}
+
+func (t *squadEntity) SlackChannel() string {
+	return t.slackChannel
+}
+
+func (t *squadEntity) CostCenter() string {
+	return t.costCenter
+}
+
+func (t *squadEntity) DefaultReviewers() []string {
+	return t.defaultReviewers
+}
+
+func (t *squadEntity) Namespace() string {
+	if t.namespace != "" {
+		return t.namespace
+	}
+	return t.label.Code
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "strings"
+
 type Tag struct {
 	Key   *string `json:"key" yaml:"key"`
 	Value *string `json:"value" yaml:"value"`
@@ -13,10 +15,22 @@ func NewTag(key string, value string) *Tag {
 }
 
 func DefaultTags(e SetupCiCdEntity) []*Tag {
-	return []*Tag{
+	tags := []*Tag{
 		NewTag("app", e.ApplicationName()),
 		NewTag("squad", e.Squad().Code()),
 		NewTag("cloud", "true"),
 		NewTag("automated-setup", "true"),
 	}
+	// Only the metadata set on the squad is tagged, tag values can't be empty
+	// on every provider
+	if e.Squad().CostCenter() != "" {
+		tags = append(tags, NewTag("cost-center", e.Squad().CostCenter()))
+	}
+	if len(e.Squad().Owners()) > 0 {
+		tags = append(tags, NewTag("owners", strings.Join(e.Squad().Owners(), " ")))
+	}
+	if e.Squad().SlackChannel() != "" {
+		tags = append(tags, NewTag("slack-channel", e.Squad().SlackChannel()))
+	}
+	return tags
 }
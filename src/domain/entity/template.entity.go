@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"regexp"
+	"strings"
+)
+
 type ManifestType string
 
 const (
@@ -11,22 +16,42 @@ const (
 )
 
 type ApplicationObject struct {
-	RootPath                       PathObject     `json:"rootPath"`
-	HealthCheckPath                PathObject     `json:"healthCheckPath"`
-	Port                           int            `json:"port"`
-	Memory                         ResourceObject `json:"memory"`
-	Cpu                            ResourceObject `json:"cpu"`
-	HealthCheckInitialDelaySeconds int            `json:"healthCheckInitialDelaySeconds"` // readiness probe
-	HealthCheckSecondDelaySeconds  int            `json:"healthCheckSecondDelaySeconds"`  // liveness probe
-	HealthCheckPeriodSeconds       int            `json:"healthCheckPeriodSeconds"`       // both
+	RootPath                       PathObject     `json:"rootPath" yaml:"rootPath"`
+	HealthCheckPath                PathObject     `json:"healthCheckPath" yaml:"healthCheckPath"`
+	Port                           int            `json:"port" yaml:"port"`
+	Memory                         ResourceObject `json:"memory" yaml:"memory"`
+	Cpu                            ResourceObject `json:"cpu" yaml:"cpu"`
+	HealthCheckInitialDelaySeconds int            `json:"healthCheckInitialDelaySeconds" yaml:"healthCheckInitialDelaySeconds"` // readiness probe
+	HealthCheckSecondDelaySeconds  int            `json:"healthCheckSecondDelaySeconds" yaml:"healthCheckSecondDelaySeconds"`   // liveness probe
+	HealthCheckPeriodSeconds       int            `json:"healthCheckPeriodSeconds" yaml:"healthCheckPeriodSeconds"`             // both
 }
 
 type IngressObject struct {
-	Enabled        bool       `json:"enabled"`
-	Host           PathObject `json:"host"`
-	Path           PathObject `json:"path"`
-	Authentication bool       `json:"authentication"`
-	Frontend       bool       `json:"frontend"`
+	Enabled        bool       `json:"enabled" yaml:"enabled"`
+	Host           PathObject `json:"host" yaml:"host"`
+	Path           PathObject `json:"path" yaml:"path"`
+	Authentication bool       `json:"authentication" yaml:"authentication"`
+	Frontend       bool       `json:"frontend" yaml:"frontend"`
+}
+
+var (
+	templatesRef    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+	templatesCommit = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// ValidTemplatesRef tells if the ref is a tag or commit name, which can't be
+// taken as an option by git.
+func ValidTemplatesRef(ref string) bool {
+	return templatesRef.MatchString(ref) && !strings.Contains(ref, "..")
+}
+
+// TemplatesRevision is the revision the templates ref is checked out at, a
+// ref that isn't a commit is taken as a tag, so a branch can't be pinned.
+func TemplatesRevision(ref string) string {
+	if templatesCommit.MatchString(ref) {
+		return ref
+	}
+	return "refs/tags/" + ref
 }
 
 type TemplateEntity interface {
@@ -35,6 +60,11 @@ type TemplateEntity interface {
 	ApplicationDefault() ApplicationObject
 	IngressDefault() IngressObject
 	Manifests() []*Manifest
+	// TemplatesRef is the tag or commit of the templates repository the
+	// template is pinned to, empty to use the templates branch.
+	TemplatesRef() string
+	// Params are the inputs the template declares for its manifests.
+	Params() []*TemplateParam
 }
 
 type templateEntity struct {
@@ -43,6 +73,8 @@ type templateEntity struct {
 	applicationDefaults ApplicationObject
 	ingressDefaults     IngressObject
 	manifests           []*Manifest
+	templatesRef        string
+	params              []*TemplateParam
 }
 
 func NewTemplateEntity(
@@ -51,6 +83,8 @@ func NewTemplateEntity(
 	applicationDefault ApplicationObject,
 	ingressDefault IngressObject,
 	manifests []*Manifest,
+	templatesRef string,
+	params []*TemplateParam,
 ) TemplateEntity {
 	return &templateEntity{
 		code,
@@ -58,6 +92,8 @@ func NewTemplateEntity(
 		applicationDefault,
 		ingressDefault,
 		manifests,
+		templatesRef,
+		params,
 	}
 }
 
@@ -80,3 +116,11 @@ func (t *templateEntity) IngressDefault() IngressObject {
 func (t *templateEntity) Manifests() []*Manifest {
 	return t.manifests
 }
+
+func (t *templateEntity) TemplatesRef() string {
+	return t.templatesRef
+}
+
+func (t *templateEntity) Params() []*TemplateParam {
+	return t.params
+}
@@ -0,0 +1,164 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+type TemplateParamType string
+
+const (
+	StringParam  TemplateParamType = "string"
+	IntegerParam TemplateParamType = "integer"
+	NumberParam  TemplateParamType = "number"
+	BooleanParam TemplateParamType = "boolean"
+)
+
+var templateParamName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// TemplateParam is an input of the setup declared by the template, besides
+// the application and ingress ones. Its value is given to the manifests as
+// .Params.<name>.
+type TemplateParam struct {
+	Name        string            `json:"name" yaml:"name"`
+	Label       string            `json:"label,omitempty" yaml:"label"`
+	Description string            `json:"description,omitempty" yaml:"description"`
+	Type        TemplateParamType `json:"type" yaml:"type"`
+	Required    bool              `json:"required" yaml:"required"`
+	Default     interface{}       `json:"default,omitempty" yaml:"default"`
+	Enum        []interface{}     `json:"enum,omitempty" yaml:"enum"`
+	// Pattern is the regex a string value must match
+	Pattern string `json:"pattern,omitempty" yaml:"pattern"`
+	// Environments limits the param to the setups with one of them, it's used
+	// by every setup when empty.
+	Environments []string `json:"environments,omitempty" yaml:"environments"`
+}
+
+// Validate checks the declaration of the param, its default and enum values
+// must be valid values of it.
+func (p *TemplateParam) Validate() error {
+	if !templateParamName.MatchString(p.Name) {
+		return fmt.Errorf("param name %s must start with a letter and have only letters, numbers and underscores", p.Name)
+	}
+	switch p.Type {
+	case StringParam, IntegerParam, NumberParam, BooleanParam:
+	default:
+		return fmt.Errorf("param %s has an unknown type %s", p.Name, p.Type)
+	}
+	if p.Pattern != "" && p.Type != StringParam {
+		return fmt.Errorf("param %s has a pattern but it's not a string", p.Name)
+	}
+	if _, err := regexp.Compile(p.Pattern); err != nil {
+		return fmt.Errorf("param %s has an invalid pattern: %w", p.Name, err)
+	}
+	for k, v := range p.Enum {
+		value, err := p.value(v)
+		if err != nil {
+			return fmt.Errorf("param %s has an invalid enum value: %w", p.Name, err)
+		}
+		p.Enum[k] = value
+	}
+	if p.Default != nil {
+		value, err := p.Value(p.Default)
+		if err != nil {
+			return fmt.Errorf("param %s has an invalid default: %w", p.Name, err)
+		}
+		p.Default = value
+	}
+	return nil
+}
+
+// AppliesTo tells if the param is used by a setup with the environments.
+func (p *TemplateParam) AppliesTo(environments []string) bool {
+	if len(p.Environments) == 0 {
+		return true
+	}
+	for _, e := range p.Environments {
+		for _, v := range environments {
+			if e == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Value checks a value of the param, either decoded from json or yaml, and
+// returns it as a string, int64, float64 or bool.
+func (p *TemplateParam) Value(v interface{}) (interface{}, error) {
+	value, err := p.value(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Enum) > 0 {
+		found := false
+		for _, e := range p.Enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("value must be one of %v", p.Enum)
+		}
+	}
+	if s, ok := value.(string); ok && p.Pattern != "" && !regexp.MustCompile(p.Pattern).MatchString(s) {
+		return nil, fmt.Errorf("value must match %s", p.Pattern)
+	}
+	return value, nil
+}
+
+// Zero is the value of an optional param without default.
+func (p *TemplateParam) Zero() interface{} {
+	switch p.Type {
+	case IntegerParam:
+		return int64(0)
+	case NumberParam:
+		return float64(0)
+	case BooleanParam:
+		return false
+	}
+	return ""
+}
+
+func (p *TemplateParam) value(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case StringParam:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return nil, errors.New("value must be a string")
+	case BooleanParam:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, errors.New("value must be a boolean")
+	case IntegerParam:
+		if n, ok := number(v); ok && n == math.Trunc(n) {
+			return int64(n), nil
+		}
+		return nil, errors.New("value must be an integer")
+	case NumberParam:
+		if n, ok := number(v); ok {
+			return n, nil
+		}
+		return nil, errors.New("value must be a number")
+	}
+	return nil, fmt.Errorf("unknown type %s", p.Type)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package entity
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateParamValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		param TemplateParam
+		err   string
+		// defaultValue and enum are the values converted by the validation
+		defaultValue interface{}
+		enum         []interface{}
+	}{
+		{
+			name:         "string with default and pattern",
+			param:        TemplateParam{Name: "javaVersion", Type: StringParam, Default: "17", Pattern: "^[0-9]+$"},
+			defaultValue: "17",
+		},
+		{
+			name:         "integer decoded from json",
+			param:        TemplateParam{Name: "workers", Type: IntegerParam, Default: float64(2), Enum: []interface{}{float64(1), 2}},
+			defaultValue: int64(2),
+			enum:         []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:  "invalid name",
+			param: TemplateParam{Name: "java-version", Type: StringParam},
+			err:   "must start with a letter",
+		},
+		{
+			name:  "unknown type",
+			param: TemplateParam{Name: "javaVersion", Type: "list"},
+			err:   "unknown type list",
+		},
+		{
+			name:  "pattern of a number",
+			param: TemplateParam{Name: "workers", Type: IntegerParam, Pattern: "^[0-9]+$"},
+			err:   "has a pattern but it's not a string",
+		},
+		{
+			name:  "invalid pattern",
+			param: TemplateParam{Name: "javaVersion", Type: StringParam, Pattern: "("},
+			err:   "invalid pattern",
+		},
+		{
+			name:  "invalid enum value",
+			param: TemplateParam{Name: "debug", Type: BooleanParam, Enum: []interface{}{"yes"}},
+			err:   "invalid enum value",
+		},
+		{
+			name:  "default out of the enum",
+			param: TemplateParam{Name: "javaVersion", Type: StringParam, Default: "11", Enum: []interface{}{"17", "21"}},
+			err:   "invalid default",
+		},
+		{
+			name:  "default not matching the pattern",
+			param: TemplateParam{Name: "javaVersion", Type: StringParam, Default: "latest", Pattern: "^[0-9]+$"},
+			err:   "invalid default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.param.Validate()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.param.Default != tt.defaultValue {
+				t.Errorf("expected default %#v, got %#v", tt.defaultValue, tt.param.Default)
+			}
+			if tt.enum != nil && !reflect.DeepEqual(tt.param.Enum, tt.enum) {
+				t.Errorf("expected enum %#v, got %#v", tt.enum, tt.param.Enum)
+			}
+		})
+	}
+}
+
+func TestTemplateParamValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    TemplateParam
+		value    interface{}
+		expected interface{}
+		err      string
+	}{
+		{"string", TemplateParam{Type: StringParam}, "17", "17", ""},
+		{"string of another type", TemplateParam{Type: StringParam}, 17, nil, "must be a string"},
+		{"boolean", TemplateParam{Type: BooleanParam}, true, true, ""},
+		{"boolean as a string", TemplateParam{Type: BooleanParam}, "true", nil, "must be a boolean"},
+		{"integer from json", TemplateParam{Type: IntegerParam}, float64(3), int64(3), ""},
+		{"integer from yaml", TemplateParam{Type: IntegerParam}, 3, int64(3), ""},
+		{"integer with decimals", TemplateParam{Type: IntegerParam}, 3.5, nil, "must be an integer"},
+		{"number", TemplateParam{Type: NumberParam}, 3, float64(3), ""},
+		{"number as a string", TemplateParam{Type: NumberParam}, "3", nil, "must be a number"},
+		{"enum value", TemplateParam{Type: StringParam, Enum: []interface{}{"17", "21"}}, "21", "21", ""},
+		{"value out of the enum", TemplateParam{Type: StringParam, Enum: []interface{}{"17", "21"}}, "11", nil, "must be one of"},
+		{"integer enum from json", TemplateParam{Type: IntegerParam, Enum: []interface{}{int64(1), int64(2)}}, float64(2), int64(2), ""},
+		{"pattern", TemplateParam{Type: StringParam, Pattern: "^[a-z]+$"}, "abc", "abc", ""},
+		{"value not matching the pattern", TemplateParam{Type: StringParam, Pattern: "^[a-z]+$"}, "ABC", nil, "must match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.param.Value(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Fatalf("expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestSetupCiCdEntityParams(t *testing.T) {
+	template := NewTemplateEntity("api", "API", ApplicationObject{}, IngressObject{}, nil, "", []*TemplateParam{
+		{Name: "javaVersion", Type: StringParam, Default: "17"},
+		{Name: "workers", Type: IntegerParam},
+		{Name: "replicas", Type: IntegerParam, Default: int64(1), Environments: []string{"prd"}},
+		{Name: "debug", Type: BooleanParam, Environments: []string{"dev"}},
+	})
+	setup := NewSetupCiCdEntity(SetupCiCdData{
+		Template: template,
+		Envs:     []SetupEnvData{NewSetupEnvData(NewEnvironmentEntity(&EnvironmentConfig{Code: "dev"}), 1, 1)},
+		Params:   map[string]interface{}{"workers": float64(4), "replicas": float64(3), "debug": true},
+	})
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "shared by the environments",
+			params:   setup.Params(),
+			expected: map[string]interface{}{"javaVersion": "17", "workers": int64(4), "replicas": int64(1), "debug": false},
+		},
+		{
+			name:     "of an environment",
+			params:   setup.EnvParams("dev"),
+			expected: map[string]interface{}{"javaVersion": "17", "workers": int64(4), "replicas": int64(1), "debug": true},
+		},
+		{
+			name:     "of another environment",
+			params:   setup.EnvParams("prd"),
+			expected: map[string]interface{}{"javaVersion": "17", "workers": int64(4), "replicas": int64(3), "debug": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.params, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, tt.params)
+			}
+		})
+	}
+}
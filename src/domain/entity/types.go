@@ -6,21 +6,21 @@ type DataLabelObject struct {
 }
 
 type NumberValueObject struct {
-	Value float32 `json:"value"`
-	Step  float32 `json:"step"`
-	Min   float32 `json:"min"`
-	Max   float32 `json:"max"`
+	Value float32 `json:"value" yaml:"value"`
+	Step  float32 `json:"step" yaml:"step"`
+	Min   float32 `json:"min" yaml:"min"`
+	Max   float32 `json:"max" yaml:"max"`
 }
 
 type ResourceObject struct {
-	Min NumberValueObject `json:"min"`
-	Max NumberValueObject `json:"max"`
+	Min NumberValueObject `json:"min" yaml:"min"`
+	Max NumberValueObject `json:"max" yaml:"max"`
 }
 
 type PathObject struct {
-	Default      string `json:"default"`
-	Fixed        string `json:"fixed"`
-	Customizable bool   `json:"customizable"`
+	Default      string `json:"default" yaml:"default"`
+	Fixed        string `json:"fixed" yaml:"fixed"`
+	Customizable bool   `json:"customizable" yaml:"customizable"`
 }
 
 type LimitsIntData struct {
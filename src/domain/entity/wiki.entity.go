@@ -6,6 +6,9 @@ type WikiConfig struct {
 	SpaceId             string `json:"spaceId" yaml:"spaceId"`
 	ServicesPageId      string `json:"servicesPageId" yaml:"servicesPageId"`
 	ServicesPageTitle   string `json:"servicesPageTitle" yaml:"servicesPageTitle"`
+	// ArchivePageId is the parent of the pages of decommissioned services,
+	// when empty the pages are archived instead.
+	ArchivePageId string `json:"archivePageId" yaml:"archivePageId"`
 }
 
 type WikiEntity interface {
@@ -0,0 +1,20 @@
+package repository
+
+import "github.com/zahirsis/dev-portal-backend/src/domain/entity"
+
+type ApplicationRepository interface {
+	// Lock reserves the application for the process and returns the process
+	// holding the lock, which is not the given one when it was already locked.
+	Lock(slug string, processID string) (string, error)
+	// ReplaceLock moves the lock from holder to the process, it returns false
+	// when the lock is no longer held by holder.
+	ReplaceLock(slug string, holder string, processID string) (bool, error)
+	// Unlock releases the lock only when it is held by the process.
+	Unlock(slug string, processID string) error
+	Save(application entity.Application) error
+	Get(slug string) (*entity.Application, error)
+	// List returns the applications matching the filter, sorted by slug,
+	// and the total of applications matching it.
+	List(filter entity.ApplicationFilter) ([]entity.Application, int, error)
+	Delete(slug string) error
+}
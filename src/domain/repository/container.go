@@ -6,4 +6,6 @@ type Container struct {
 	SquadRepository       SquadRepository
 	ProgressRepository    ProcessRepository
 	ManifestRepository    ManifestRepository
+	JobQueueRepository    JobQueueRepository
+	ApplicationRepository ApplicationRepository
 }
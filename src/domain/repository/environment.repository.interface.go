@@ -7,4 +7,7 @@ import (
 type EnvironmentRepository interface {
 	List() ([]entity.EnvironmentEntity, error)
 	Get(code string) (entity.EnvironmentEntity, error)
+	// Save creates the environment or replaces the one with its code.
+	Save(environment entity.EnvironmentEntity) error
+	Delete(code string) error
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"time"
+)
+
+type JobQueueRepository interface {
+	Enqueue(job entity.Job) error
+	Get(ID string) (*entity.Job, error)
+	// Dequeue takes the next job, if any, and leases it for the given time.
+	Dequeue(lease time.Duration) (*entity.Job, error)
+	// Extend renews the lease of a job, it returns false when the lease was lost.
+	Extend(ID string, lease time.Duration) (bool, error)
+	Ack(ID string) error
+	// RequeueExpired puts back in the queue the jobs whose lease expired.
+	RequeueExpired() ([]string, error)
+	Queued() ([]string, error)
+	Position(ID string) (int, error)
+	Remove(ID string) (bool, error)
+	RequestCancel(ID string) error
+	IsCancelRequested(ID string) (bool, error)
+	// ClaimReporter elects the instance reporting the queue positions.
+	ClaimReporter(owner string, ttl time.Duration) (bool, error)
+}
@@ -6,7 +6,23 @@ import (
 
 type ProcessRepository interface {
 	GetMessages(ID string) ([]entity.ProgressEntity, error)
-	SaveMessage(ID string, message entity.ProgressEntity) error
+	// GetMessagesAfter returns the messages whose index is greater than after.
+	GetMessagesAfter(ID string, after int64) ([]entity.ProgressEntity, error)
+	// SaveMessage stores the message and returns its index.
+	SaveMessage(ID string, message entity.ProgressEntity) (int64, error)
 	MarkAsFinished(ID string) error
 	IsFinished(ID string) (bool, error)
+	SetStatus(ID string, status entity.ProcessStatus) error
+	GetStatus(ID string) (entity.ProcessStatus, error)
+	SaveCompensation(ID string, compensation entity.Compensation) error
+	GetCompensations(ID string) ([]entity.Compensation, error)
+	ClearCompensations(ID string) error
+	SaveInput(ID string, input []byte) error
+	GetInput(ID string) ([]byte, error)
+	SaveCheckpoint(ID string, step string, data *entity.CreatedData) error
+	GetCheckpoints(ID string) ([]string, *entity.CreatedData, error)
+	ClearCheckpoints(ID string) error
+	SaveMetadata(metadata entity.ProcessMetadata) error
+	GetMetadata(ID string) (*entity.ProcessMetadata, error)
+	ListMetadata(filter entity.ProcessFilter) ([]entity.ProcessMetadata, int, error)
 }
@@ -7,4 +7,7 @@ import (
 type SquadRepository interface {
 	List() ([]entity.SquadEntity, error)
 	Get(code string) (entity.SquadEntity, error)
+	// Save creates the squad or replaces the one with its code.
+	Save(squad entity.SquadEntity) error
+	Delete(code string) error
 }
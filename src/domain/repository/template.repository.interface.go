@@ -7,4 +7,9 @@ import (
 type TemplateRepository interface {
 	List() ([]entity.TemplateEntity, error)
 	Get(code string) (entity.TemplateEntity, error)
+	// Read reads the template from a checkout of the templates repository,
+	// as it's defined at the commit checked out.
+	Read(code string, dir string) (entity.TemplateEntity, error)
+	// Refresh loads the templates again from their source.
+	Refresh() error
 }
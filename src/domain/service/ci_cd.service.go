@@ -6,6 +6,7 @@ import (
 	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
 	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
 	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"sort"
 )
 
 type CiCdService interface {
@@ -45,6 +46,7 @@ func (c *ciCdService) ValidateSetup(setup entity.SetupCiCdEntity) []error {
 	errs = append(errs, c.checkResources(setup)...)
 	errs = append(errs, c.CheckApplication(setup)...)
 	errs = append(errs, c.checkIngress(setup)...)
+	errs = append(errs, c.checkParams(setup)...)
 	return errs
 }
 
@@ -191,6 +193,42 @@ func (c *ciCdService) checkIngress(setup entity.SetupCiCdEntity) []error {
 	return errs
 }
 
+func (c *ciCdService) checkParams(setup entity.SetupCiCdEntity) []error {
+	var errs []error
+	var envs []string
+	for _, env := range setup.Envs() {
+		envs = append(envs, env.Env().Code())
+	}
+	declared := make(map[string]bool)
+	for _, p := range setup.Template().Params() {
+		declared[p.Name] = true
+		if !p.AppliesTo(envs) {
+			continue
+		}
+		v, ok := setup.InputParams()[p.Name]
+		if !ok || v == nil {
+			if p.Required && p.Default == nil {
+				errs = append(errs, errors.NewInputError("params."+p.Name, []string{p.Name + " cannot be empty"}))
+			}
+			continue
+		}
+		if _, err := p.Value(v); err != nil {
+			errs = append(errs, errors.NewInputError("params."+p.Name, []string{err.Error()}))
+		}
+	}
+	var unknown []string
+	for name := range setup.InputParams() {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, errors.NewInputError("params."+name, []string{"template does not have this param"}))
+	}
+	return errs
+}
+
 func formatCpu(cpu float32) string {
 	if cpu < 1 {
 		return fmt.Sprintf("%dm", int(cpu*1000))
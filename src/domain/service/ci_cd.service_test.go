@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/errors"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"reflect"
+	"testing"
+)
+
+func TestCheckParams(t *testing.T) {
+	params := []*entity.TemplateParam{
+		{Name: "javaVersion", Type: entity.StringParam, Required: true, Enum: []interface{}{"17", "21"}},
+		{Name: "workers", Type: entity.IntegerParam, Required: true, Default: int64(2)},
+		{Name: "debug", Type: entity.BooleanParam},
+		{Name: "certificate", Type: entity.StringParam, Required: true, Environments: []string{"prd"}},
+	}
+	tests := []struct {
+		name     string
+		envs     []string
+		params   map[string]interface{}
+		expected []error
+	}{
+		{
+			name:   "valid params",
+			envs:   []string{"dev"},
+			params: map[string]interface{}{"javaVersion": "21", "debug": true},
+		},
+		{
+			name:     "required param without default",
+			envs:     []string{"dev"},
+			params:   map[string]interface{}{"debug": nil},
+			expected: []error{errors.NewInputError("params.javaVersion", []string{"javaVersion cannot be empty"})},
+		},
+		{
+			name:   "invalid values",
+			envs:   []string{"dev"},
+			params: map[string]interface{}{"javaVersion": "11", "workers": 1.5},
+			expected: []error{
+				errors.NewInputError("params.javaVersion", []string{"value must be one of [17 21]"}),
+				errors.NewInputError("params.workers", []string{"value must be an integer"}),
+			},
+		},
+		{
+			name:     "required param of an environment of the setup",
+			envs:     []string{"dev", "prd"},
+			params:   map[string]interface{}{"javaVersion": "17"},
+			expected: []error{errors.NewInputError("params.certificate", []string{"certificate cannot be empty"})},
+		},
+		{
+			name:   "unknown params",
+			envs:   []string{"dev"},
+			params: map[string]interface{}{"javaVersion": "17", "version": "1", "image": "api"},
+			expected: []error{
+				errors.NewInputError("params.image", []string{"template does not have this param"}),
+				errors.NewInputError("params.version", []string{"template does not have this param"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var envs []entity.SetupEnvData
+			for _, code := range tt.envs {
+				envs = append(envs, testEnv(code, 1, 1))
+			}
+			setup := entity.NewSetupCiCdEntity(entity.SetupCiCdData{
+				Template: entity.NewTemplateEntity("api", "API", entity.ApplicationObject{}, entity.IngressObject{}, nil, "", params),
+				Envs:     envs,
+				Params:   tt.params,
+			})
+			errs := (&ciCdService{}).checkParams(setup)
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+)
+
+type CompensationService interface {
+	// Compensate undoes the resource, the message tells what is left to be
+	// done by someone, as a revert pull request waiting for approval.
+	Compensate(ctx context.Context, c entity.Compensation, workDir string) (message string, err error)
+}
+
+type compensationService struct {
+	logger           logger.Logger
+	git              GitService
+	gitApi           GitApiService
+	registryApi      RegistryApiService
+	secretApi        SecretApiService
+	wikiApi          WikiApiService
+	directoryService DirectoryService
+}
+
+func NewCompensationService(
+	logger logger.Logger,
+	git GitService,
+	gitApi GitApiService,
+	registryApi RegistryApiService,
+	secretApi SecretApiService,
+	wikiApi WikiApiService,
+	directoryService DirectoryService,
+) CompensationService {
+	return &compensationService{
+		logger:           logger,
+		git:              git,
+		gitApi:           gitApi,
+		registryApi:      registryApi,
+		secretApi:        secretApi,
+		wikiApi:          wikiApi,
+		directoryService: directoryService,
+	}
+}
+
+func (s *compensationService) Compensate(ctx context.Context, c entity.Compensation, workDir string) (string, error) {
+	s.logger.Debug("Compensating", c)
+	switch c.Kind {
+	case entity.DeleteRegistryCompensation:
+		config := &entity.RegistryConfig{Region: c.Region, RegistryId: c.RegistryId}
+		return "", s.registryApi.Delete(ctx, entity.NewRegistryEntity(c.Name, "", config, nil))
+	case entity.DeleteSecretCompensation:
+		// The secret is soft deleted, a rollback by mistake can be undone
+		return "", s.secretApi.SoftDelete(c.Location, c.Path)
+	case entity.DeleteBranchCompensation:
+		return "", s.gitApi.DeleteBranch(ctx, c.Repository, c.Branch)
+	case entity.DeclinePullRequestCompensation:
+		if err := s.gitApi.DeclinePullRequest(ctx, c.Repository, c.PullRequestId); err != nil {
+			return "", err
+		}
+		return "", s.gitApi.DeleteBranch(ctx, c.Repository, c.Branch)
+	case entity.RevertPullRequestCompensation:
+		return s.revertPullRequest(ctx, c, workDir)
+	case entity.ArchiveWikiPageCompensation:
+		return "", s.wikiApi.ArchivePage(c.PageId)
+	default:
+		return "", errors.New(fmt.Sprintf("unknown compensation kind: %s", c.Kind))
+	}
+}
+
+// revertPullRequest reverts the merge of the pull request on a new pull
+// request, merged unless the target requires approval.
+func (s *compensationService) revertPullRequest(ctx context.Context, c entity.Compensation, workDir string) (string, error) {
+	path := workDir + "/" + c.Repository
+	if exists, err := s.directoryService.DirectoryExists(path); err != nil {
+		return "", err
+	} else if exists {
+		if err := s.git.Checkout(ctx, path, c.TargetBranch); err != nil {
+			return "", err
+		}
+		if err := s.git.Pull(ctx, path, c.TargetBranch); err != nil {
+			return "", err
+		}
+	} else if err := s.git.CloneRepository(ctx, c.Repository, c.TargetBranch, path); err != nil {
+		return "", err
+	}
+	branch := "revert/" + c.Branch
+	if err := s.git.Branch(ctx, path, branch); err != nil {
+		return "", err
+	}
+	// The head of the branch is not on the target with a squash or fast-forward
+	// merge, the merge commit always is
+	commit := c.MergeCommit
+	if commit == "" {
+		commit = c.Commit
+	}
+	if err := s.git.Revert(ctx, path, commit); err != nil {
+		return "", err
+	}
+	if err := s.git.Push(ctx, path, branch); err != nil {
+		return "", err
+	}
+	message := fmt.Sprintf("revert: %s [Setup Ci/CD Automation rollback]", c.Branch)
+	pr, err := s.gitApi.CreatePullRequest(ctx, c.Repository, branch, c.TargetBranch, message, message)
+	if err != nil {
+		return "", err
+	}
+	if c.RequireApproval {
+		return fmt.Sprintf("revert pull request waiting for approval: %s", pr.Links.Html.Href), nil
+	}
+	_, err = s.gitApi.MergePullRequest(ctx, c.Repository, pr.Id)
+	return "", err
+}
@@ -1,15 +1,35 @@
 package service
 
+import "time"
+
+type DirectoryInfo struct {
+	Name       string
+	Path       string
+	ModifiedAt time.Time
+}
+
 type DirectoryService interface {
 	CopyFile(src string, dest string) error
 	CopyDirectory(src string, dest string) error
 	CreateDirectory(path string) error
 	RemoveDirectory(path string) error
 	DirectoryExists(path string) (bool, error)
+	// ListDirectories returns the directories directly under the path, none
+	// when the path doesn't exist.
+	ListDirectories(path string) ([]DirectoryInfo, error)
+	// DirectorySize returns the bytes used by the files under the path.
+	DirectorySize(path string) (int64, error)
+	// ReadFiles returns the content of the files under the path by their path
+	// relative to it, none when the path doesn't exist.
+	ReadFiles(path string) (map[string][]byte, error)
 	ApplyTemplateRecursively(path string, values interface{}) error
 	ApplyTemplate(path string, values interface{}) error
 	LoadTemplate(path string, values interface{}, html bool) ([]byte, error)
+	// LoadStrictTemplate loads the template like LoadTemplate, but a key
+	// missing on a map of the values is an error instead of being empty.
+	LoadStrictTemplate(path string, values interface{}, html bool) ([]byte, error)
 	RenameFile(oldPath, newPath string) error
 	DeleteFile(path string) error
 	VerifyOrInsertLineInFile(path string, line string) error
+	RemoveLineFromFile(path string, line string) error
 }
@@ -1,11 +1,20 @@
 package service
 
+import "context"
+
 type GitService interface {
-	CloneRepository(url string, branch string, path string) error
-	Checkout(path string, branch string) error
-	Branch(path string, branch string) error
-	Commit(path string, message string) error
-	Push(path string, branch string) error
-	Pull(path string, branch string) error
-	HasChanges(path string) (bool, error)
+	CloneRepository(ctx context.Context, url string, branch string, path string) error
+	Checkout(ctx context.Context, path string, branch string) error
+	Branch(ctx context.Context, path string, branch string) error
+	Commit(ctx context.Context, path string, message string) error
+	Push(ctx context.Context, path string, branch string) error
+	Pull(ctx context.Context, path string, branch string) error
+	HasChanges(ctx context.Context, path string) (bool, error)
+	Diff(ctx context.Context, path string, base string, head string) (string, error)
+	HeadCommit(ctx context.Context, path string) (string, error)
+	Revert(ctx context.Context, path string, commit string) error
+	// RemoveWorktrees unregisters the worktrees under the path from their
+	// mirrors and removes them, so their branches are free for other
+	// worktrees. The rest of the path is kept.
+	RemoveWorktrees(ctx context.Context, path string) error
 }
@@ -1,5 +1,7 @@
 package service
 
+import "context"
+
 type CreatedPullRequest struct {
 	Id    int `json:"id"`
 	Links struct {
@@ -9,6 +11,18 @@ type CreatedPullRequest struct {
 	} `json:"links"`
 }
 
+const (
+	PullRequestOpen   = "OPEN"
+	PullRequestMerged = "MERGED"
+)
+
+// PullRequest is a pull request with its state, OPEN, MERGED, DECLINED or
+// SUPERSEDED.
+type PullRequest struct {
+	CreatedPullRequest
+	State string `json:"state"`
+}
+
 type PipelineEnvironment struct {
 	Name      string              `json:"name"`
 	Variables []*PipelineVariable `json:"variables"`
@@ -21,10 +35,18 @@ type PipelineVariable struct {
 }
 
 type GitApiService interface {
-	EnablePipelines(repository string) error
-	CreatePullRequest(repository, sourceBranch, destinationBranch, title, message string) (*CreatedPullRequest, error)
-	MergePullRequest(repository string, pullRequestId int) error
-	SetRepositoryVariables(repository string, variables []*PipelineVariable) error
-	SetRepositoryEnvironmentsVariables(repository string, environments []*PipelineEnvironment) error
-	ActiveRepositoryPipelines(repository string) error
+	EnablePipelines(ctx context.Context, repository string) error
+	CreatePullRequest(ctx context.Context, repository, sourceBranch, destinationBranch, title, message string) (*CreatedPullRequest, error)
+	// MergePullRequest returns the commit the merge created on the destination
+	// branch, whatever the merge strategy.
+	MergePullRequest(ctx context.Context, repository string, pullRequestId int) (string, error)
+	DeclinePullRequest(ctx context.Context, repository string, pullRequestId int) error
+	GetPullRequest(ctx context.Context, repository string, pullRequestId int) (*PullRequest, error)
+	DeleteBranch(ctx context.Context, repository, branch string) error
+	SetRepositoryVariables(ctx context.Context, repository string, variables []*PipelineVariable) error
+	SetRepositoryEnvironmentsVariables(ctx context.Context, repository string, environments []*PipelineEnvironment) error
+	ActiveRepositoryPipelines(ctx context.Context, repository string) error
+	// AddDefaultReviewers adds the users, by their account id or uuid, to the
+	// default reviewers of the repository pull requests.
+	AddDefaultReviewers(ctx context.Context, repository string, reviewers []string) error
 }
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// K8sApplication is an application found on the GitOps repositories, read
+// from its k8s manifests.
+type K8sApplication struct {
+	Namespace       string
+	Slug            string
+	Path            string
+	Image           string
+	Port            int
+	HealthCheckPath string
+	Resources       entity.ResourcesDataObject
+	Environments    []*K8sApplicationEnvironment
+}
+
+type K8sApplicationEnvironment struct {
+	Code     string
+	Replicas entity.LimitsIntData
+	Host     string
+	Path     string
+	Image    string
+	// ArgoApplication tells if the Argo Application of the environment was
+	// found on the GitOps tools repository.
+	ArgoApplication bool
+}
+
+// FindK8sApplications lists the applications found on the layout of the
+// config, every directory matching the application destination path with a
+// base is an application and each of its overlays an environment.
+func (g *gitOpsService) FindK8sApplications(config *entity.GitOpsConfig, gitOpsPath, gitOpsToolsPath string) ([]*K8sApplication, error) {
+	pattern := strings.Trim(config.K8sApplicationDestinationPath, "/")
+	if !strings.Contains(pattern, "<applicationName>") {
+		return nil, fmt.Errorf("application destination path %s has no application name", pattern)
+	}
+	glob := strings.NewReplacer("<namespace>", "*", "<applicationName>", "*").Replace(pattern)
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.Replace(expression, "<namespace>", "(?P<ns>[^/]+)", 1)
+	expression = strings.Replace(expression, "<namespace>", "[^/]+", -1)
+	expression = strings.Replace(expression, "<applicationName>", "(?P<app>[^/]+)", 1)
+	expression = strings.Replace(expression, "<applicationName>", "[^/]+", -1)
+	matcher, err := regexp.Compile("^" + expression + "$")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(gitOpsPath + "/" + glob)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var applications []*K8sApplication
+	for _, path := range paths {
+		relative := strings.TrimPrefix(path, gitOpsPath+"/")
+		match := matcher.FindStringSubmatch(relative)
+		if match == nil {
+			continue
+		}
+		if exists, err := g.directoryService.DirectoryExists(path + "/base"); err != nil {
+			return nil, err
+		} else if !exists {
+			continue
+		}
+		application := &K8sApplication{
+			Slug: match[matcher.SubexpIndex("app")],
+			Path: relative,
+		}
+		if i := matcher.SubexpIndex("ns"); i >= 0 {
+			application.Namespace = match[i]
+		}
+		if err := g.readK8sBase(application, path+"/base"); err != nil {
+			return nil, err
+		}
+		overlays, err := os.ReadDir(path + "/overlays")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, overlay := range overlays {
+			if !overlay.IsDir() {
+				continue
+			}
+			env, err := g.readK8sOverlay(path+"/overlays/"+overlay.Name(), overlay.Name())
+			if err != nil {
+				return nil, err
+			}
+			if env.Image == "" {
+				env.Image = application.Image
+			}
+			argoPath := strings.NewReplacer(
+				"<namespace>", application.Namespace,
+				"<applicationName>", application.Slug,
+			).Replace(config.GitOpsAppsDestination(env.Code))
+			if _, err := os.Stat(fmt.Sprintf("%s/%s/%s/%s.yaml", gitOpsToolsPath, argoPath, application.Namespace, application.Slug)); err == nil {
+				env.ArgoApplication = true
+			}
+			application.Environments = append(application.Environments, env)
+		}
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
+
+// readK8sBase reads the container of the application from its base manifests.
+func (g *gitOpsService) readK8sBase(application *K8sApplication, path string) error {
+	documents, err := readManifests(path)
+	if err != nil {
+		return err
+	}
+	for _, document := range documents {
+		containers := yamlPath(document, "spec", "template", "spec", "containers")
+		if containers == nil || containers.Kind != yaml.SequenceNode || len(containers.Content) == 0 {
+			continue
+		}
+		container := containers.Content[0]
+		for _, c := range containers.Content {
+			if name := yamlPath(c, "name"); name != nil && name.Value == application.Slug {
+				container = c
+				break
+			}
+		}
+		if image := yamlPath(container, "image"); image != nil {
+			application.Image = image.Value
+		}
+		if port := yamlPath(container, "ports"); port != nil && port.Kind == yaml.SequenceNode && len(port.Content) > 0 {
+			if p := yamlPath(port.Content[0], "containerPort"); p != nil {
+				application.Port, _ = strconv.Atoi(p.Value)
+			}
+		}
+		for _, probe := range []string{"readinessProbe", "livenessProbe"} {
+			if p := yamlPath(container, probe, "httpGet", "path"); p != nil {
+				application.HealthCheckPath = p.Value
+				break
+			}
+		}
+		if v := yamlPath(container, "resources", "requests", "cpu"); v != nil {
+			application.Resources.Cpu.Min = parseCpu(v.Value)
+		}
+		if v := yamlPath(container, "resources", "limits", "cpu"); v != nil {
+			application.Resources.Cpu.Max = parseCpu(v.Value)
+		}
+		if v := yamlPath(container, "resources", "requests", "memory"); v != nil {
+			application.Resources.Memory.Min = parseMemory(v.Value)
+		}
+		if v := yamlPath(container, "resources", "limits", "memory"); v != nil {
+			application.Resources.Memory.Max = parseMemory(v.Value)
+		}
+		return nil
+	}
+	return nil
+}
+
+// readK8sOverlay reads the replicas, ingress and image of an environment from
+// its overlay.
+func (g *gitOpsService) readK8sOverlay(path, code string) (*K8sApplicationEnvironment, error) {
+	env := &K8sApplicationEnvironment{Code: code}
+	documents, err := readManifests(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		kind := ""
+		if k := yamlPath(document, "kind"); k != nil {
+			kind = k.Value
+		}
+		switch kind {
+		case "", "Kustomization":
+			if images := yamlPath(document, "images"); images != nil && images.Kind == yaml.SequenceNode && len(images.Content) > 0 {
+				env.Image = imageReference(images.Content[0])
+			}
+		case "HorizontalPodAutoscaler":
+			env.Replicas.Min = yamlInt(yamlPath(document, "spec", "minReplicas"), env.Replicas.Min)
+			env.Replicas.Max = yamlInt(yamlPath(document, "spec", "maxReplicas"), env.Replicas.Max)
+		case "ScaledObject":
+			env.Replicas.Min = yamlInt(yamlPath(document, "spec", "minReplicaCount"), env.Replicas.Min)
+			env.Replicas.Max = yamlInt(yamlPath(document, "spec", "maxReplicaCount"), env.Replicas.Max)
+		case "Deployment", "StatefulSet":
+			if replicas := yamlPath(document, "spec", "replicas"); replicas != nil && env.Replicas.Max == 0 {
+				env.Replicas.Min = yamlInt(replicas, 0)
+				env.Replicas.Max = env.Replicas.Min
+			}
+		case "Ingress":
+			rules := yamlPath(document, "spec", "rules")
+			if rules == nil || rules.Kind != yaml.SequenceNode || len(rules.Content) == 0 {
+				continue
+			}
+			if host := yamlPath(rules.Content[0], "host"); host != nil {
+				env.Host = host.Value
+			}
+			if paths := yamlPath(rules.Content[0], "http", "paths"); paths != nil && paths.Kind == yaml.SequenceNode && len(paths.Content) > 0 {
+				if p := yamlPath(paths.Content[0], "path"); p != nil {
+					env.Path = p.Value
+				}
+			}
+		}
+	}
+	return env, nil
+}
+
+// readManifests decodes every document of the yaml files in the path.
+func readManifests(path string) ([]*yaml.Node, error) {
+	var documents []*yaml.Node
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (!strings.HasSuffix(file, ".yaml") && !strings.HasSuffix(file, ".yml")) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		for {
+			document := &yaml.Node{}
+			if err := decoder.Decode(document); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("error reading %s: %w", file, err)
+			}
+			documents = append(documents, document)
+		}
+		return nil
+	})
+	return documents, err
+}
+
+func yamlInt(node *yaml.Node, fallback int) int {
+	if node == nil {
+		return fallback
+	}
+	value, err := strconv.Atoi(node.Value)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// parseCpu reads a cpu quantity as formatted by formatCpu.
+func parseCpu(quantity string) float32 {
+	if strings.HasSuffix(quantity, "m") {
+		value, _ := strconv.ParseFloat(strings.TrimSuffix(quantity, "m"), 32)
+		return float32(value / 1000)
+	}
+	value, _ := strconv.ParseFloat(quantity, 32)
+	return float32(value)
+}
+
+// parseMemory reads a memory quantity in Mi, as formatted by formatMemory.
+func parseMemory(quantity string) float32 {
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"Ki", 1.0 / 1024},
+		{"Mi", 1},
+		{"Gi", 1024},
+		{"K", 1000.0 / 1024 / 1024},
+		{"M", 1000 * 1000 / 1024.0 / 1024},
+		{"G", 1000 * 1000 * 1000 / 1024.0 / 1024},
+	}
+	for _, unit := range units {
+		if strings.HasSuffix(quantity, unit.suffix) {
+			value, _ := strconv.ParseFloat(strings.TrimSuffix(quantity, unit.suffix), 32)
+			return float32(value * unit.factor)
+		}
+	}
+	value, _ := strconv.ParseFloat(quantity, 32)
+	return float32(value / 1024 / 1024)
+}
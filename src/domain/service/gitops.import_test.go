@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const importDeployment = `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: sidecar
+          image: envoy
+        - name: %s
+          image: registry/%s:1.0.0
+          ports:
+            - containerPort: 8080
+          livenessProbe:
+            httpGet:
+              path: /health
+          resources:
+            requests:
+              cpu: 250m
+              memory: 256Mi
+            limits:
+              cpu: "1.50"
+              memory: 1.00Gi
+`
+
+func TestFindK8sApplications(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		files    []string
+		err      string
+		expected []string
+	}{
+		{
+			name:    "namespace and application",
+			pattern: "apps/<namespace>/<applicationName>",
+			files: []string{
+				"apps/payments/checkout/base/deployment.yaml",
+				"apps/payments/refunds/base/deployment.yaml",
+				"apps/orders/cart/base/deployment.yaml",
+				// Without base it isn't an application
+				"apps/orders/docs/overlays/dev/kustomization.yaml",
+				// Deeper than the pattern
+				"apps/orders/cart/extra/base/deployment.yaml",
+			},
+			expected: []string{"orders/cart", "payments/checkout", "payments/refunds"},
+		},
+		{
+			name:    "application before the namespace",
+			pattern: "/<applicationName>/k8s/<namespace>/",
+			files: []string{
+				"checkout/k8s/payments/base/deployment.yaml",
+				"checkout/docs/payments/base/deployment.yaml",
+			},
+			expected: []string{"payments/checkout"},
+		},
+		{
+			name:    "repeated placeholders",
+			pattern: "<namespace>/<applicationName>/<applicationName>",
+			files: []string{
+				"payments/checkout/checkout/base/deployment.yaml",
+				"payments/checkout/base/deployment.yaml",
+			},
+			expected: []string{"payments/checkout"},
+		},
+		{
+			name:    "without namespace",
+			pattern: "services/<applicationName>",
+			files:   []string{"services/checkout/base/deployment.yaml"},
+			// The namespace is found later from the manifests
+			expected: []string{"/checkout"},
+		},
+		{
+			name:    "without application name",
+			pattern: "apps/<namespace>",
+			err:     "has no application name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			files := make(map[string]string)
+			for _, file := range tt.files {
+				files[file] = fmt.Sprintf(importDeployment, "api", "api")
+			}
+			writeFixtures(t, root, files)
+			applications, err := testGitOpsService().FindK8sApplications(&entity.GitOpsConfig{K8sApplicationDestinationPath: tt.pattern}, root, t.TempDir())
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var found []string
+			for _, a := range applications {
+				found = append(found, a.Namespace+"/"+a.Slug)
+			}
+			if !reflect.DeepEqual(found, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, found)
+			}
+		})
+	}
+}
+
+func TestFindK8sApplicationsReadsManifests(t *testing.T) {
+	root, tools := t.TempDir(), t.TempDir()
+	writeFixtures(t, root, map[string]string{
+		"apps/payments/checkout/base/deployment.yaml": fmt.Sprintf(importDeployment, "checkout", "checkout"),
+		"apps/payments/checkout/overlays/dev/kustomization.yaml": `
+resources:
+  - ../../base
+images:
+  - name: registry/checkout
+    newTag: 1.1.0
+`,
+		"apps/payments/checkout/overlays/dev/hpa.yaml": `
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 1
+  maxReplicas: 3
+`,
+		"apps/payments/checkout/overlays/prd/deployment.yaml": `
+kind: Deployment
+spec:
+  replicas: 2
+---
+kind: Ingress
+spec:
+  rules:
+    - host: checkout.example.com
+      http:
+        paths:
+          - path: /api
+`,
+	})
+	writeFixtures(t, tools, map[string]string{"argo/dev/payments/checkout.yaml": "kind: Application\n"})
+	cfg := &entity.GitOpsConfig{
+		K8sApplicationDestinationPath: "apps/<namespace>/<applicationName>",
+		GitOpsBaseDestinationPath:     "argo/<environment>",
+	}
+	applications, err := testGitOpsService().FindK8sApplications(cfg, root, tools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applications) != 1 {
+		t.Fatalf("expected an application, got %d", len(applications))
+	}
+	a := applications[0]
+	if a.Path != filepath.Join("apps", "payments", "checkout") || a.Image != "registry/checkout:1.0.0" || a.Port != 8080 || a.HealthCheckPath != "/health" {
+		t.Errorf("unexpected application %+v", a)
+	}
+	if a.Resources != (entity.ResourcesDataObject{Cpu: entity.LimitsFloatData{Min: 0.25, Max: 1.5}, Memory: entity.LimitsFloatData{Min: 256, Max: 1024}}) {
+		t.Errorf("unexpected resources %+v", a.Resources)
+	}
+	expected := []K8sApplicationEnvironment{
+		{Code: "dev", Replicas: entity.LimitsIntData{Min: 1, Max: 3}, Image: "registry/checkout:1.1.0", ArgoApplication: true},
+		{Code: "prd", Replicas: entity.LimitsIntData{Min: 2, Max: 2}, Host: "checkout.example.com", Path: "/api", Image: "registry/checkout:1.0.0"},
+	}
+	if len(a.Environments) != len(expected) {
+		t.Fatalf("expected %d environments, got %d", len(expected), len(a.Environments))
+	}
+	for i, env := range a.Environments {
+		if *env != expected[i] {
+			t.Errorf("expected environment %+v, got %+v", expected[i], *env)
+		}
+	}
+}
+
+func TestParseQuantities(t *testing.T) {
+	for _, cpu := range []float32{0.05, 0.1, 0.25, 0.5, 1, 1.5, 2, 4} {
+		if parsed := parseCpu(formatCpu(cpu)); math.Abs(float64(parsed-cpu)) > 0.001 {
+			t.Errorf("cpu %v formatted as %s is parsed as %v", cpu, formatCpu(cpu), parsed)
+		}
+	}
+	for _, memory := range []float32{64, 128, 256, 512, 1024, 1536, 2048, 4096} {
+		if parsed := parseMemory(formatMemory(memory)); math.Abs(float64(parsed-memory)) > 0.01 {
+			t.Errorf("memory %v formatted as %s is parsed as %v", memory, formatMemory(memory), parsed)
+		}
+	}
+	tests := []struct {
+		quantity string
+		parse    func(string) float32
+		expected float32
+	}{
+		{"500m", parseCpu, 0.5},
+		{"2", parseCpu, 2},
+		{"1.25", parseCpu, 1.25},
+		{"512Ki", parseMemory, 0.5},
+		{"1Gi", parseMemory, 1024},
+		{"1G", parseMemory, 953.6743},
+		{"64M", parseMemory, 61.035156},
+		{"1048576", parseMemory, 1},
+	}
+	for _, tt := range tests {
+		if parsed := tt.parse(tt.quantity); math.Abs(float64(parsed-tt.expected)) > 0.001 {
+			t.Errorf("%s: expected %v, got %v", tt.quantity, tt.expected, parsed)
+		}
+	}
+}
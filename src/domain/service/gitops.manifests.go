@@ -0,0 +1,344 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The k8s manifests of an application may have been changed by hand since they
+// were created, so they are edited in place instead of rendered again from the
+// templates.
+
+// manifestEditor changes a document of a manifest file and tells if it was
+// changed.
+type manifestEditor func(document *yaml.Node) bool
+
+// UpdateK8sResources sets the resources of the application container on the
+// base manifests, and on the overlays' patches already setting them. An
+// application may have no overlay yet.
+func (g *gitOpsService) UpdateK8sResources(e entity.GitOpsEntity, gitOpsPath string) error {
+	data := g.createApplicationData(e)
+	appPath := gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath
+	edited, err := g.editManifests(appPath+"/base", func(document *yaml.Node) bool {
+		return setContainerResources(document, e, data, true)
+	})
+	if err != nil {
+		return err
+	}
+	if edited == 0 {
+		return errors.New("no container found on the k8s base manifests of the application")
+	}
+	if exists, err := g.directoryService.DirectoryExists(appPath + "/overlays"); err != nil || !exists {
+		return err
+	}
+	_, err = g.editManifests(appPath+"/overlays", func(document *yaml.Node) bool {
+		return setContainerResources(document, e, data, false)
+	})
+	return err
+}
+
+// UpdateK8sReplicas sets the replicas of the application on the overlay of the
+// environment.
+func (g *gitOpsService) UpdateK8sReplicas(e entity.GitOpsEntity, gitOpsPath string, env entity.SetupEnvData) error {
+	overlayPath := gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath + "/overlays/" + env.Env().Code()
+	if exists, err := g.directoryService.DirectoryExists(overlayPath); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(fmt.Sprintf("k8s overlay for %s environment not found for application", env.Env().Code()))
+	}
+	edited, err := g.editManifests(overlayPath, func(document *yaml.Node) bool {
+		return setReplicas(document, env.ReplicasMin(), env.ReplicasMax())
+	})
+	if err != nil {
+		return err
+	}
+	if edited == 0 {
+		return errors.New(fmt.Sprintf("no replicas found on the k8s overlay of %s environment", env.Env().Code()))
+	}
+	return nil
+}
+
+// editManifests applies the editor to every document of the yaml files in the
+// path, or of the file itself, the files are only written when changed. It returns how many
+// documents were matched by the editor.
+func (g *gitOpsService) editManifests(path string, editor manifestEditor) (int, error) {
+	edited := 0
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (file != path && !strings.HasSuffix(file, ".yaml") && !strings.HasSuffix(file, ".yml")) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var documents []*yaml.Node
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		changed := false
+		for {
+			document := &yaml.Node{}
+			if err := decoder.Decode(document); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("error reading %s: %w", file, err)
+			}
+			if editor(document) {
+				edited++
+				changed = true
+			}
+			documents = append(documents, document)
+		}
+		if !changed {
+			return nil
+		}
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		for _, document := range documents {
+			if err := encoder.Encode(document); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+		g.logger.Debug("Updating manifest", file)
+		return os.WriteFile(file, out.Bytes(), 0644)
+	})
+	return edited, err
+}
+
+// setContainerResources sets the resources of the application container, the
+// one named after the application or the first one. Without force, only a
+// container already setting its resources is changed.
+func setContainerResources(document *yaml.Node, e entity.GitOpsEntity, data *ApplicationData, force bool) bool {
+	containers := yamlPath(document, "spec", "template", "spec", "containers")
+	if containers == nil || containers.Kind != yaml.SequenceNode || len(containers.Content) == 0 {
+		return false
+	}
+	container := containers.Content[0]
+	for _, c := range containers.Content {
+		name := yamlPath(c, "name")
+		if name != nil && (name.Value == e.Data().ApplicationName() || name.Value == e.Data().ApplicationSlug()) {
+			container = c
+			break
+		}
+	}
+	if !force && yamlPath(container, "resources") == nil {
+		return false
+	}
+	resources := yamlMapping(container, "resources")
+	requests := yamlMapping(resources, "requests")
+	setYamlScalar(requests, "cpu", data.ApplicationCpuRequest, "!!str")
+	setYamlScalar(requests, "memory", data.ApplicationMemoryRequest, "!!str")
+	limits := yamlMapping(resources, "limits")
+	setYamlScalar(limits, "cpu", data.ApplicationCpuLimit, "!!str")
+	setYamlScalar(limits, "memory", data.ApplicationMemoryLimit, "!!str")
+	return true
+}
+
+// setReplicas sets the replicas on the autoscalers, or on the workloads
+// setting a fixed number of replicas.
+func setReplicas(document *yaml.Node, min, max int) bool {
+	kind := yamlPath(document, "kind")
+	spec := yamlPath(document, "spec")
+	if kind == nil || spec == nil || spec.Kind != yaml.MappingNode {
+		return false
+	}
+	switch kind.Value {
+	case "HorizontalPodAutoscaler":
+		setYamlScalar(spec, "minReplicas", strconv.Itoa(min), "!!int")
+		setYamlScalar(spec, "maxReplicas", strconv.Itoa(max), "!!int")
+		return true
+	case "ScaledObject":
+		setYamlScalar(spec, "minReplicaCount", strconv.Itoa(min), "!!int")
+		setYamlScalar(spec, "maxReplicaCount", strconv.Itoa(max), "!!int")
+		return true
+	case "Deployment", "StatefulSet":
+		if yamlPath(spec, "replicas") == nil {
+			return false
+		}
+		setYamlScalar(spec, "replicas", strconv.Itoa(min), "!!int")
+		return true
+	}
+	return false
+}
+
+// yamlPath returns the node at the keys of nested mappings, or nil when one is
+// missing.
+func yamlPath(node *yaml.Node, keys ...string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+	return node
+}
+
+// yamlMapping returns the mapping at the key, adding it when missing.
+func yamlMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := yamlPath(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setYamlNode(node, key, value)
+	return value
+}
+
+// setYamlScalar sets the scalar at the key, an existing one keeps its comments.
+func setYamlScalar(node *yaml.Node, key, value, tag string) {
+	if current := yamlPath(node, key); current != nil && current.Kind == yaml.ScalarNode {
+		current.Value = value
+		current.Tag = tag
+		current.Style = 0
+		return
+	}
+	setYamlNode(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+}
+
+func setYamlNode(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// PromoteImage sets on the overlay of the target environment the images
+// deployed by the overlay of the source environment. It returns the changed
+// images.
+func (g *gitOpsService) PromoteImage(e entity.GitOpsEntity, gitOpsPath string, source, target entity.SetupEnvData) ([]string, error) {
+	overlaysPath := gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath + "/overlays/"
+	sourceFile, err := kustomizationFile(overlaysPath + source.Env().Code())
+	if err != nil {
+		return []string{}, err
+	}
+	targetFile, err := kustomizationFile(overlaysPath + target.Env().Code())
+	if err != nil {
+		return []string{}, err
+	}
+	content, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return []string{}, err
+	}
+	sourceDocument := &yaml.Node{}
+	if err := yaml.Unmarshal(content, sourceDocument); err != nil {
+		return []string{}, fmt.Errorf("error reading %s: %w", sourceFile, err)
+	}
+	images := yamlPath(sourceDocument, "images")
+	if images == nil || images.Kind != yaml.SequenceNode || len(images.Content) == 0 {
+		return []string{}, errors.New(fmt.Sprintf("no image found on the k8s overlay of %s environment", source.Env().Code()))
+	}
+	var output []string
+	_, err = g.editManifests(targetFile, func(document *yaml.Node) bool {
+		if yamlPath(document, "resources") == nil && yamlPath(document, "images") == nil {
+			return false
+		}
+		root := document.Content[0]
+		targetImages := yamlPath(root, "images")
+		if targetImages == nil || targetImages.Kind != yaml.SequenceNode {
+			targetImages = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			setYamlNode(root, "images", targetImages)
+		}
+		for _, image := range images.Content {
+			name := yamlPath(image, "name")
+			if name == nil {
+				continue
+			}
+			var targetImage *yaml.Node
+			for _, t := range targetImages.Content {
+				if n := yamlPath(t, "name"); n != nil && n.Value == name.Value {
+					targetImage = t
+					break
+				}
+			}
+			if targetImage == nil {
+				targetImage = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setYamlScalar(targetImage, "name", name.Value, "!!str")
+				targetImages.Content = append(targetImages.Content, targetImage)
+			}
+			before := imageReference(targetImage)
+			for _, key := range []string{"newName", "newTag", "digest"} {
+				if value := yamlPath(image, key); value != nil {
+					setYamlScalar(targetImage, key, value.Value, "!!str")
+				} else {
+					removeYamlKey(targetImage, key)
+				}
+			}
+			output = append(output, fmt.Sprintf("%s: %s -> %s", name.Value, before, imageReference(targetImage)))
+		}
+		return true
+	})
+	if err != nil {
+		return []string{}, err
+	}
+	return output, nil
+}
+
+// kustomizationFile returns the kustomization file of the directory.
+func kustomizationFile(dir string) (string, error) {
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		if _, err := os.Stat(dir + "/" + name); err == nil {
+			return dir + "/" + name, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", errors.New(fmt.Sprintf("kustomization not found on %s", dir))
+}
+
+// imageReference formats an image entry of a kustomization.
+func imageReference(image *yaml.Node) string {
+	reference := "<none>"
+	if name := yamlPath(image, "newName"); name != nil {
+		reference = name.Value
+	} else if name := yamlPath(image, "name"); name != nil {
+		reference = name.Value
+	}
+	if tag := yamlPath(image, "newTag"); tag != nil {
+		reference += ":" + tag.Value
+	}
+	if digest := yamlPath(image, "digest"); digest != nil {
+		reference += "@" + digest.Value
+	}
+	return reference
+}
+
+func removeYamlKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
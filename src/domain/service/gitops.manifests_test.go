@@ -0,0 +1,543 @@
+package service
+
+import (
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/pkg/log_logger"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"gopkg.in/yaml.v3"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// osDirectoryService answers the directory checks from the disk, the other
+// methods aren't used by the editors.
+type osDirectoryService struct {
+	DirectoryService
+}
+
+func (osDirectoryService) DirectoryExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func testGitOpsService() *gitOpsService {
+	return &gitOpsService{
+		config:           &config.Config{SetupCiCd: &config.SetupCiCdConfig{}},
+		logger:           log_logger.New(log.New(io.Discard, "", 0), &logger.Config{Level: logger.Fatal}),
+		directoryService: osDirectoryService{},
+	}
+}
+
+func testEnv(code string, min, max int) entity.SetupEnvData {
+	return entity.NewSetupEnvData(entity.NewEnvironmentEntity(&entity.EnvironmentConfig{Code: code}), min, max)
+}
+
+func testGitOpsEntity(envs ...entity.SetupEnvData) entity.GitOpsEntity {
+	template := entity.NewTemplateEntity("api", "API", entity.ApplicationObject{}, entity.IngressObject{}, nil, "", nil)
+	data := entity.NewSetupCiCdEntity(entity.SetupCiCdData{
+		Template: template,
+		Envs:     envs,
+		Squad:    entity.NewSquadEntity(&entity.SquadConfig{Code: "payments"}),
+		Application: entity.ApplicationData{
+			Name: "checkout",
+			Resources: entity.ResourcesDataObject{
+				Cpu:    entity.LimitsFloatData{Min: 0.25, Max: 1.5},
+				Memory: entity.LimitsFloatData{Min: 256, Max: 1024},
+			},
+		},
+	})
+	return entity.NewGitOpsEntity(data, &entity.GitOpsConfig{K8sApplicationDestinationPath: "apps/<namespace>/<applicationName>"}, nil)
+}
+
+// writeFixtures writes the files under the directory, by their relative path.
+func writeFixtures(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.TrimPrefix(content, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFixture(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestSetReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		edited   bool
+		expected string
+	}{
+		{
+			name: "horizontal pod autoscaler",
+			manifest: `
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 1 # keep
+  maxReplicas: 2
+`,
+			edited: true,
+			expected: `
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 2 # keep
+  maxReplicas: 5
+`,
+		},
+		{
+			name: "scaled object without counts",
+			manifest: `
+kind: ScaledObject
+spec:
+  scaleTargetRef:
+    name: checkout
+`,
+			edited: true,
+			expected: `
+kind: ScaledObject
+spec:
+  scaleTargetRef:
+    name: checkout
+  minReplicaCount: 2
+  maxReplicaCount: 5
+`,
+		},
+		{
+			name: "deployment with fixed replicas",
+			manifest: `
+kind: Deployment
+spec:
+  replicas: "1"
+`,
+			edited: true,
+			expected: `
+kind: Deployment
+spec:
+  replicas: 2
+`,
+		},
+		{
+			name: "deployment scaled by an autoscaler",
+			manifest: `
+kind: Deployment
+spec:
+  template: {}
+`,
+			expected: `
+kind: Deployment
+spec:
+  template: {}
+`,
+		},
+		{
+			name: "other kinds",
+			manifest: `
+kind: Service
+spec:
+  replicas: 1
+`,
+			expected: `
+kind: Service
+spec:
+  replicas: 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := &yaml.Node{}
+			if err := yaml.Unmarshal([]byte(strings.TrimPrefix(tt.manifest, "\n")), document); err != nil {
+				t.Fatal(err)
+			}
+			if edited := setReplicas(document, 2, 5); edited != tt.edited {
+				t.Fatalf("expected edited %v, got %v", tt.edited, edited)
+			}
+			var out strings.Builder
+			encoder := yaml.NewEncoder(&out)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(document); err != nil {
+				t.Fatal(err)
+			}
+			if expected := strings.TrimPrefix(tt.expected, "\n"); out.String() != expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+			}
+		})
+	}
+}
+
+func TestUpdateK8sReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		err      string
+		expected map[string]string
+	}{
+		{
+			name: "autoscaler of the overlay",
+			files: map[string]string{
+				"overlays/dev/hpa.yaml": `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 1
+  maxReplicas: 1
+`,
+				"overlays/prd/hpa.yaml": `
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 3
+  maxReplicas: 9
+`,
+			},
+			expected: map[string]string{
+				"overlays/dev/hpa.yaml": `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 2
+  maxReplicas: 4
+`,
+				"overlays/prd/hpa.yaml": `
+kind: HorizontalPodAutoscaler
+spec:
+  minReplicas: 3
+  maxReplicas: 9
+`,
+			},
+		},
+		{
+			name: "overlay without replicas",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+resources:
+  - ../../base
+`,
+			},
+			err: "no replicas found",
+		},
+		{
+			name:  "missing overlay",
+			files: map[string]string{"overlays/prd/kustomization.yaml": "resources: []\n"},
+			err:   "k8s overlay for dev environment not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			appPath := filepath.Join(root, "apps/payments/checkout")
+			writeFixtures(t, appPath, tt.files)
+			env := testEnv("dev", 2, 4)
+			err := testGitOpsService().UpdateK8sReplicas(testGitOpsEntity(env), root, env)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, expected := range tt.expected {
+				if content := readFixture(t, filepath.Join(appPath, name)); content != strings.TrimPrefix(expected, "\n") {
+					t.Errorf("%s: expected:\n%s\ngot:\n%s", name, expected, content)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateK8sResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		err      string
+		expected map[string]string
+	}{
+		{
+			name: "container named after the application",
+			files: map[string]string{
+				"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: proxy
+          image: envoy
+        - name: checkout
+          image: checkout
+          resources:
+            requests:
+              cpu: 100m # minimum
+              memory: 128Mi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: checkout
+`,
+				"overlays/prd/patch.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: checkout
+          resources:
+            limits:
+              cpu: "2"
+`,
+				"overlays/dev/patch.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: checkout
+          env: []
+`,
+			},
+			expected: map[string]string{
+				"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: proxy
+          image: envoy
+        - name: checkout
+          image: checkout
+          resources:
+            requests:
+              cpu: 250m # minimum
+              memory: 256Mi
+            limits:
+              cpu: "1.50"
+              memory: 1.00Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: checkout
+`,
+				"overlays/prd/patch.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: checkout
+          resources:
+            limits:
+              cpu: "1.50"
+              memory: 1.00Gi
+            requests:
+              cpu: 250m
+              memory: 256Mi
+`,
+				"overlays/dev/patch.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: checkout
+          env: []
+`,
+			},
+		},
+		{
+			name: "first container without resources",
+			files: map[string]string{
+				"base/deployment.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+`,
+			},
+			expected: map[string]string{
+				"base/deployment.yaml": `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          resources:
+            requests:
+              cpu: 250m
+              memory: 256Mi
+            limits:
+              cpu: "1.50"
+              memory: 1.00Gi
+`,
+			},
+		},
+		{
+			name: "base without containers",
+			files: map[string]string{
+				"base/service.yaml": `
+kind: Service
+spec:
+  ports: []
+`,
+			},
+			err: "no container found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			appPath := filepath.Join(root, "apps/payments/checkout")
+			writeFixtures(t, appPath, tt.files)
+			err := testGitOpsService().UpdateK8sResources(testGitOpsEntity(), root)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, expected := range tt.expected {
+				if content := readFixture(t, filepath.Join(appPath, name)); content != strings.TrimPrefix(expected, "\n") {
+					t.Errorf("%s: expected:\n%s\ngot:\n%s", name, expected, content)
+				}
+			}
+		})
+	}
+}
+
+func TestPromoteImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		err      string
+		output   []string
+		expected string
+	}{
+		{
+			name: "retags the image of the target",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+resources:
+  - ../../base
+images:
+  - name: checkout
+    newName: registry/checkout
+    newTag: "1.2.0"
+`,
+				"overlays/prd/kustomization.yaml": `
+resources:
+  - ../../base
+images:
+  - name: checkout # promoted
+    newName: registry/checkout
+    newTag: 1.1.0
+    digest: sha256:abc
+`,
+			},
+			output: []string{"checkout: registry/checkout:1.1.0@sha256:abc -> registry/checkout:1.2.0"},
+			expected: `
+resources:
+  - ../../base
+images:
+  - name: checkout # promoted
+    newName: registry/checkout
+    newTag: 1.2.0
+`,
+		},
+		{
+			name: "adds the images missing on the target",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+images:
+  - name: checkout
+    digest: sha256:def
+`,
+				"overlays/prd/kustomization.yml": `
+resources:
+  - ../../base
+`,
+			},
+			output: []string{"checkout: checkout -> checkout@sha256:def"},
+			expected: `
+resources:
+  - ../../base
+images:
+  - name: checkout
+    digest: sha256:def
+`,
+		},
+		{
+			name: "source without images",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": "resources: []\n",
+				"overlays/prd/kustomization.yaml": "resources: []\n",
+			},
+			err: "no image found on the k8s overlay of dev environment",
+		},
+		{
+			name: "target without kustomization",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": "images: []\n",
+				"overlays/prd/hpa.yaml":           "kind: HorizontalPodAutoscaler\n",
+			},
+			err: "kustomization not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			appPath := filepath.Join(root, "apps/payments/checkout")
+			writeFixtures(t, appPath, tt.files)
+			source, target := testEnv("dev", 1, 1), testEnv("prd", 2, 4)
+			output, err := testGitOpsService().PromoteImage(testGitOpsEntity(source, target), root, source, target)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(output, "\n") != strings.Join(tt.output, "\n") {
+				t.Errorf("expected output %v, got %v", tt.output, output)
+			}
+			file, err := kustomizationFile(filepath.Join(appPath, "overlays/prd"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content := readFixture(t, file); content != strings.TrimPrefix(tt.expected, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
@@ -23,10 +23,23 @@ type SetupGitOpsData struct {
 
 type GitOpsService interface {
 	LoadData(data entity.SetupCiCdEntity, manifest *entity.Manifest, templatesPath string) (entity.GitOpsEntity, error)
+	LoadConfig(manifest *entity.Manifest, templatesPath string) (*entity.GitOpsConfig, error)
+	FindK8sApplications(config *entity.GitOpsConfig, gitOpsPath, gitOpsToolsPath string) ([]*K8sApplication, error)
 	SetupBaseUtilities(e entity.GitOpsEntity, templatesPath, gitOpsPath string) error
 	SetupNamespacedUtilities(e entity.GitOpsEntity, templatesPath, gitOpsPath string) error
-	SetupK8sManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, error)
+	// SetupK8sManifests and SetupK8sOverlays return the environments they
+	// created, the entity's created data is left to the caller.
+	SetupK8sManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, []*entity.EnvironmentCreatedData, error)
+	SetupK8sOverlays(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, []*entity.EnvironmentCreatedData, error)
 	SetupGitOpsManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath string, env entity.SetupEnvData) error
+	// GitOpsApplicationPath is the Argo Application of the environment,
+	// relative to the GitOps tools repository.
+	GitOpsApplicationPath(e entity.GitOpsEntity, env entity.SetupEnvData) string
+	UpdateK8sResources(e entity.GitOpsEntity, gitOpsPath string) error
+	UpdateK8sReplicas(e entity.GitOpsEntity, gitOpsPath string, env entity.SetupEnvData) error
+	PromoteImage(e entity.GitOpsEntity, gitOpsPath string, source, target entity.SetupEnvData) ([]string, error)
+	RemoveK8sManifests(e entity.GitOpsEntity, gitOpsPath, cmPath string) error
+	RemoveGitOpsManifests(e entity.GitOpsEntity, gitOpsPath string, env entity.SetupEnvData) error
 }
 
 type gitOpsService struct {
@@ -44,6 +57,16 @@ func NewGitOpsService(config *config.Config, logger logger.Logger, directoryServ
 }
 
 func (g *gitOpsService) LoadData(data entity.SetupCiCdEntity, manifest *entity.Manifest, templatesPath string) (entity.GitOpsEntity, error) {
+	configData, err := g.LoadConfig(manifest, templatesPath)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewGitOpsEntity(data, configData, entity.DefaultTags(data)), nil
+}
+
+// LoadConfig reads the config of the manifest, its paths keep the
+// placeholders of the application.
+func (g *gitOpsService) LoadConfig(manifest *entity.Manifest, templatesPath string) (*entity.GitOpsConfig, error) {
 	cfg, err := os.ReadFile(fmt.Sprintf("%s/%s/config.yaml", templatesPath, manifest.Dir))
 	if err != nil {
 		return nil, err
@@ -54,7 +77,7 @@ func (g *gitOpsService) LoadData(data entity.SetupCiCdEntity, manifest *entity.M
 		g.logger.Error("Error unmarshalling config", err.Error(), string(cfg))
 		return nil, err
 	}
-	return entity.NewGitOpsEntity(data, configData, entity.DefaultTags(data)), nil
+	return configData, nil
 }
 
 func (g *gitOpsService) SetupBaseUtilities(e entity.GitOpsEntity, templatesPath, gitOpsPath string) error {
@@ -76,6 +99,13 @@ func (g *gitOpsService) SetupBaseUtilities(e entity.GitOpsEntity, templatesPath,
 	return g.directoryService.CopyDirectory(templatesPath, gitOpsPath)
 }
 
+// NamespaceUtilitiesData renders the utilities of a namespace, they are
+// created with the params of the first application of the namespace.
+type NamespaceUtilitiesData struct {
+	Namespace string
+	Params    map[string]interface{}
+}
+
 func (g *gitOpsService) SetupNamespacedUtilities(e entity.GitOpsEntity, templatesPath, gitOpsPath string) error {
 	templatesPath = templatesPath + "/" + e.Config().K8sNamespaceUtilitiesTemplatesPath
 	gitOpsPath = gitOpsPath + "/" + e.Config().K8sNamespaceUtilitiesDestinationPath
@@ -95,10 +125,7 @@ func (g *gitOpsService) SetupNamespacedUtilities(e entity.GitOpsEntity, template
 	if err := g.directoryService.CopyDirectory(templatesPath, gitOpsPath); err != nil {
 		return err
 	}
-	type ReplaceValue struct {
-		Namespace string
-	}
-	replaceValues := ReplaceValue{Namespace: e.Data().Squad().Code()}
+	replaceValues := NamespaceUtilitiesData{Namespace: e.Data().Squad().Namespace(), Params: e.Data().Params()}
 	return g.directoryService.ApplyTemplateRecursively(gitOpsPath, replaceValues)
 }
 
@@ -125,49 +152,67 @@ type ApplicationData struct {
 	ApplicationMinReplicas              int
 	ApplicationMaxReplicas              int
 	EnvironmentMountPath                string
+	Params                              map[string]interface{}
 }
 
-func (g *gitOpsService) SetupK8sManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, error) {
-	cmTemplatesPath := templatesPath + "/" + e.Config().K8sConfigMapTemplatesPath
-	templatesPath = templatesPath + "/" + e.Config().K8sApplicationTemplatesPath
-	gitOpsPath = gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath
-	if exists, err := g.directoryService.DirectoryExists(gitOpsPath); err != nil {
-		return []string{}, err
+func (g *gitOpsService) SetupK8sManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, []*entity.EnvironmentCreatedData, error) {
+	appTemplatesPath := templatesPath + "/" + e.Config().K8sApplicationTemplatesPath
+	appPath := gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath
+	if exists, err := g.directoryService.DirectoryExists(appPath); err != nil {
+		return []string{}, nil, err
 	} else if exists {
-		return []string{}, errors.New("k8s manifests for already exists for application")
+		return []string{}, nil, errors.New("k8s manifests for already exists for application")
 	}
-	if err := g.directoryService.CreateDirectory(gitOpsPath); err != nil {
-		return []string{}, err
+	if err := g.directoryService.CreateDirectory(appPath); err != nil {
+		return []string{}, nil, err
 	}
-	if err := g.directoryService.CopyDirectory(templatesPath+"/base", gitOpsPath+"/base"); err != nil {
-		return []string{}, err
+	if err := g.directoryService.CopyDirectory(appTemplatesPath+"/base", appPath+"/base"); err != nil {
+		return []string{}, nil, err
 	}
 	data := g.createApplicationData(e)
-	g.logger.Debug("Applying template recursively", gitOpsPath+"/base", data)
-	if err := g.directoryService.ApplyTemplateRecursively(gitOpsPath+"/base", data); err != nil {
-		return []string{}, err
+	g.logger.Debug("Applying template recursively", appPath+"/base", data)
+	if err := g.directoryService.ApplyTemplateRecursively(appPath+"/base", data); err != nil {
+		return []string{}, nil, err
 	}
-	if err := g.directoryService.CreateDirectory(gitOpsPath + "/overlays"); err != nil {
-		return []string{}, err
+	if err := g.directoryService.CreateDirectory(appPath + "/overlays"); err != nil {
+		return []string{}, nil, err
 	}
+	return g.SetupK8sOverlays(e, templatesPath, gitOpsPath, cmPath)
+}
+
+// SetupK8sOverlays renders the overlays of the entity's environments on the
+// manifests of an application, an existing overlay is an error.
+func (g *gitOpsService) SetupK8sOverlays(e entity.GitOpsEntity, templatesPath, gitOpsPath, cmPath string) ([]string, []*entity.EnvironmentCreatedData, error) {
+	cmTemplatesPath := templatesPath + "/" + e.Config().K8sConfigMapTemplatesPath
+	templatesPath = templatesPath + "/" + e.Config().K8sApplicationTemplatesPath
+	gitOpsPath = gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath
+	data := g.createApplicationData(e)
 	extraData := []string{"Application ingresses:"}
+	var environments []*entity.EnvironmentCreatedData
 	for _, env := range e.Data().Envs() {
-		if err := g.directoryService.CopyDirectory(templatesPath+"/overlays/overlay", gitOpsPath+"/overlays/"+env.Env().Code()); err != nil {
-			return []string{}, err
+		overlayPath := gitOpsPath + "/overlays/" + env.Env().Code()
+		if exists, err := g.directoryService.DirectoryExists(overlayPath); err != nil {
+			return []string{}, nil, err
+		} else if exists {
+			return []string{}, nil, errors.New(fmt.Sprintf("k8s overlay for %s environment already exists for application", env.Env().Code()))
+		}
+		if err := g.directoryService.CopyDirectory(templatesPath+"/overlays/overlay", overlayPath); err != nil {
+			return []string{}, nil, err
 		}
 		data.IngressHost = e.Data().IngressHost(env.Env().Code())
 		data.IngressPath = e.Data().IngressPath(env.Env().Code())
 		data.ApplicationMinReplicas = env.ReplicasMin()
 		data.ApplicationMaxReplicas = env.ReplicasMax()
 		data.EnvironmentMountPath = env.Env().SecretsPath()
-		if err := g.directoryService.ApplyTemplateRecursively(gitOpsPath+"/overlays/"+env.Env().Code(), data); err != nil {
-			return []string{}, err
+		data.Params = e.Data().EnvParams(env.Env().Code())
+		if err := g.directoryService.ApplyTemplateRecursively(overlayPath, data); err != nil {
+			return []string{}, nil, err
 		}
 		extraData = append(
 			extraData,
 			fmt.Sprintf(" -- %s: %s", env.Env().Label(), e.Data().IngressFull(env.Env().Code())),
 		)
-		e.Data().CreatedData().Environments = append(e.Data().CreatedData().Environments, &entity.EnvironmentCreatedData{
+		environments = append(environments, &entity.EnvironmentCreatedData{
 			Label:           env.Env().Label(),
 			Code:            env.Env().Code(),
 			Url:             e.Data().IngressFull(env.Env().Code()),
@@ -177,16 +222,16 @@ func (g *gitOpsService) SetupK8sManifests(e entity.GitOpsEntity, templatesPath,
 			continue
 		}
 		if err := g.directoryService.CreateDirectory(fmt.Sprintf("%s/%s", cmPath, e.Config().K8sConfigMapDestinationPath)); err != nil {
-			return []string{}, err
+			return []string{}, nil, err
 		}
 		if err := g.directoryService.CopyDirectory(cmTemplatesPath+"/overlay", fmt.Sprintf("%s/%s/%s", cmPath, e.Config().K8sConfigMapDestinationPath, env.Env().Code())); err != nil {
-			return []string{}, err
+			return []string{}, nil, err
 		}
 		if err := g.directoryService.ApplyTemplateRecursively(fmt.Sprintf("%s/%s/%s", cmPath, e.Config().K8sConfigMapDestinationPath, env.Env().Code()), data); err != nil {
-			return []string{}, err
+			return []string{}, nil, err
 		}
 	}
-	return extraData, nil
+	return extraData, environments, nil
 }
 
 type GitOpsManifestsData struct {
@@ -199,21 +244,27 @@ type GitOpsManifestsData struct {
 	namespaceUtilitiesFileName            string
 	appDestinationPath                    string
 	Namespace                             string
-	ApplicationName                       string
-	Environment                           string
-	DestinationCluster                    string
-	Project                               string
-	K8sApplicationPath                    string
-	K8sNamespaceUtilitiesPath             string
-	GitOpsToolsRepository                 string
-	GitOpsRepository                      string
-	ConfigMapPath                         string
-	ConfigMapRepository                   string
+	// Squad and its metadata label the namespace on the namespace utilities
+	Squad                     string
+	SquadOwners               []string
+	SquadSlackChannel         string
+	SquadCostCenter           string
+	ApplicationName           string
+	Environment               string
+	DestinationCluster        string
+	Project                   string
+	K8sApplicationPath        string
+	K8sNamespaceUtilitiesPath string
+	GitOpsToolsRepository     string
+	GitOpsRepository          string
+	ConfigMapPath             string
+	ConfigMapRepository       string
+	Params                    map[string]interface{}
 }
 
 func (g *gitOpsService) SetupGitOpsManifests(e entity.GitOpsEntity, templatesPath, gitOpsPath string, env entity.SetupEnvData) error {
 	gitOpsBaseDestinationPath := gitOpsPath + "/" + e.Config().GitOpsAppsDestination(env.Env().Code())
-	gitOpsNamespaceDestinationPath := gitOpsBaseDestinationPath + "/" + e.Data().Squad().Code()
+	gitOpsNamespaceDestinationPath := gitOpsBaseDestinationPath + "/" + e.Data().Squad().Namespace()
 	if exists, err := g.directoryService.DirectoryExists(gitOpsNamespaceDestinationPath); err != nil {
 		return err
 	} else if !exists {
@@ -228,8 +279,12 @@ func (g *gitOpsService) SetupGitOpsManifests(e entity.GitOpsEntity, templatesPat
 		baseKustomizationDestinationPath:      gitOpsBaseDestinationPath + "/kustomization.yaml",
 		namespaceKustomizationDestinationPath: gitOpsNamespaceDestinationPath + "/kustomization.yaml",
 		namespaceUtilitiesDestinationPath:     gitOpsNamespaceDestinationPath + "/_base.yaml",
-		appDestinationPath:                    gitOpsNamespaceDestinationPath + "/" + e.Data().ApplicationSlug() + ".yaml",
-		Namespace:                             e.Data().Squad().Code(),
+		appDestinationPath:                    gitOpsPath + "/" + g.GitOpsApplicationPath(e, env),
+		Namespace:                             e.Data().Squad().Namespace(),
+		Squad:                                 e.Data().Squad().Code(),
+		SquadOwners:                           e.Data().Squad().Owners(),
+		SquadSlackChannel:                     e.Data().Squad().SlackChannel(),
+		SquadCostCenter:                       e.Data().Squad().CostCenter(),
 		ApplicationName:                       e.Data().ApplicationSlug(),
 		Environment:                           env.Env().Code(),
 		DestinationCluster:                    env.Env().DestinationCluster(),
@@ -240,6 +295,7 @@ func (g *gitOpsService) SetupGitOpsManifests(e entity.GitOpsEntity, templatesPat
 		GitOpsToolsRepository:                 g.config.SetupCiCd.GitOpsToolsRepository,
 		ConfigMapPath:                         e.Config().K8sConfigMapDestinationPath + "/" + env.Env().Code(),
 		ConfigMapRepository:                   g.config.SetupCiCd.ConfigMapRepository,
+		Params:                                e.Data().EnvParams(env.Env().Code()),
 	}
 	if err := g.setupGitOpsBaseManifests(data); err != nil {
 		return err
@@ -250,6 +306,10 @@ func (g *gitOpsService) SetupGitOpsManifests(e entity.GitOpsEntity, templatesPat
 	return g.setupGitOpsApplicationManifests(data)
 }
 
+func (g *gitOpsService) GitOpsApplicationPath(e entity.GitOpsEntity, env entity.SetupEnvData) string {
+	return e.Config().GitOpsAppsDestination(env.Env().Code()) + "/" + e.Data().Squad().Namespace() + "/" + e.Data().ApplicationSlug() + ".yaml"
+}
+
 func (g *gitOpsService) setupGitOpsBaseManifests(data *GitOpsManifestsData) error {
 	if exists, err := g.directoryService.DirectoryExists(data.baseKustomizationDestinationPath); err != nil {
 		return err
@@ -303,6 +363,44 @@ func (g *gitOpsService) setupGitOpsApplicationManifests(data *GitOpsManifestsDat
 	return g.directoryService.ApplyTemplate(data.appDestinationPath, data)
 }
 
+// RemoveK8sManifests removes the application's manifests and its config map
+// overlays, the base and namespace utilities are kept as they are shared.
+func (g *gitOpsService) RemoveK8sManifests(e entity.GitOpsEntity, gitOpsPath, cmPath string) error {
+	if err := g.removeDirectory(gitOpsPath + "/" + e.Config().K8sApplicationDestinationPath); err != nil {
+		return err
+	}
+	if !g.config.SetupCiCd.ExternalConfigMap {
+		return nil
+	}
+	return g.removeDirectory(cmPath + "/" + e.Config().K8sConfigMapDestinationPath)
+}
+
+// RemoveGitOpsManifests removes the application's Argo Application from the
+// environment and its entry from the namespace kustomization.
+func (g *gitOpsService) RemoveGitOpsManifests(e entity.GitOpsEntity, gitOpsPath string, env entity.SetupEnvData) error {
+	namespacePath := gitOpsPath + "/" + e.Config().GitOpsAppsDestination(env.Env().Code()) + "/" + e.Data().Squad().Namespace()
+	appPath := namespacePath + "/" + e.Data().ApplicationSlug() + ".yaml"
+	if exists, err := g.directoryService.DirectoryExists(appPath); err != nil {
+		return err
+	} else if exists {
+		if err := g.directoryService.DeleteFile(appPath); err != nil {
+			return err
+		}
+	}
+	kustomizationPath := namespacePath + "/kustomization.yaml"
+	if exists, err := g.directoryService.DirectoryExists(kustomizationPath); err != nil || !exists {
+		return err
+	}
+	return g.directoryService.RemoveLineFromFile(kustomizationPath, fmt.Sprintf("- %s.yaml", e.Data().ApplicationSlug()))
+}
+
+func (g *gitOpsService) removeDirectory(path string) error {
+	if exists, err := g.directoryService.DirectoryExists(path); err != nil || !exists {
+		return err
+	}
+	return g.directoryService.RemoveDirectory(path)
+}
+
 func (g *gitOpsService) getParentDir(path string) string {
 	return path[0 : len(path)-len(g.getDirName(path))-1]
 }
@@ -318,7 +416,7 @@ func (g *gitOpsService) getDirName(path string) string {
 
 func (g *gitOpsService) createApplicationData(e entity.GitOpsEntity) *ApplicationData {
 	return &ApplicationData{
-		Namespace:                           e.Data().Squad().Code(),
+		Namespace:                           e.Data().Squad().Namespace(),
 		ApplicationName:                     e.Data().ApplicationName(),
 		ApplicationPort:                     e.Data().ApplicationPort(),
 		ApplicationCpuLimit:                 formatCpu(e.Data().ApplicationMaxCpu()),
@@ -335,5 +433,6 @@ func (g *gitOpsService) createApplicationData(e entity.GitOpsEntity) *Applicatio
 		IngressFrontend:                     e.Data().Template().IngressDefault().Frontend,
 		DefaultImageName:                    g.config.SetupCiCd.DefaultImageName,
 		DefaultImageTag:                     g.config.SetupCiCd.DefaultImageTag,
+		Params:                              e.Data().Params(),
 	}
 }
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The schemas follow the definitions of the Kubernetes OpenAPI spec, each
+// resource is found by its x-kubernetes-group-version-kind. Only the resources
+// the templates use are embedded, the others are skipped.
+//
+//go:embed schemas/*.json
+var manifestSchemas embed.FS
+
+const kustomizationApiVersion = "kustomize.config.k8s.io/v1beta1"
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+type ManifestViolation struct {
+	// File is relative to the root validated
+	File    string
+	Line    int
+	Message string
+}
+
+func (v ManifestViolation) String() string {
+	return fmt.Sprintf("%s:%d: %s", v.File, v.Line, v.Message)
+}
+
+type ManifestValidation struct {
+	Violations []ManifestViolation
+	// Skipped are the resources without schema, by file, line and kind
+	Skipped []string
+}
+
+// ManifestSchemaService checks the generated manifests offline before they are
+// pushed, like kubeconform in strict mode: the fields must exist on the schema
+// of the resource and have its types.
+type ManifestSchemaService interface {
+	// Validate checks the yaml files under the paths, files or directories
+	// relative to root. The patches of the kustomizations are checked without
+	// the required fields, and the files of their generators are ignored.
+	Validate(root string, paths ...string) (*ManifestValidation, error)
+}
+
+type manifestSchemaService struct {
+	logger           logger.Logger
+	directoryService DirectoryService
+	schemas          map[string]*manifestSchema
+}
+
+func NewManifestSchemaService(logger logger.Logger, directoryService DirectoryService) ManifestSchemaService {
+	schemas, err := loadManifestSchemas()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded manifest schemas: %s", err.Error()))
+	}
+	return &manifestSchemaService{
+		logger:           logger,
+		directoryService: directoryService,
+		schemas:          schemas,
+	}
+}
+
+func (m *manifestSchemaService) Validate(root string, paths ...string) (*ManifestValidation, error) {
+	files := make(map[string][]byte)
+	for _, p := range paths {
+		read, err := m.directoryService.ReadFiles(root + "/" + p)
+		if err != nil {
+			return nil, err
+		}
+		for name, content := range read {
+			files[path.Join(p, name)] = content
+		}
+	}
+	patches, ignored := kustomizationRefs(files)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		ext := path.Ext(name)
+		if (ext == ".yaml" || ext == ".yml") && !ignored[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := &ManifestValidation{}
+	for _, name := range names {
+		m.logger.Debug("Validating manifest", name)
+		m.validateFile(result, name, files[name], patches[name])
+	}
+	return result, nil
+}
+
+func (m *manifestSchemaService) validateFile(result *ManifestValidation, name string, content []byte, patch bool) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return
+		} else if err != nil {
+			line := 0
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				line, _ = strconv.Atoi(match[1])
+			}
+			result.Violations = append(result.Violations, ManifestViolation{File: name, Line: line, Message: err.Error()})
+			return
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		node := doc.Content[0]
+		if node.Kind != yaml.MappingNode {
+			// The json 6902 patches are lists of operations
+			if !patch {
+				result.Violations = append(result.Violations, ManifestViolation{File: name, Line: node.Line, Message: "expected a kubernetes resource"})
+			}
+			continue
+		}
+		apiVersion, kind := mappingValue(node, "apiVersion"), mappingValue(node, "kind")
+		if apiVersion == "" && kind == "" && isKustomization(name) {
+			// Kustomize doesn't require the kind of its files
+			apiVersion, kind = kustomizationApiVersion, "Kustomization"
+		}
+		switch {
+		case apiVersion == "" && kind == "":
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s:%d: not a kubernetes resource", name, node.Line))
+			continue
+		case apiVersion == "" || kind == "":
+			result.Violations = append(result.Violations, ManifestViolation{File: name, Line: node.Line, Message: "apiVersion and kind are required"})
+			continue
+		}
+		schema, ok := m.schemas[apiVersion+"/"+kind]
+		if !ok {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s:%d: no schema for %s %s", name, node.Line, apiVersion, kind))
+			continue
+		}
+		v := &manifestValidator{file: name, patch: patch}
+		v.validate(schema, node, "")
+		result.Violations = append(result.Violations, v.violations...)
+	}
+}
+
+// kustomizationRefs returns the files the kustomizations use as strategic
+// merge patches, and the ones to ignore: the json 6902 patches and the
+// sources of the generators.
+func kustomizationRefs(files map[string][]byte) (map[string]bool, map[string]bool) {
+	type generator struct {
+		Files []string `yaml:"files"`
+		Envs  []string `yaml:"envs"`
+		Env   string   `yaml:"env"`
+	}
+	type pathRef struct {
+		Path string `yaml:"path"`
+	}
+	patches := make(map[string]bool)
+	ignored := make(map[string]bool)
+	for name, content := range files {
+		if !isKustomization(name) {
+			continue
+		}
+		var k struct {
+			PatchesStrategicMerge []string    `yaml:"patchesStrategicMerge"`
+			Patches               []pathRef   `yaml:"patches"`
+			PatchesJson6902       []pathRef   `yaml:"patchesJson6902"`
+			ConfigMapGenerator    []generator `yaml:"configMapGenerator"`
+			SecretGenerator       []generator `yaml:"secretGenerator"`
+		}
+		// An invalid kustomization is reported by its own validation
+		if err := yaml.Unmarshal(content, &k); err != nil {
+			continue
+		}
+		dir := path.Dir(name)
+		for _, p := range k.PatchesStrategicMerge {
+			patches[path.Join(dir, p)] = true
+		}
+		for _, p := range k.Patches {
+			if p.Path != "" {
+				patches[path.Join(dir, p.Path)] = true
+			}
+		}
+		for _, p := range k.PatchesJson6902 {
+			ignored[path.Join(dir, p.Path)] = true
+		}
+		for _, g := range append(k.ConfigMapGenerator, k.SecretGenerator...) {
+			sources := append(append([]string{}, g.Files...), g.Envs...)
+			if g.Env != "" {
+				sources = append(sources, g.Env)
+			}
+			for _, s := range sources {
+				// The files can be renamed on the generated key with key=path
+				if i := strings.Index(s, "="); i >= 0 {
+					s = s[i+1:]
+				}
+				ignored[path.Join(dir, s)] = true
+			}
+		}
+	}
+	return patches, ignored
+}
+
+func isKustomization(name string) bool {
+	switch path.Base(name) {
+	case "kustomization.yaml", "kustomization.yml", "Kustomization":
+		return true
+	}
+	return false
+}
+
+func mappingValue(node *yaml.Node, key string) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
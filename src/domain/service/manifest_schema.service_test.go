@@ -0,0 +1,240 @@
+package service
+
+import (
+	"github.com/zahirsis/dev-portal-backend/pkg/log_logger"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// filesDirectoryService reads the files to validate from the disk.
+type filesDirectoryService struct {
+	osDirectoryService
+}
+
+func (filesDirectoryService) ReadFiles(path string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = content
+		return nil
+	})
+	return files, err
+}
+
+const schemaDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: registry/api:1.0.0
+          ports:
+            - containerPort: 8080
+          resources:
+            limits:
+              cpu: 500m
+              memory: 512Mi
+`
+
+func TestManifestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		violations []ManifestViolation
+	}{
+		{
+			name:  "valid deployment",
+			files: map[string]string{"base/deployment.yaml": schemaDeployment},
+		},
+		{
+			name: "unknown field",
+			files: map[string]string{"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replica: 2
+  selector: {}
+  template: {}
+`},
+			violations: []ManifestViolation{{File: "base/deployment.yaml", Line: 6, Message: "spec.replica: unknown field"}},
+		},
+		{
+			name: "wrong type",
+			files: map[string]string{"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: two
+  selector: {}
+  template: {}
+`},
+			violations: []ManifestViolation{{File: "base/deployment.yaml", Line: 6, Message: "spec.replicas: expected integer, got string"}},
+		},
+		{
+			name: "missing required fields",
+			files: map[string]string{"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+`},
+			violations: []ManifestViolation{
+				{File: "base/deployment.yaml", Line: 6, Message: "spec.selector: field is required"},
+				{File: "base/deployment.yaml", Line: 6, Message: "spec.template: field is required"},
+			},
+		},
+		{
+			name: "patch missing required fields",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+resources:
+  - ../../base
+patches:
+  - path: replicas.yaml
+`,
+				"overlays/dev/replicas.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+`,
+			},
+		},
+		{
+			name: "invalid patch",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+patchesStrategicMerge:
+  - replicas.yaml
+`,
+				"overlays/dev/replicas.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: three
+`,
+			},
+			violations: []ManifestViolation{{File: "overlays/dev/replicas.yaml", Line: 6, Message: "spec.replicas: expected integer, got string"}},
+		},
+		{
+			name: "json 6902 patches are ignored",
+			files: map[string]string{
+				"overlays/dev/kustomization.yaml": `
+patchesJson6902:
+  - target:
+      kind: Deployment
+      name: api
+    path: replicas.yaml
+`,
+				"overlays/dev/replicas.yaml": `
+- op: replace
+  path: /spec/replicas
+  value: 3
+`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFixtures(t, root, tt.files)
+			s := NewManifestSchemaService(log_logger.New(log.New(io.Discard, "", 0), &logger.Config{Level: logger.Fatal}), filesDirectoryService{})
+			result, err := s.Validate(root, "base", "overlays")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Violations, tt.violations) {
+				t.Fatalf("expected violations %v, got %v", tt.violations, result.Violations)
+			}
+		})
+	}
+}
+
+func TestKustomizationRefs(t *testing.T) {
+	patches, ignored := kustomizationRefs(map[string][]byte{
+		"overlays/dev/kustomization.yaml": []byte(`
+resources:
+  - ../../base
+patchesStrategicMerge:
+  - replicas.yaml
+patches:
+  - path: patches/resources.yaml
+  - patch: |-
+      - op: remove
+        path: /spec/replicas
+patchesJson6902:
+  - path: patches/ops.yaml
+configMapGenerator:
+  - name: api
+    files:
+      - application.properties
+      - config=files/config.json
+    envs:
+      - .env
+secretGenerator:
+  - name: api
+    env: secrets.env
+`),
+		// Not a kustomization
+		"base/deployment.yaml": []byte("patchesStrategicMerge: [ignored.yaml]\n"),
+		// Invalid kustomizations are reported by their validation
+		"broken/kustomization.yaml": []byte("patches: [\n"),
+	})
+	expectedPatches := map[string]bool{
+		"overlays/dev/replicas.yaml":          true,
+		"overlays/dev/patches/resources.yaml": true,
+	}
+	expectedIgnored := map[string]bool{
+		"overlays/dev/patches/ops.yaml":       true,
+		"overlays/dev/application.properties": true,
+		"overlays/dev/files/config.json":      true,
+		"overlays/dev/.env":                   true,
+		"overlays/dev/secrets.env":            true,
+	}
+	if !reflect.DeepEqual(patches, expectedPatches) {
+		t.Errorf("expected patches %v, got %v", expectedPatches, patches)
+	}
+	if !reflect.DeepEqual(ignored, expectedIgnored) {
+		t.Errorf("expected ignored %v, got %v", expectedIgnored, ignored)
+	}
+}
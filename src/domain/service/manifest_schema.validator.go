@@ -0,0 +1,288 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// manifestSchema is the subset of the OpenAPI schemas used by the embedded
+// definitions. An object with properties doesn't accept other fields, unless
+// it preserves the unknown fields, and an object without them accepts any
+// field with the additionalProperties schema.
+type manifestSchema struct {
+	Ref                   string                     `json:"$ref"`
+	Type                  schemaTypes                `json:"type"`
+	Properties            map[string]*manifestSchema `json:"properties"`
+	Required              []string                   `json:"required"`
+	AdditionalProperties  *manifestSchema            `json:"additionalProperties"`
+	Items                 *manifestSchema            `json:"items"`
+	Enum                  []string                   `json:"enum"`
+	Pattern               string                     `json:"pattern"`
+	Minimum               *float64                   `json:"minimum"`
+	Maximum               *float64                   `json:"maximum"`
+	IntOrString           bool                       `json:"x-kubernetes-int-or-string"`
+	PreserveUnknownFields bool                       `json:"x-kubernetes-preserve-unknown-fields"`
+	GroupVersionKind      []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind"`
+	ref     *manifestSchema
+	pattern *regexp.Regexp
+}
+
+// schemaTypes accepts a type or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var types []string
+	if err := json.Unmarshal(data, &types); err == nil {
+		*t = types
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*t = schemaTypes{single}
+	return nil
+}
+
+// loadManifestSchemas reads the definitions of every embedded file, the refs
+// are resolved among all of them, and returns the resources by apiVersion and
+// kind.
+func loadManifestSchemas() (map[string]*manifestSchema, error) {
+	definitions := make(map[string]*manifestSchema)
+	files, err := fs.Glob(manifestSchemas, "schemas/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := manifestSchemas.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var spec struct {
+			Definitions map[string]*manifestSchema `json:"definitions"`
+		}
+		if err := json.Unmarshal(content, &spec); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for name, s := range spec.Definitions {
+			if _, ok := definitions[name]; ok {
+				return nil, fmt.Errorf("%s: definition %s is repeated", file, name)
+			}
+			definitions[name] = s
+		}
+	}
+	schemas := make(map[string]*manifestSchema)
+	for name, s := range definitions {
+		if err := s.resolve(definitions); err != nil {
+			return nil, fmt.Errorf("definition %s: %w", name, err)
+		}
+		for _, gvk := range s.GroupVersionKind {
+			apiVersion := gvk.Version
+			if gvk.Group != "" {
+				apiVersion = gvk.Group + "/" + gvk.Version
+			}
+			schemas[apiVersion+"/"+gvk.Kind] = s
+		}
+	}
+	return schemas, nil
+}
+
+func (s *manifestSchema) resolve(definitions map[string]*manifestSchema) error {
+	if s.Ref != "" {
+		ref, ok := definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			return fmt.Errorf("ref %s not found", s.Ref)
+		}
+		s.ref = ref
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = pattern
+	}
+	children := []*manifestSchema{s.AdditionalProperties, s.Items}
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		if err := c.resolve(definitions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accepts tells if the node has one of the types of the schema, a number
+// accepts integers.
+func (s *manifestSchema) accepts(nodeType string) bool {
+	if s.IntOrString {
+		return nodeType == "integer" || nodeType == "string"
+	}
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, t := range s.Type {
+		if t == nodeType || (t == "number" && nodeType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *manifestSchema) typeLabel() string {
+	if s.IntOrString {
+		return "integer or string"
+	}
+	return strings.Join(s.Type, " or ")
+}
+
+type manifestValidator struct {
+	file string
+	// patch skips the required fields, they are on the patched resource
+	patch      bool
+	violations []ManifestViolation
+}
+
+func (v *manifestValidator) validate(s *manifestSchema, node *yaml.Node, field string) {
+	for s.ref != nil {
+		s = s.ref
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	nodeType := yamlNodeType(node)
+	// A null is the field not set
+	if nodeType == "null" {
+		return
+	}
+	if !s.accepts(nodeType) {
+		v.add(node, field, fmt.Sprintf("expected %s, got %s", s.typeLabel(), nodeType))
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		v.validateObject(s, node, field)
+	case yaml.SequenceNode:
+		if s.Items == nil {
+			return
+		}
+		for k, item := range node.Content {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", field, k))
+		}
+	case yaml.ScalarNode:
+		v.validateScalar(s, node, nodeType, field)
+	}
+}
+
+func (v *manifestValidator) validateObject(s *manifestSchema, node *yaml.Node, field string) {
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" || (v.patch && strings.HasPrefix(key.Value, "$")) {
+			// Merge keys and the directives of the patches
+			continue
+		}
+		name := key.Value
+		if field != "" {
+			name = field + "." + key.Value
+		}
+		if yamlNodeType(value) != "null" {
+			set[key.Value] = true
+		}
+		if p, ok := s.Properties[key.Value]; ok {
+			v.validate(p, value, name)
+		} else if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, value, name)
+		} else if len(s.Properties) > 0 && !s.PreserveUnknownFields {
+			v.add(key, name, "unknown field")
+		}
+	}
+	if v.patch {
+		return
+	}
+	for _, r := range s.Required {
+		if !set[r] {
+			name := r
+			if field != "" {
+				name = field + "." + r
+			}
+			v.add(node, name, "field is required")
+		}
+	}
+}
+
+func (v *manifestValidator) validateScalar(s *manifestSchema, node *yaml.Node, nodeType string, field string) {
+	if len(s.Enum) > 0 && nodeType == "string" {
+		found := false
+		for _, e := range s.Enum {
+			if e == node.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.add(node, field, fmt.Sprintf("must be one of %s, got %s", strings.Join(s.Enum, ", "), node.Value))
+		}
+	}
+	if s.pattern != nil && nodeType == "string" && !s.pattern.MatchString(node.Value) {
+		v.add(node, field, fmt.Sprintf("must match %s", s.Pattern))
+	}
+	if nodeType != "integer" && nodeType != "number" {
+		return
+	}
+	var n float64
+	if err := node.Decode(&n); err != nil {
+		return
+	}
+	if s.Minimum != nil && n < *s.Minimum {
+		v.add(node, field, fmt.Sprintf("must be at least %s", strconv.FormatFloat(*s.Minimum, 'f', -1, 64)))
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.add(node, field, fmt.Sprintf("must be at most %s", strconv.FormatFloat(*s.Maximum, 'f', -1, 64)))
+	}
+}
+
+func (v *manifestValidator) add(node *yaml.Node, field, message string) {
+	if field != "" {
+		message = field + ": " + message
+	}
+	v.violations = append(v.violations, ManifestViolation{File: v.file, Line: node.Line, Message: message})
+}
+
+// yamlNodeType is the json type of the node, the scalars use the tag yaml
+// resolved for them, so a quoted number is a string.
+func yamlNodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.AliasNode:
+		return yamlNodeType(node.Alias)
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
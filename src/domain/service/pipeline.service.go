@@ -57,6 +57,7 @@ func (g *pipelineService) LoadData(data entity.SetupCiCdEntity, manifest *entity
 type PipelineData struct {
 	Environments     map[string]*entity.PipelineEnvironment `json:"environments" yaml:"environments"`
 	DefaultVariables []*entity.PipelineVariable             `json:"defaultVariables" yaml:"defaultVariables"`
+	Params           map[string]interface{}                 `json:"params" yaml:"params"`
 }
 
 func (g *pipelineService) SetupPipeline(e entity.PipelineEntity, templatesPath, applicationPath string) error {
@@ -81,6 +82,7 @@ func (g *pipelineService) SetupPipeline(e entity.PipelineEntity, templatesPath,
 	data := PipelineData{
 		Environments:     environments,
 		DefaultVariables: e.Config().DefaultVariables,
+		Params:           e.Data().Params(),
 	}
 	return g.directoryService.ApplyTemplateRecursively(pipelinePath, data)
 }
@@ -1,7 +1,11 @@
 package service
 
-import "github.com/zahirsis/dev-portal-backend/src/domain/entity"
+import (
+	"context"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+)
 
 type RegistryApiService interface {
-	Create(entity entity.RegistryEntity) (string, error)
+	Create(ctx context.Context, entity entity.RegistryEntity) (string, bool, error)
+	Delete(ctx context.Context, entity entity.RegistryEntity) error
 }
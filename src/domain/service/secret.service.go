@@ -10,7 +10,7 @@ import (
 
 type SecretService interface {
 	LoadData(data entity.SetupCiCdEntity, manifest *entity.Manifest, templatesPath string) (entity.SecretEntity, error)
-	SetupNewSecret(secretEntity entity.SecretEntity, env entity.SetupEnvData) error
+	SetupNewSecret(secretEntity entity.SecretEntity, env entity.SetupEnvData) (bool, error)
 }
 
 type secretService struct {
@@ -38,6 +38,6 @@ func (r *secretService) LoadData(data entity.SetupCiCdEntity, manifest *entity.M
 	return entity.NewSecretEntity(data, configData, entity.DefaultTags(data)), nil
 }
 
-func (r *secretService) SetupNewSecret(secretEntity entity.SecretEntity, env entity.SetupEnvData) error {
+func (r *secretService) SetupNewSecret(secretEntity entity.SecretEntity, env entity.SetupEnvData) (bool, error) {
 	return r.api.CreateBlank(secretEntity.Config().GetRootPath(env), secretEntity.Config().GetSecretPath(env))
 }
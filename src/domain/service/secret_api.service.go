@@ -1,5 +1,9 @@
 package service
 
 type SecretApiService interface {
-	CreateBlank(location, path string) error
+	CreateBlank(location, path string) (bool, error)
+	// Delete destroys every version of the secret and its metadata.
+	Delete(location, path string) error
+	// SoftDelete deletes the latest version of the secret, it can be undeleted.
+	SoftDelete(location, path string) error
 }
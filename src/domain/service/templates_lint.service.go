@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// TemplatesLintService checks a checkout of the templates repository before
+// its changes reach the setups: the config of every manifest must match its
+// entity, the paths it references must exist and its templates must render.
+type TemplatesLintService interface {
+	Lint(templatesPath string, templates []entity.TemplateEntity) []error
+}
+
+type templatesLintService struct {
+	logger           logger.Logger
+	directoryService DirectoryService
+}
+
+func NewTemplatesLintService(logger logger.Logger, directoryService DirectoryService) TemplatesLintService {
+	return &templatesLintService{
+		logger:           logger,
+		directoryService: directoryService,
+	}
+}
+
+// Lint checks the manifests of the templates once, they are shared by most
+// templates, with the params of every template using them.
+func (l *templatesLintService) Lint(templatesPath string, templates []entity.TemplateEntity) []error {
+	var errs []error
+	var manifests []*entity.Manifest
+	params := make(map[string][]map[string]interface{})
+	for _, t := range templates {
+		for _, m := range t.Manifests() {
+			key := string(m.Type) + ":" + m.Dir
+			if _, ok := params[key]; !ok {
+				manifests = append(manifests, m)
+			}
+			params[key] = append(params[key], lintParams(t.Params())...)
+		}
+	}
+	for _, m := range manifests {
+		l.logger.Debug("Linting manifest", m.Code, m.Dir)
+		for _, err := range l.lintManifest(templatesPath, m, params[string(m.Type)+":"+m.Dir]) {
+			errs = append(errs, fmt.Errorf("manifest %s (%s): %w", m.Code, m.Dir, err))
+		}
+	}
+	return errs
+}
+
+func (l *templatesLintService) lintManifest(templatesPath string, m *entity.Manifest, params []map[string]interface{}) []error {
+	configPath := filepath.Join(templatesPath, m.Dir, "config.yaml")
+	switch m.Type {
+	case entity.GitOpsManifests:
+		cfg := &entity.GitOpsConfig{}
+		if err := readStrictConfig(configPath, cfg); err != nil {
+			return []error{err}
+		}
+		return l.lintGitOps(templatesPath, cfg, params)
+	case entity.PipelineManifests:
+		cfg := &entity.PipelineConfig{}
+		if err := readStrictConfig(configPath, cfg); err != nil {
+			return []error{err}
+		}
+		return l.lintPipeline(templatesPath, cfg, params)
+	case entity.RegistryManifests:
+		if err := readStrictConfig(configPath, &entity.RegistryConfig{}); err != nil {
+			return []error{err}
+		}
+		return l.lintRegistry(filepath.Join(templatesPath, m.Dir, "policy.json"))
+	case entity.SecretManifests:
+		if err := readStrictConfig(configPath, &entity.SecretConfig{}); err != nil {
+			return []error{err}
+		}
+		return nil
+	case entity.WikiManifests:
+		cfg := &entity.WikiConfig{}
+		if err := readStrictConfig(configPath, cfg); err != nil {
+			return []error{err}
+		}
+		return l.lintWiki(templatesPath, cfg)
+	}
+	return []error{fmt.Errorf("unknown manifest type %s", m.Type)}
+}
+
+// lintParams returns the params of a template with their default values, as
+// a setup not giving them has, and with their zero values.
+func lintParams(params []*entity.TemplateParam) []map[string]interface{} {
+	defaults := make(map[string]interface{})
+	zeros := make(map[string]interface{})
+	for _, p := range params {
+		defaults[p.Name] = p.Zero()
+		if p.Default != nil {
+			defaults[p.Name] = p.Default
+		}
+		zeros[p.Name] = p.Zero()
+	}
+	return []map[string]interface{}{defaults, zeros}
+}
+
+// readStrictConfig reads a config like the services do, but a field the
+// config doesn't have is an error instead of being ignored.
+func readStrictConfig(path string, out interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return nil
+}
+
+func (l *templatesLintService) lintGitOps(templatesPath string, cfg *entity.GitOpsConfig, params []map[string]interface{}) []error {
+	var errs []error
+	var namespace []interface{}
+	for _, p := range params {
+		namespace = append(namespace, &NamespaceUtilitiesData{Namespace: "squad", Params: p})
+	}
+	for _, path := range []struct {
+		name     string
+		value    string
+		file     bool
+		optional bool
+		values   []interface{}
+	}{
+		// The base utilities are copied without rendering them
+		{"k8sBaseTemplatesPath", cfg.K8sBaseTemplatesPath, false, false, nil},
+		{"k8sNamespaceUtilitiesTemplatesPath", cfg.K8sNamespaceUtilitiesTemplatesPath, false, false, namespace},
+		{"k8sApplicationTemplatesPath/base", joinConfigPath(cfg.K8sApplicationTemplatesPath, "base"), false, false, lintApplicationData(params)},
+		{"k8sApplicationTemplatesPath/overlays/overlay", joinConfigPath(cfg.K8sApplicationTemplatesPath, "overlays/overlay"), false, false, lintApplicationData(params)},
+		// The config maps are only used when they are external
+		{"k8sConfigMapTemplatesPath/overlay", joinConfigPath(cfg.K8sConfigMapTemplatesPath, "overlay"), false, true, lintApplicationData(params)},
+		{"gitOpsKustomizationTemplatePath", cfg.GitOpsKustomizationTemplatePath, true, false, nil},
+		{"gitOpsAppTemplatesPath", cfg.GitOpsAppTemplatesPath, true, false, lintGitOpsManifestsData(params)},
+		{"gitOpsAppNamespaceUtilitiesTemplatesPath", cfg.GitOpsAppNamespaceUtilitiesTemplatesPath, true, false, lintGitOpsManifestsData(params)},
+	} {
+		if path.value == "" && path.optional {
+			continue
+		} else if path.value == "" {
+			errs = append(errs, fmt.Errorf("%s is empty", path.name))
+			continue
+		}
+		fullPath := filepath.Join(templatesPath, path.value)
+		if err := checkConfigPath(fullPath, path.file); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path.name, err))
+			continue
+		}
+		errs = append(errs, l.render(fullPath, path.values, false, true)...)
+	}
+	for _, path := range []struct{ name, value string }{
+		{"k8sBaseDestinationPath", cfg.K8sBaseDestinationPath},
+		{"k8sNamespaceUtilitiesDestinationPath", cfg.K8sNamespaceUtilitiesDestinationPath},
+		{"k8sApplicationDestinationPath", cfg.K8sApplicationDestinationPath},
+		{"gitOpsBaseDestinationPath", cfg.GitOpsBaseDestinationPath},
+	} {
+		if path.value == "" {
+			errs = append(errs, fmt.Errorf("%s is empty", path.name))
+		}
+	}
+	return errs
+}
+
+func (l *templatesLintService) lintPipeline(templatesPath string, cfg *entity.PipelineConfig, params []map[string]interface{}) []error {
+	if cfg.TemplatesPath == "" || cfg.DestinationPath == "" {
+		return []error{errors.New("templatesPath and destinationPath cannot be empty")}
+	}
+	path := filepath.Join(templatesPath, cfg.TemplatesPath)
+	if _, err := os.Stat(path); err != nil {
+		return []error{fmt.Errorf("templatesPath: %w", err)}
+	}
+	// The pipeline is rendered with the configured environments the
+	// application has, all of them or none. Without them the environments
+	// are missing keys, so that one isn't strict.
+	var values []interface{}
+	for _, p := range params {
+		values = append(values, PipelineData{Environments: cfg.Environments, DefaultVariables: cfg.DefaultVariables, Params: p})
+	}
+	errs := l.render(path, values, false, true)
+	if len(errs) > 0 {
+		return errs
+	}
+	return l.render(path, []interface{}{
+		PipelineData{Environments: map[string]*entity.PipelineEnvironment{}, DefaultVariables: cfg.DefaultVariables, Params: params[0]},
+	}, false, false)
+}
+
+func (l *templatesLintService) lintRegistry(policyPath string) []error {
+	policy, err := os.ReadFile(policyPath)
+	if err != nil {
+		return []error{err}
+	}
+	if !json.Valid(policy) {
+		return []error{fmt.Errorf("invalid policy %s", policyPath)}
+	}
+	return nil
+}
+
+func (l *templatesLintService) lintWiki(templatesPath string, cfg *entity.WikiConfig) []error {
+	var errs []error
+	for _, path := range []struct {
+		name   string
+		value  string
+		values []interface{}
+	}{
+		{"templateServicePath", cfg.TemplateServicePath, lintWikiServiceData()},
+		{"templatePagePath", cfg.TemplatePagePath, []interface{}{
+			&WikiPagesData{Pages: []*PageList{{Id: "1", ParentId: "0", Title: "[SQUAD] APPLICATION", Link: "https://wiki.example.com/1"}}},
+			&WikiPagesData{Pages: []*PageList{}},
+		}},
+	} {
+		if path.value == "" {
+			errs = append(errs, fmt.Errorf("%s is empty", path.name))
+			continue
+		}
+		fullPath := filepath.Join(templatesPath, path.value)
+		if err := checkConfigPath(fullPath, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path.name, err))
+			continue
+		}
+		errs = append(errs, l.render(fullPath, path.values, true, true)...)
+	}
+	if cfg.SpaceId == "" || cfg.ServicesPageId == "" {
+		errs = append(errs, errors.New("spaceId and servicesPageId cannot be empty"))
+	}
+	return errs
+}
+
+// render loads every file under the path with each of the values, without
+// changing them. A strict render fails on the keys missing on the maps of the
+// values, like a param the template doesn't declare.
+func (l *templatesLintService) render(path string, values []interface{}, html bool, strict bool) []error {
+	var errs []error
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		for _, v := range values {
+			load := l.directoryService.LoadTemplate
+			if strict {
+				load = l.directoryService.LoadStrictTemplate
+			}
+			if _, err := load(file, v, html); err != nil {
+				errs = append(errs, fmt.Errorf("error rendering %s with %T: %w", file, v, err))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func joinConfigPath(path, dir string) string {
+	if path == "" {
+		return ""
+	}
+	return path + "/" + dir
+}
+
+func checkConfigPath(path string, file bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if file && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	} else if !file && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// lintApplicationData returns an application with every value set and
+// another one with the optional values empty, so both branches of the
+// conditionals are rendered, for each of the params.
+func lintApplicationData(params []map[string]interface{}) []interface{} {
+	var values []interface{}
+	for _, p := range params {
+		values = append(values, &ApplicationData{
+			Namespace:                           "squad",
+			ApplicationName:                     "application",
+			ApplicationPort:                     8080,
+			ApplicationCpuLimit:                 "500m",
+			ApplicationMemoryLimit:              "512Mi",
+			ApplicationCpuRequest:               "250m",
+			ApplicationMemoryRequest:            "256Mi",
+			ApplicationHealthCheckPath:          "/health",
+			ApplicationInitialDelaySeconds:      30,
+			ApplicationSecondDelaySeconds:       10,
+			ApplicationHealthCheckPeriodSeconds: 10,
+			IngressStripPath:                    true,
+			IngressAuthentication:               true,
+			IngressFrontend:                     true,
+			IngressCustomPath:                   "/custom",
+			IngressHost:                         "application.example.com",
+			IngressPath:                         "/application",
+			DefaultImageName:                    "image",
+			DefaultImageTag:                     "latest",
+			ApplicationMinReplicas:              1,
+			ApplicationMaxReplicas:              2,
+			EnvironmentMountPath:                "secrets/environment",
+			Params:                              p,
+		}, &ApplicationData{
+			Namespace:              "squad",
+			ApplicationName:        "application",
+			ApplicationPort:        8080,
+			ApplicationCpuLimit:    "500m",
+			ApplicationMemoryLimit: "512Mi",
+			DefaultImageName:       "image",
+			DefaultImageTag:        "latest",
+			Params:                 p,
+		})
+	}
+	return values
+}
+
+func lintGitOpsManifestsData(params []map[string]interface{}) []interface{} {
+	var values []interface{}
+	for _, p := range params {
+		values = append(values, &GitOpsManifestsData{
+			Namespace:                 "squad",
+			Squad:                     "squad",
+			SquadOwners:               []string{"owner@example.com"},
+			SquadSlackChannel:         "squad",
+			SquadCostCenter:           "cost-center",
+			ApplicationName:           "application",
+			Environment:               "environment",
+			DestinationCluster:        "https://kubernetes.default.svc",
+			Project:                   "project",
+			K8sApplicationPath:        "k8s/squad/application/overlays/environment",
+			K8sNamespaceUtilitiesPath: "k8s/squad/_utilities/overlays/environment",
+			GitOpsToolsRepository:     "https://git.example.com/gitops-tools.git",
+			GitOpsRepository:          "https://git.example.com/gitops.git",
+			ConfigMapPath:             "config-maps/squad/application/environment",
+			ConfigMapRepository:       "https://git.example.com/config-maps.git",
+			Params:                    p,
+		}, &GitOpsManifestsData{
+			Namespace:                 "squad",
+			Squad:                     "squad",
+			ApplicationName:           "application",
+			Environment:               "environment",
+			DestinationCluster:        "https://kubernetes.default.svc",
+			Project:                   "project",
+			K8sApplicationPath:        "k8s/squad/application/overlays/environment",
+			K8sNamespaceUtilitiesPath: "k8s/squad/_utilities/overlays/environment",
+			GitOpsToolsRepository:     "https://git.example.com/gitops-tools.git",
+			GitOpsRepository:          "https://git.example.com/gitops.git",
+			Params:                    p,
+		})
+	}
+	return values
+}
+
+func lintWikiServiceData() []interface{} {
+	return []interface{}{
+		&WikiServiceData{
+			ApplicationName: "application",
+			GitRepository:   "https://git.example.com/application.git",
+			Squad:           "Squad",
+			Owners:          []string{"owner@example.com"},
+			SlackChannel:    "squad",
+			CostCenter:      "cost-center",
+			Environments: []*entity.EnvironmentCreatedData{
+				{Label: "Environment", Code: "environment", Url: "https://application.example.com", ApplicationName: "application"},
+			},
+			Exposed:             true,
+			HealthCheck:         "/health",
+			Port:                8080,
+			RegistryUrl:         "registry.example.com/application",
+			GitOpsUrl:           "https://git.example.com/gitops/k8s/squad/application",
+			ConfigMapUrl:        "https://git.example.com/config-maps/squad/application",
+			Template:            "Template",
+			TemplatesRepository: "https://git.example.com/devportal-templates.git",
+			TemplatesCommit:     "0123456789abcdef0123456789abcdef01234567",
+			ServiceWikiLink:     "https://wiki.example.com/1",
+		},
+		&WikiServiceData{
+			ApplicationName: "application",
+			GitRepository:   "https://git.example.com/application.git",
+			Squad:           "Squad",
+			Environments:    []*entity.EnvironmentCreatedData{},
+			Template:        "Template",
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"github.com/zahirsis/dev-portal-backend/pkg/log_logger"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// templateDirectoryService renders the templates like the unix service.
+type templateDirectoryService struct {
+	osDirectoryService
+}
+
+func (d templateDirectoryService) LoadTemplate(path string, values interface{}, _ bool) ([]byte, error) {
+	return d.load(path, values, "missingkey=default")
+}
+
+func (d templateDirectoryService) LoadStrictTemplate(path string, values interface{}, _ bool) ([]byte, error) {
+	return d.load(path, values, "missingkey=error")
+}
+
+func (templateDirectoryService) load(path string, values interface{}, option string) ([]byte, error) {
+	t, err := template.ParseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Option(option).Execute(&buf, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func TestLintParams(t *testing.T) {
+	pipeline := `
+{{- if .Environments.prd }}
+deploy: {{ .Params.javaVersion }}
+{{- end }}
+debug: {{ if .Params.debug }}on{{ end }}
+`
+	tests := []struct {
+		name   string
+		params []*entity.TemplateParam
+		err    string
+	}{
+		{
+			name: "declared params",
+			params: []*entity.TemplateParam{
+				{Name: "javaVersion", Type: entity.StringParam, Default: "17"},
+				{Name: "debug", Type: entity.BooleanParam},
+			},
+		},
+		{
+			name:   "undeclared param",
+			params: []*entity.TemplateParam{{Name: "javaVersion", Type: entity.StringParam}},
+			err:    `map has no entry for key "debug"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFixtures(t, root, map[string]string{
+				"manifests/pipeline/config.yaml": `
+templatesPath: templates/pipeline
+destinationPath: .
+environments:
+  prd: {}
+`,
+				"templates/pipeline/pipeline.yml": pipeline,
+			})
+			manifest := &entity.Manifest{Code: "pipeline", Type: entity.PipelineManifests, Dir: "manifests/pipeline"}
+			templates := []entity.TemplateEntity{
+				entity.NewTemplateEntity("api", "API", entity.ApplicationObject{}, entity.IngressObject{}, []*entity.Manifest{manifest}, "", tt.params),
+			}
+			l := NewTemplatesLintService(log_logger.New(log.New(io.Discard, "", 0), &logger.Config{Level: logger.Fatal}), templateDirectoryService{})
+			errs := l.Lint(root, templates)
+			if tt.err == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, errs)
+			}
+		})
+	}
+}
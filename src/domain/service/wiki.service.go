@@ -12,7 +12,11 @@ import (
 
 type WikiService interface {
 	LoadData(data entity.SetupCiCdEntity, v *entity.Manifest, dir string) (entity.WikiEntity, error)
-	SetupWiki(wiki entity.WikiEntity, templatesPath string) ([]string, error)
+	// SetupWiki creates the service page and returns its id and url.
+	SetupWiki(wiki entity.WikiEntity, templatesPath string) (string, string, error)
+	RenderServicePage(wiki entity.WikiEntity, templatesPath string) (string, []byte, error)
+	ArchiveServicePage(wiki entity.WikiEntity, pageId string, templatesPath string) ([]string, error)
+	RefreshServicePage(wiki entity.WikiEntity, pageId string, templatesPath string) ([]string, error)
 }
 
 type wikiService struct {
@@ -54,6 +58,9 @@ type WikiServiceData struct {
 	ApplicationName string
 	GitRepository   string
 	Squad           string
+	Owners          []string
+	SlackChannel    string
+	CostCenter      string
 	Environments    []*entity.EnvironmentCreatedData
 	Exposed         bool
 	HealthCheck     string
@@ -62,51 +69,140 @@ type WikiServiceData struct {
 	GitOpsUrl       string
 	ConfigMapUrl    string
 	Template        string
-	ServiceWikiLink string
+	// TemplatesRepository and TemplatesCommit tell what the service was
+	// generated from
+	TemplatesRepository string
+	TemplatesCommit     string
+	ServiceWikiLink     string
 }
 
 type WikiPagesData struct {
 	Pages []*PageList
 }
 
-func (g *wikiService) SetupWiki(wiki entity.WikiEntity, templatesPath string) ([]string, error) {
-	data := wiki.Data().CreatedData()
-	wsd := &WikiServiceData{
-		ApplicationName: wiki.Data().ApplicationName(),
-		GitRepository:   g.config.GitConfig.GetRepositoryUrl(wiki.Data().ApplicationName()),
-		Squad:           wiki.Data().Squad().Label(),
-		Environments:    data.Environments,
-		Exposed:         wiki.Data().Template().IngressDefault().Enabled,
-		HealthCheck:     wiki.Data().ApplicationHealthCheckPath(),
-		Port:            wiki.Data().ApplicationPort(),
-		GitOpsUrl:       data.GitOpsPath,
-		ConfigMapUrl:    data.ConfigMapPath,
-		Template:        wiki.Data().Template().Label(),
-		RegistryUrl:     data.RegistryUrl,
-		ServiceWikiLink: "",
+func (g *wikiService) SetupWiki(wiki entity.WikiEntity, templatesPath string) (string, string, error) {
+	title, c, err := g.RenderServicePage(wiki, templatesPath)
+	if err != nil {
+		return "", "", err
 	}
-	c, err := g.ds.LoadTemplate(fmt.Sprintf("%s/%s", templatesPath, wiki.Config().TemplateServicePath), wsd, true)
+	id, url, err := g.api.CreatePage(title, wiki.Config().SpaceId, wiki.Config().ServicesPageId, c)
+	if err != nil {
+		return "", "", err
+	}
+	if err := g.updateServicesPage(wiki, templatesPath, "Add "+title); err != nil {
+		return id, url, err
+	}
+	return id, url, nil
+}
+
+// ArchiveServicePage moves the service page to the archive page, or archives
+// it when there is no archive page, and removes it from the services page.
+// The page is looked up by its title when its id is unknown.
+func (g *wikiService) ArchiveServicePage(wiki entity.WikiEntity, pageId string, templatesPath string) ([]string, error) {
+	title := g.servicePageTitle(wiki)
+	pageId, err := g.findServicePage(wiki, pageId)
 	if err != nil {
 		return []string{}, err
 	}
-	title := fmt.Sprintf("[%s] %s", strings.ToUpper(wiki.Data().Squad().Label()), strings.ToTitle(wiki.Data().ApplicationSlug()))
-	url, err := g.api.CreatePage(title, wiki.Config().SpaceId, wiki.Config().ServicesPageId, c)
+	if pageId == "" {
+		return []string{fmt.Sprintf("Page %s not found", title)}, nil
+	}
+	var output []string
+	if wiki.Config().ArchivePageId != "" {
+		if err := g.api.MovePage(pageId, wiki.Config().ArchivePageId); err != nil {
+			return []string{}, err
+		}
+		output = append(output, fmt.Sprintf("Page %s moved to archive", title))
+	} else {
+		if err := g.api.ArchivePage(pageId); err != nil {
+			return []string{}, err
+		}
+		output = append(output, fmt.Sprintf("Page %s archived", title))
+	}
+	if err := g.updateServicesPage(wiki, templatesPath, "Remove "+title); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// RefreshServicePage renders the service page again with the current data of
+// the service. The page is looked up by its title when its id is unknown.
+func (g *wikiService) RefreshServicePage(wiki entity.WikiEntity, pageId string, templatesPath string) ([]string, error) {
+	title, c, err := g.RenderServicePage(wiki, templatesPath)
 	if err != nil {
 		return []string{}, err
 	}
-	list, err := g.api.ListSubPages(wiki.Config().SpaceId, wiki.Config().ServicesPageId)
+	pageId, err = g.findServicePage(wiki, pageId)
 	if err != nil {
 		return []string{}, err
 	}
+	if pageId == "" {
+		return []string{fmt.Sprintf("Page %s not found", title)}, nil
+	}
+	if err := g.api.UpdatePage(pageId, c, "Update "+title); err != nil {
+		return []string{}, err
+	}
+	return []string{fmt.Sprintf("Page %s updated", title)}, nil
+}
+
+func (g *wikiService) findServicePage(wiki entity.WikiEntity, pageId string) (string, error) {
+	if pageId != "" {
+		return pageId, nil
+	}
+	list, err := g.api.ListSubPages(wiki.Config().SpaceId, wiki.Config().ServicesPageId)
+	if err != nil {
+		return "", err
+	}
+	title := g.servicePageTitle(wiki)
+	for _, p := range list {
+		if p.Title == title {
+			return p.Id, nil
+		}
+	}
+	return "", nil
+}
+
+func (g *wikiService) updateServicesPage(wiki entity.WikiEntity, templatesPath, message string) error {
+	list, err := g.api.ListSubPages(wiki.Config().SpaceId, wiki.Config().ServicesPageId)
+	if err != nil {
+		return err
+	}
 	wpd := &WikiPagesData{Pages: list}
-	c, err = g.ds.LoadTemplate(fmt.Sprintf("%s/%s", templatesPath, wiki.Config().TemplatePagePath), wpd, true)
+	c, err := g.ds.LoadTemplate(fmt.Sprintf("%s/%s", templatesPath, wiki.Config().TemplatePagePath), wpd, true)
 	if err != nil {
-		return []string{}, err
+		return err
+	}
+	return g.api.UpdatePage(wiki.Config().ServicesPageId, c, message)
+}
+
+func (g *wikiService) servicePageTitle(wiki entity.WikiEntity) string {
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(wiki.Data().Squad().Label()), strings.ToTitle(wiki.Data().ApplicationSlug()))
+}
+
+func (g *wikiService) RenderServicePage(wiki entity.WikiEntity, templatesPath string) (string, []byte, error) {
+	data := wiki.Data().CreatedData()
+	wsd := &WikiServiceData{
+		ApplicationName:     wiki.Data().ApplicationName(),
+		GitRepository:       g.config.GitConfig.GetRepositoryUrl(wiki.Data().ApplicationName()),
+		Squad:               wiki.Data().Squad().Label(),
+		Owners:              wiki.Data().Squad().Owners(),
+		SlackChannel:        wiki.Data().Squad().SlackChannel(),
+		CostCenter:          wiki.Data().Squad().CostCenter(),
+		Environments:        data.Environments,
+		Exposed:             wiki.Data().Template().IngressDefault().Enabled,
+		HealthCheck:         wiki.Data().ApplicationHealthCheckPath(),
+		Port:                wiki.Data().ApplicationPort(),
+		GitOpsUrl:           data.GitOpsPath,
+		ConfigMapUrl:        data.ConfigMapPath,
+		Template:            wiki.Data().Template().Label(),
+		RegistryUrl:         data.RegistryUrl,
+		TemplatesRepository: g.config.GitConfig.GetRepositoryUrl(g.config.SetupCiCd.TemplatesRepository),
+		TemplatesCommit:     data.TemplatesCommit,
+		ServiceWikiLink:     "",
 	}
-	message := fmt.Sprintf("Add [%s] %s", strings.ToUpper(wiki.Data().Squad().Label()), strings.ToTitle(wiki.Data().ApplicationSlug()))
-	err = g.api.UpdatePage(wiki.Config().ServicesPageId, c, message)
+	c, err := g.ds.LoadTemplate(fmt.Sprintf("%s/%s", templatesPath, wiki.Config().TemplateServicePath), wsd, true)
 	if err != nil {
-		return []string{}, err
+		return "", nil, err
 	}
-	return []string{url}, nil
+	return g.servicePageTitle(wiki), c, nil
 }
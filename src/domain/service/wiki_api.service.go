@@ -8,7 +8,9 @@ type PageList struct {
 }
 
 type WikiApiService interface {
-	CreatePage(title, space, parent string, content []byte) (url string, err error)
+	CreatePage(title, space, parent string, content []byte) (id string, url string, err error)
 	ListSubPages(space, parent string) ([]*PageList, error)
 	UpdatePage(Id string, content []byte, updateMessage string) error
+	ArchivePage(Id string) error
+	MovePage(Id string, parent string) error
 }
@@ -0,0 +1,274 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zahirsis/dev-portal-backend/config"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
+	"github.com/zahirsis/dev-portal-backend/src/domain/service"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ErrTemplateNotFound = "template not found"
+	refreshTimeout      = 5 * time.Minute
+)
+
+// templatesDescriptor is the file listing the templates, its manifests are
+// shared by every template:
+//
+//	manifests:
+//	  - code: argo-cd
+//	    label: Argo manifests
+//	    type: gitOps
+//	    dir: manifests/git-ops/argo-cd
+//	templates:
+//	  - code: spring-boot
+//	    label: SpringBoot
+//	    application: {...}
+//	    ingress: {...}
+//	    manifests: [...]
+//	    params:
+//	      - name: javaVersion
+//	        type: string
+//	        default: "17"
+//	        enum: ["17", "21"]
+type templatesDescriptor struct {
+	Manifests []*entity.Manifest   `yaml:"manifests"`
+	Templates []templateDescriptor `yaml:"templates"`
+}
+
+// templateDescriptor describes a template, either as an item of the
+// templates descriptor or as a file of the descriptors directory.
+type templateDescriptor struct {
+	Code        string                   `yaml:"code"`
+	Label       string                   `yaml:"label"`
+	Application entity.ApplicationObject `yaml:"application"`
+	Ingress     entity.IngressObject     `yaml:"ingress"`
+	Manifests   []*entity.Manifest       `yaml:"manifests"`
+	// TemplatesRef pins the template to a tag or commit of the repository
+	TemplatesRef string `yaml:"templatesRef"`
+	// Params are the inputs of the setup the manifests use
+	Params []*entity.TemplateParam `yaml:"params"`
+}
+
+// templateRepository reads the templates from a checkout of the templates
+// repository, they are cached until the next refresh.
+type templateRepository struct {
+	logger    logger.Logger
+	cfg       *config.SetupCiCdConfig
+	git       service.GitService
+	refresh   sync.Mutex
+	mutex     sync.RWMutex
+	templates []entity.TemplateEntity
+	err       error
+}
+
+func NewTemplateRepository(logger logger.Logger, cfg *config.SetupCiCdConfig, git service.GitService) repository.TemplateRepository {
+	return &templateRepository{
+		logger: logger,
+		cfg:    cfg,
+		git:    git,
+	}
+}
+
+// ReadTemplates reads the templates of the checkout on the cache dir of the
+// config as it is, without pulling it.
+func ReadTemplates(logger logger.Logger, cfg *config.SetupCiCdConfig) ([]entity.TemplateEntity, error) {
+	r := &templateRepository{logger: logger, cfg: cfg}
+	return r.load()
+}
+
+func (r *templateRepository) List() ([]entity.TemplateEntity, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.templates == nil && r.err != nil {
+		return nil, fmt.Errorf("templates not loaded: %w", r.err)
+	} else if r.templates == nil {
+		return nil, errors.New("templates not loaded yet")
+	}
+	return r.templates, nil
+}
+
+func (r *templateRepository) Get(code string) (entity.TemplateEntity, error) {
+	templates, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range templates {
+		if v.Code() == code {
+			return v, nil
+		}
+	}
+	return nil, errors.New(ErrTemplateNotFound)
+}
+
+// Read reads the templates of the checkout on dir, without caching them, and
+// returns the one of the code.
+func (r *templateRepository) Read(code string, dir string) (entity.TemplateEntity, error) {
+	cfg := *r.cfg
+	cfg.TemplatesCacheDir = dir
+	templates, err := (&templateRepository{logger: r.logger, cfg: &cfg}).load()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range templates {
+		if v.Code() == code {
+			return v, nil
+		}
+	}
+	return nil, errors.New(ErrTemplateNotFound)
+}
+
+// Refresh updates the checkout of the templates repository and reads the
+// templates again, the templates already loaded are kept when it fails.
+func (r *templateRepository) Refresh() error {
+	r.refresh.Lock()
+	defer r.refresh.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	var templates []entity.TemplateEntity
+	err := r.checkout(ctx)
+	if err == nil {
+		templates, err = r.load()
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil {
+		r.err = err
+		return err
+	}
+	r.logger.Debug("Templates loaded", len(templates))
+	r.templates = templates
+	r.err = nil
+	return nil
+}
+
+// checkout pulls the templates repository on the cache directory, cloning it
+// again when the pull fails.
+func (r *templateRepository) checkout(ctx context.Context) error {
+	dir := r.cfg.TemplatesCacheDir
+	if _, err := os.Stat(dir + "/.git"); err == nil {
+		if err := r.git.Pull(ctx, dir, r.cfg.TemplatesRepositoryBranch); err == nil {
+			return nil
+		}
+		r.logger.Warning("Error pulling templates repository, cloning it again", dir)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return r.git.CloneRepository(ctx, r.cfg.TemplatesRepository, r.cfg.TemplatesRepositoryBranch, dir)
+}
+
+// load reads the templates descriptor, or every descriptor of the templates
+// when it's a directory.
+func (r *templateRepository) load() ([]entity.TemplateEntity, error) {
+	path := filepath.Join(r.cfg.TemplatesCacheDir, r.cfg.TemplatesDescriptor)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	descriptor := templatesDescriptor{}
+	if !info.IsDir() {
+		if err := readDescriptor(path, &descriptor); err != nil {
+			return nil, err
+		}
+	} else {
+		files, err := filepath.Glob(path + "/*.y*ml")
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			template := templateDescriptor{}
+			if err := readDescriptor(file, &template); err != nil {
+				return nil, err
+			}
+			descriptor.Templates = append(descriptor.Templates, template)
+		}
+	}
+	return r.makeTemplates(descriptor, path)
+}
+
+func readDescriptor(path string, out interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// makeTemplates validates the descriptor, every template needs an unique
+// code and its manifests must exist on the templates repository.
+func (r *templateRepository) makeTemplates(descriptor templatesDescriptor, path string) ([]entity.TemplateEntity, error) {
+	var errs []error
+	codes := make(map[string]bool)
+	templates := []entity.TemplateEntity{}
+	for k, t := range descriptor.Templates {
+		if t.Code == "" {
+			errs = append(errs, fmt.Errorf("template %d has no code", k+1))
+			continue
+		}
+		if codes[t.Code] {
+			errs = append(errs, fmt.Errorf("template %s is duplicated", t.Code))
+			continue
+		}
+		codes[t.Code] = true
+		if t.Label == "" {
+			t.Label = t.Code
+		}
+		if t.TemplatesRef != "" && !entity.ValidTemplatesRef(t.TemplatesRef) {
+			errs = append(errs, fmt.Errorf("template %s has an invalid templates ref %s", t.Code, t.TemplatesRef))
+		}
+		params := make(map[string]bool)
+		for _, p := range t.Params {
+			if err := p.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("template %s: %w", t.Code, err))
+			} else if params[p.Name] {
+				errs = append(errs, fmt.Errorf("template %s: param %s is duplicated", t.Code, p.Name))
+			}
+			params[p.Name] = true
+		}
+		manifests := append(append([]*entity.Manifest{}, descriptor.Manifests...), t.Manifests...)
+		for _, m := range manifests {
+			if err := r.validateManifest(m); err != nil {
+				errs = append(errs, fmt.Errorf("template %s: %w", t.Code, err))
+			}
+		}
+		templates = append(templates, entity.NewTemplateEntity(t.Code, t.Label, t.Application, t.Ingress, manifests, t.TemplatesRef, t.Params))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid templates descriptor %s: %w", path, errors.Join(errs...))
+	}
+	return templates, nil
+}
+
+func (r *templateRepository) validateManifest(m *entity.Manifest) error {
+	if m.Code == "" {
+		return errors.New("manifest has no code")
+	}
+	switch m.Type {
+	case entity.GitOpsManifests, entity.PipelineManifests, entity.RegistryManifests, entity.WikiManifests, entity.SecretManifests:
+	default:
+		return fmt.Errorf("manifest %s has an unknown type %s", m.Code, m.Type)
+	}
+	if m.Dir == "" || strings.Contains(m.Dir, "..") {
+		return fmt.Errorf("manifest %s has an invalid dir %s", m.Code, m.Dir)
+	}
+	if info, err := os.Stat(filepath.Join(r.cfg.TemplatesCacheDir, m.Dir)); err != nil || !info.IsDir() {
+		return fmt.Errorf("manifest %s dir %s not found", m.Code, m.Dir)
+	}
+	return nil
+}
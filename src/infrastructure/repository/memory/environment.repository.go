@@ -9,6 +9,7 @@ import (
 
 const (
 	ErrEnvironmentNotFound = "environment not found"
+	ErrEnvironmentReadOnly = "environments in memory cannot be changed"
 )
 
 type environmentRepository struct {
@@ -32,6 +33,14 @@ func (r *environmentRepository) Get(code string) (entity.EnvironmentEntity, erro
 	return nil, errors.New(ErrEnvironmentNotFound)
 }
 
+func (r *environmentRepository) Save(_ entity.EnvironmentEntity) error {
+	return errors.New(ErrEnvironmentReadOnly)
+}
+
+func (r *environmentRepository) Delete(_ string) error {
+	return errors.New(ErrEnvironmentReadOnly)
+}
+
 func (r *environmentRepository) memory() []entity.EnvironmentEntity {
 	return []entity.EnvironmentEntity{
 		entity.NewEnvironmentEntity(&entity.EnvironmentConfig{
@@ -9,6 +9,7 @@ import (
 
 const (
 	ErrSquadNotFound = "squad not found"
+	ErrSquadReadOnly = "squads in memory cannot be changed"
 )
 
 type squadRepository struct {
@@ -32,16 +33,24 @@ func (r *squadRepository) Get(code string) (entity.SquadEntity, error) {
 	return nil, errors.New(ErrSquadNotFound)
 }
 
+func (r *squadRepository) Save(_ entity.SquadEntity) error {
+	return errors.New(ErrSquadReadOnly)
+}
+
+func (r *squadRepository) Delete(_ string) error {
+	return errors.New(ErrSquadReadOnly)
+}
+
 func (r *squadRepository) memory() []entity.SquadEntity {
 	return []entity.SquadEntity{
-		entity.NewSquadEntity("atendimento", "Atendimento"),
-		entity.NewSquadEntity("cca", "CCA"),
-		entity.NewSquadEntity("cco", "CCO"),
-		entity.NewSquadEntity("cd", "CD"),
-		entity.NewSquadEntity("devops", "Devops"),
-		entity.NewSquadEntity("erp-prestadores", "Erp Prestadores"),
-		entity.NewSquadEntity("mms", "MMS"),
-		entity.NewSquadEntity("processamento", "Processamento"),
-		entity.NewSquadEntity("rpa", "RPA"),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "atendimento", Label: "Atendimento"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "cca", Label: "CCA"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "cco", Label: "CCO"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "cd", Label: "CD"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "devops", Label: "Devops"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "erp-prestadores", Label: "Erp Prestadores"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "mms", Label: "MMS"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "processamento", Label: "Processamento"}),
+		entity.NewSquadEntity(&entity.SquadConfig{Code: "rpa", Label: "RPA"}),
 	}
 }
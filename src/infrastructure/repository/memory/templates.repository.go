@@ -32,6 +32,16 @@ func (r *templateRepository) Get(code string) (entity.TemplateEntity, error) {
 	return nil, errors.New(ErrTemplateNotFound)
 }
 
+// Read returns the template defined in memory, it has no checkout.
+func (r *templateRepository) Read(code string, _ string) (entity.TemplateEntity, error) {
+	return r.Get(code)
+}
+
+// Refresh does nothing, the templates are defined in memory.
+func (r *templateRepository) Refresh() error {
+	return nil
+}
+
 func (r *templateRepository) memory() []entity.TemplateEntity {
 	m := []*entity.Manifest{
 		{
@@ -114,7 +124,7 @@ func (r *templateRepository) memory() []entity.TemplateEntity {
 			Authentication: true,
 			Frontend:       false,
 			Enabled:        true,
-		}, msb),
+		}, msb, "", nil),
 		entity.NewTemplateEntity("react-js", "ReactJs", entity.ApplicationObject{
 			RootPath: entity.PathObject{
 				Default:      "/",
@@ -167,7 +177,7 @@ func (r *templateRepository) memory() []entity.TemplateEntity {
 			Authentication: false,
 			Frontend:       true,
 			Enabled:        true,
-		}, mrj),
+		}, mrj, "", nil),
 		//entity.NewTemplateEntity("node-js", "Node.Js", entity.ApplicationObject{
 		//	RootPath:        entity.PathObject{},
 		//	HealthCheckPath: entity.PathObject{},
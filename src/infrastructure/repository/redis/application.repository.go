@@ -0,0 +1,171 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+)
+
+const (
+	applicationLockPrefix = "application:LOCK:"
+	applicationDataPrefix = "application:DATA:"
+	applicationIndexes    = "application:INDEXES:"
+	applicationIndexKey   = "application:INDEX"
+	applicationQueryKey   = "application:QUERY"
+)
+
+// lockScript takes the lock when it's free or already held by the process
+// and returns the process holding it.
+var lockScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder and holder ~= ARGV[1] then
+	return holder
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return ARGV[1]
+`)
+
+// replaceLockScript moves the lock to another process only when it's still
+// held by the expected one.
+var replaceLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+return 1
+`)
+
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type applicationRepository struct {
+	logger logger.Logger
+	client *redis.Client
+}
+
+func NewApplicationRepository(logger logger.Logger, client *redis.Client) repository.ApplicationRepository {
+	return &applicationRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (a applicationRepository) Lock(slug string, processID string) (string, error) {
+	ctx := context.Background()
+	return lockScript.Run(ctx, a.client, []string{applicationLockPrefix + slug}, processID).Text()
+}
+
+func (a applicationRepository) ReplaceLock(slug string, holder string, processID string) (bool, error) {
+	ctx := context.Background()
+	return replaceLockScript.Run(ctx, a.client, []string{applicationLockPrefix + slug}, holder, processID).Bool()
+}
+
+func (a applicationRepository) Unlock(slug string, processID string) error {
+	ctx := context.Background()
+	return unlockScript.Run(ctx, a.client, []string{applicationLockPrefix + slug}, processID).Err()
+}
+
+// Save saves the application on the indexes of its filters, they have the
+// same score so the applications are listed by slug.
+func (a applicationRepository) Save(application entity.Application) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(application)
+	if err != nil {
+		return err
+	}
+	keys := append([]string{applicationDataPrefix + application.Slug, applicationIndexes + application.Slug}, applicationIndexKeys(application)...)
+	return saveIndexedScript.Run(ctx, a.client, keys, string(jsonData), 0, application.Slug).Err()
+}
+
+func (a applicationRepository) Get(slug string) (*entity.Application, error) {
+	ctx := context.Background()
+	jsonData, err := a.client.Get(ctx, applicationDataPrefix+slug).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	application := &entity.Application{}
+	if err := json.Unmarshal([]byte(jsonData), application); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// List returns the applications matching the filter, by slug, and the total
+// of applications matching it.
+func (a applicationRepository) List(filter entity.ApplicationFilter) ([]entity.Application, int, error) {
+	ctx := context.Background()
+	slugs, total, err := indexPage(ctx, a.client, applicationQueryKey, applicationFilterKeys(filter), filter.Offset, filter.Limit, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(slugs) == 0 {
+		return []entity.Application{}, total, nil
+	}
+	keys := make([]string, len(slugs))
+	for i, slug := range slugs {
+		keys[i] = applicationDataPrefix + slug
+	}
+	rows, err := a.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []entity.Application{}
+	for _, row := range rows {
+		jsonData, ok := row.(string)
+		if !ok {
+			continue
+		}
+		var application entity.Application
+		if err := json.Unmarshal([]byte(jsonData), &application); err != nil {
+			a.logger.Error("Error unmarshalling application: %s", err.Error())
+			continue
+		}
+		list = append(list, application)
+	}
+	return list, total, nil
+}
+
+func (a applicationRepository) Delete(slug string) error {
+	ctx := context.Background()
+	return deleteIndexedScript.Run(ctx, a.client, []string{applicationDataPrefix + slug, applicationIndexes + slug}, slug).Err()
+}
+
+func applicationIndexKeys(application entity.Application) []string {
+	keys := []string{applicationIndexKey}
+	if application.Squad != "" {
+		keys = append(keys, applicationIndexKey+":SQUAD:"+application.Squad)
+	}
+	if application.Template != "" {
+		keys = append(keys, applicationIndexKey+":TEMPLATE:"+application.Template)
+	}
+	for _, env := range application.Environments {
+		keys = append(keys, applicationIndexKey+":ENVIRONMENT:"+env)
+	}
+	return keys
+}
+
+func applicationFilterKeys(f entity.ApplicationFilter) []string {
+	var keys []string
+	if f.Squad != "" {
+		keys = append(keys, applicationIndexKey+":SQUAD:"+f.Squad)
+	}
+	if f.Template != "" {
+		keys = append(keys, applicationIndexKey+":TEMPLATE:"+f.Template)
+	}
+	if f.Environment != "" {
+		keys = append(keys, applicationIndexKey+":ENVIRONMENT:"+f.Environment)
+	}
+	if len(keys) == 0 {
+		keys = append(keys, applicationIndexKey)
+	}
+	return keys
+}
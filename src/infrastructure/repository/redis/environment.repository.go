@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+)
+
+const (
+	ErrEnvironmentNotFound = "environment not found"
+)
+
+const (
+	environmentDataPrefix = "environment:DATA:"
+	// environmentIndex keeps the environments in the order they were created,
+	// the order they are shown and promoted.
+	environmentIndex    = "environment:INDEX"
+	environmentSequence = "environment:SEQUENCE"
+)
+
+type environmentRepository struct {
+	logger logger.Logger
+	client *redis.Client
+}
+
+func NewEnvironmentRepository(logger logger.Logger, client *redis.Client) repository.EnvironmentRepository {
+	return &environmentRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (e environmentRepository) List() ([]entity.EnvironmentEntity, error) {
+	ctx := context.Background()
+	codes, err := e.client.ZRange(ctx, environmentIndex, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := []entity.EnvironmentEntity{}
+	if len(codes) == 0 {
+		return list, nil
+	}
+	keys := make([]string, len(codes))
+	for i, code := range codes {
+		keys[i] = environmentDataPrefix + code
+	}
+	rows, err := e.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		jsonData, ok := row.(string)
+		if !ok {
+			continue
+		}
+		environment, err := e.unmarshal(jsonData)
+		if err != nil {
+			e.logger.Error("Error unmarshalling environment: %s", err.Error())
+			continue
+		}
+		list = append(list, environment)
+	}
+	return list, nil
+}
+
+func (e environmentRepository) Get(code string) (entity.EnvironmentEntity, error) {
+	ctx := context.Background()
+	jsonData, err := e.client.Get(ctx, environmentDataPrefix+code).Result()
+	if err == redis.Nil {
+		return nil, errors.New(ErrEnvironmentNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+	return e.unmarshal(jsonData)
+}
+
+// Save keeps the position of an existing environment, a new one goes to the
+// end of the list.
+func (e environmentRepository) Save(environment entity.EnvironmentEntity) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(environment.Config())
+	if err != nil {
+		return err
+	}
+	if err := e.client.Set(ctx, environmentDataPrefix+environment.Code(), string(jsonData), 0).Err(); err != nil {
+		return err
+	}
+	if err := e.client.ZScore(ctx, environmentIndex, environment.Code()).Err(); err == nil {
+		return nil
+	} else if err != redis.Nil {
+		return err
+	}
+	position, err := e.client.Incr(ctx, environmentSequence).Result()
+	if err != nil {
+		return err
+	}
+	return e.client.ZAddNX(ctx, environmentIndex, &redis.Z{Score: float64(position), Member: environment.Code()}).Err()
+}
+
+func (e environmentRepository) Delete(code string) error {
+	ctx := context.Background()
+	_, err := e.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, environmentDataPrefix+code)
+		pipe.ZRem(ctx, environmentIndex, code)
+		return nil
+	})
+	return err
+}
+
+func (e environmentRepository) unmarshal(jsonData string) (entity.EnvironmentEntity, error) {
+	config := &entity.EnvironmentConfig{}
+	if err := json.Unmarshal([]byte(jsonData), config); err != nil {
+		return nil, err
+	}
+	return entity.NewEnvironmentEntity(config), nil
+}
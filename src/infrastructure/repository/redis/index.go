@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+)
+
+// saveIndexedScript saves a record and moves it from the indexes of its
+// previous version to the new ones, the indexes of a record are kept in a set
+// so a change of the filtered fields doesn't leave it on the old indexes.
+// KEYS are the record, the set of its indexes and the new indexes, ARGV the
+// record, its score and its member on the indexes.
+var saveIndexedScript = redis.NewScript(`
+for _, key in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+	redis.call('ZREM', key, ARGV[3])
+end
+redis.call('DEL', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1])
+for i = 3, #KEYS do
+	redis.call('ZADD', KEYS[i], ARGV[2], ARGV[3])
+	redis.call('SADD', KEYS[2], KEYS[i])
+end
+return 1
+`)
+
+// deleteIndexedScript deletes a record and removes it from its indexes.
+var deleteIndexedScript = redis.NewScript(`
+for _, key in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+	redis.call('ZREM', key, ARGV[1])
+end
+return redis.call('DEL', KEYS[1], KEYS[2])
+`)
+
+// indexPage returns a page of the members found on every index and the total
+// of them. The members are sorted by the score of the first index, descending
+// when reverse, and a limit lower than one returns all of them. The
+// intersection of the indexes is stored on queryKey and removed in the same
+// transaction.
+func indexPage(ctx context.Context, client *redis.Client, queryKey string, keys []string, offset, limit int, reverse bool) ([]string, int, error) {
+	start, stop := int64(offset), int64(-1)
+	if limit > 0 {
+		stop = int64(offset + limit - 1)
+	}
+	key := keys[0]
+	var members *redis.StringSliceCmd
+	var total *redis.IntCmd
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(keys) > 1 {
+			key = queryKey
+			weights := make([]float64, len(keys))
+			weights[0] = 1
+			pipe.ZInterStore(ctx, key, &redis.ZStore{Keys: keys, Weights: weights})
+		}
+		if reverse {
+			members = pipe.ZRevRange(ctx, key, start, stop)
+		} else {
+			members = pipe.ZRange(ctx, key, start, stop)
+		}
+		total = pipe.ZCard(ctx, key)
+		if len(keys) > 1 {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return members.Val(), int(total.Val()), nil
+}
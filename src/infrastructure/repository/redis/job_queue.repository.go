@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+	"time"
+)
+
+const (
+	jobsQueueKey      = "jobs:QUEUE"
+	jobsProcessingKey = "jobs:PROCESSING"
+	jobsReporterKey   = "jobs:REPORTER"
+	jobDataPrefix     = "jobs:DATA:"
+	jobLeasePrefix    = "jobs:LEASE:"
+	jobCancelPrefix   = "jobs:CANCEL:"
+)
+
+// dequeueScript moves the next job to the processing list and leases it in a
+// single step, so the reaper never sees a job being processed without lease.
+var dequeueScript = redis.NewScript(`
+local id = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+if not id then
+	return false
+end
+redis.call('SET', ARGV[1] .. id, '1', 'PX', ARGV[2])
+return id
+`)
+
+// requeueScript puts a job back in the queue when its lease is gone.
+var requeueScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+if redis.call('LREM', KEYS[2], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('RPUSH', KEYS[1], ARGV[1])
+return 1
+`)
+
+type jobQueueRepository struct {
+	logger logger.Logger
+	client *redis.Client
+}
+
+func NewJobQueueRepository(logger logger.Logger, client *redis.Client) repository.JobQueueRepository {
+	return &jobQueueRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (q jobQueueRepository) Enqueue(job entity.Job) error {
+	ctx := context.Background()
+	jsonJob, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, jobDataPrefix+job.ID, string(jsonJob), 0)
+		pipe.LPush(ctx, jobsQueueKey, job.ID)
+		return nil
+	})
+	return err
+}
+
+func (q jobQueueRepository) Get(ID string) (*entity.Job, error) {
+	ctx := context.Background()
+	jsonJob, err := q.client.Get(ctx, jobDataPrefix+ID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	job := &entity.Job{}
+	if err := json.Unmarshal([]byte(jsonJob), job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q jobQueueRepository) Dequeue(lease time.Duration) (*entity.Job, error) {
+	ctx := context.Background()
+	ID, err := dequeueScript.Run(ctx, q.client, []string{jobsQueueKey, jobsProcessingKey}, jobLeasePrefix, lease.Milliseconds()).Text()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	job, err := q.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		q.logger.Error("Job data not found, dropping job", ID)
+		return nil, q.Ack(ID)
+	}
+	job.Attempts++
+	jsonData, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	return job, q.client.Set(ctx, jobDataPrefix+ID, string(jsonData), 0).Err()
+}
+
+func (q jobQueueRepository) Extend(ID string, lease time.Duration) (bool, error) {
+	ctx := context.Background()
+	return q.client.PExpire(ctx, jobLeasePrefix+ID, lease).Result()
+}
+
+func (q jobQueueRepository) Ack(ID string) error {
+	ctx := context.Background()
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, jobsProcessingKey, 0, ID)
+		pipe.Del(ctx, jobLeasePrefix+ID, jobDataPrefix+ID, jobCancelPrefix+ID)
+		return nil
+	})
+	return err
+}
+
+func (q jobQueueRepository) RequeueExpired() ([]string, error) {
+	ctx := context.Background()
+	processing, err := q.client.LRange(ctx, jobsProcessingKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var requeued []string
+	for _, ID := range processing {
+		keys := []string{jobsQueueKey, jobsProcessingKey, jobLeasePrefix + ID}
+		moved, err := requeueScript.Run(ctx, q.client, keys, ID).Int()
+		if err != nil {
+			return requeued, err
+		}
+		if moved == 1 {
+			requeued = append(requeued, ID)
+		}
+	}
+	return requeued, nil
+}
+
+func (q jobQueueRepository) Queued() ([]string, error) {
+	ctx := context.Background()
+	queued, err := q.client.LRange(ctx, jobsQueueKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	// Jobs are pushed on the left and taken from the right
+	for i, j := 0, len(queued)-1; i < j; i, j = i+1, j-1 {
+		queued[i], queued[j] = queued[j], queued[i]
+	}
+	return queued, nil
+}
+
+func (q jobQueueRepository) Position(ID string) (int, error) {
+	queued, err := q.Queued()
+	if err != nil {
+		return 0, err
+	}
+	for i, v := range queued {
+		if v == ID {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (q jobQueueRepository) Remove(ID string) (bool, error) {
+	ctx := context.Background()
+	removed, err := q.client.LRem(ctx, jobsQueueKey, 0, ID).Result()
+	if err != nil {
+		return false, err
+	}
+	if removed == 0 {
+		return false, nil
+	}
+	return true, q.client.Del(ctx, jobDataPrefix+ID).Err()
+}
+
+func (q jobQueueRepository) RequestCancel(ID string) error {
+	ctx := context.Background()
+	return q.client.Set(ctx, jobCancelPrefix+ID, "1", 0).Err()
+}
+
+func (q jobQueueRepository) IsCancelRequested(ID string) (bool, error) {
+	ctx := context.Background()
+	exists, err := q.client.Exists(ctx, jobCancelPrefix+ID).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists == 1, nil
+}
+
+func (q jobQueueRepository) ClaimReporter(owner string, ttl time.Duration) (bool, error) {
+	ctx := context.Background()
+	claimed, err := q.client.SetNX(ctx, jobsReporterKey, owner, ttl).Result()
+	if err != nil || claimed {
+		return claimed, err
+	}
+	current, err := q.client.Get(ctx, jobsReporterKey).Result()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if current != owner {
+		return false, nil
+	}
+	return true, q.client.PExpire(ctx, jobsReporterKey, ttl).Err()
+}
@@ -9,6 +9,13 @@ import (
 	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
 )
 
+const (
+	processMetadataPrefix = "process:METADATA:"
+	processIndexesPrefix  = "process:INDEXES:"
+	processIndexKey       = "process:INDEX"
+	processQueryKey       = "process:QUERY"
+)
+
 type processRepository struct {
 	logger logger.Logger
 	client *redis.Client
@@ -22,57 +29,256 @@ func NewProcessRepository(logger logger.Logger, client *redis.Client) repository
 }
 
 func (p processRepository) GetMessages(ID string) ([]entity.ProgressEntity, error) {
+	return p.GetMessagesAfter(ID, 0)
+}
+
+func (p processRepository) GetMessagesAfter(ID string, after int64) ([]entity.ProgressEntity, error) {
 	ctx := context.Background()
-	result, err := p.client.LRange(ctx, "process:"+ID, 0, -1).Result()
+	result, err := p.client.LRange(ctx, "process:"+ID, after, -1).Result()
 	if err != nil {
 		return nil, err
 	}
 	var messages []entity.ProgressEntity
-	for _, row := range result {
+	for i, row := range result {
 		var message entity.Progress
 		err = json.Unmarshal([]byte(row), &message)
 		if err != nil {
 			p.logger.Error("Error unmarshalling message: %s", err.Error())
 			continue
 		}
+		message.Index = after + int64(i) + 1
 		messages = append(messages, entity.NewProgressEntity(message))
 	}
 	return messages, nil
 }
 
-func (p processRepository) SaveMessage(ID string, message entity.ProgressEntity) error {
+func (p processRepository) SaveMessage(ID string, message entity.ProgressEntity) (int64, error) {
 	ctx := context.Background()
 	progress := message.ToStruct()
 	p.logger.Debug("Saving message", progress)
 	jsonMessage, err := json.Marshal(progress)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	err = p.client.RPush(ctx, "process:"+ID, string(jsonMessage)).Err()
+	// The index is the position in the list, so it doesn't need to be stored
+	return p.client.RPush(ctx, "process:"+ID, string(jsonMessage)).Result()
+}
+
+func (p processRepository) MarkAsFinished(ID string) error {
+	return p.SetStatus(ID, entity.ProcessFinished)
+}
+
+func (p processRepository) IsFinished(ID string) (bool, error) {
+	status, err := p.GetStatus(ID)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	return status.Done(), nil
 }
 
-func (p processRepository) MarkAsFinished(ID string) error {
+func (p processRepository) SetStatus(ID string, status entity.ProcessStatus) error {
 	ctx := context.Background()
 	key := "process:STATUS:" + ID
-	err := p.client.Set(ctx, key, "finished", 0).Err()
+	err := p.client.Set(ctx, key, string(status), 0).Err()
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p processRepository) IsFinished(ID string) (bool, error) {
+func (p processRepository) GetStatus(ID string) (entity.ProcessStatus, error) {
 	ctx := context.Background()
 	key := "process:STATUS:" + ID
 	status, err := p.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return false, nil
+		return "", nil
 	} else if err != nil {
-		return false, err
+		return "", err
+	}
+	return entity.ProcessStatus(status), nil
+}
+
+func (p processRepository) SaveCompensation(ID string, compensation entity.Compensation) error {
+	ctx := context.Background()
+	jsonCompensation, err := json.Marshal(compensation)
+	if err != nil {
+		return err
+	}
+	return p.client.RPush(ctx, "process:COMPENSATIONS:"+ID, string(jsonCompensation)).Err()
+}
+
+func (p processRepository) GetCompensations(ID string) ([]entity.Compensation, error) {
+	ctx := context.Background()
+	result, err := p.client.LRange(ctx, "process:COMPENSATIONS:"+ID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var compensations []entity.Compensation
+	for _, row := range result {
+		var compensation entity.Compensation
+		err = json.Unmarshal([]byte(row), &compensation)
+		if err != nil {
+			p.logger.Error("Error unmarshalling compensation: %s", err.Error())
+			continue
+		}
+		compensations = append(compensations, compensation)
+	}
+	return compensations, nil
+}
+
+func (p processRepository) ClearCompensations(ID string) error {
+	ctx := context.Background()
+	return p.client.Del(ctx, "process:COMPENSATIONS:"+ID).Err()
+}
+
+func (p processRepository) SaveInput(ID string, input []byte) error {
+	ctx := context.Background()
+	return p.client.Set(ctx, "process:INPUT:"+ID, string(input), 0).Err()
+}
+
+func (p processRepository) GetInput(ID string) ([]byte, error) {
+	ctx := context.Background()
+	input, err := p.client.Get(ctx, "process:INPUT:"+ID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return []byte(input), nil
+}
+
+func (p processRepository) SaveCheckpoint(ID string, step string, data *entity.CreatedData) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, "process:CHECKPOINTS:"+ID, step)
+		pipe.Set(ctx, "process:CREATED:"+ID, string(jsonData), 0)
+		return nil
+	})
+	return err
+}
+
+func (p processRepository) GetCheckpoints(ID string) ([]string, *entity.CreatedData, error) {
+	ctx := context.Background()
+	steps, err := p.client.LRange(ctx, "process:CHECKPOINTS:"+ID, 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonData, err := p.client.Get(ctx, "process:CREATED:"+ID).Result()
+	if err == redis.Nil {
+		return steps, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	data := &entity.CreatedData{}
+	if err := json.Unmarshal([]byte(jsonData), data); err != nil {
+		return nil, nil, err
+	}
+	return steps, data, nil
+}
+
+func (p processRepository) ClearCheckpoints(ID string) error {
+	ctx := context.Background()
+	return p.client.Del(ctx, "process:CHECKPOINTS:"+ID, "process:CREATED:"+ID).Err()
+}
+
+// SaveMetadata saves the metadata on the indexes of its filters, by creation
+// time.
+func (p processRepository) SaveMetadata(metadata entity.ProcessMetadata) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	keys := append([]string{processMetadataPrefix + metadata.ID, processIndexesPrefix + metadata.ID}, processIndexKeys(metadata)...)
+	return saveIndexedScript.Run(ctx, p.client, keys, string(jsonData), metadata.CreatedAt.UnixMilli(), metadata.ID).Err()
+}
+
+func (p processRepository) GetMetadata(ID string) (*entity.ProcessMetadata, error) {
+	ctx := context.Background()
+	jsonData, err := p.client.Get(ctx, processMetadataPrefix+ID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	metadata := &entity.ProcessMetadata{}
+	if err := json.Unmarshal([]byte(jsonData), metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ListMetadata returns the processes matching the filter, newest first, and
+// the total of processes matching it.
+func (p processRepository) ListMetadata(filter entity.ProcessFilter) ([]entity.ProcessMetadata, int, error) {
+	ctx := context.Background()
+	IDs, total, err := indexPage(ctx, p.client, processQueryKey, processFilterKeys(filter), filter.Offset, filter.Limit, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(IDs) == 0 {
+		return []entity.ProcessMetadata{}, total, nil
+	}
+	keys := make([]string, len(IDs))
+	for i, ID := range IDs {
+		keys[i] = processMetadataPrefix + ID
+	}
+	rows, err := p.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []entity.ProcessMetadata{}
+	for _, row := range rows {
+		jsonData, ok := row.(string)
+		if !ok {
+			continue
+		}
+		var metadata entity.ProcessMetadata
+		if err := json.Unmarshal([]byte(jsonData), &metadata); err != nil {
+			p.logger.Error("Error unmarshalling process metadata: %s", err.Error())
+			continue
+		}
+		list = append(list, metadata)
+	}
+	return list, total, nil
+}
+
+// processIndexKeys are the indexes listing the process, the status filter
+// matches the status and the result.
+func processIndexKeys(m entity.ProcessMetadata) []string {
+	keys := []string{processIndexKey}
+	if m.Squad != "" {
+		keys = append(keys, processIndexKey+":SQUAD:"+m.Squad)
+	}
+	if m.Application != "" {
+		keys = append(keys, processIndexKey+":APPLICATION:"+m.Application)
+	}
+	if m.Status != "" {
+		keys = append(keys, processIndexKey+":STATUS:"+string(m.Status))
+	}
+	if m.Result != "" && string(m.Result) != string(m.Status) {
+		keys = append(keys, processIndexKey+":STATUS:"+string(m.Result))
+	}
+	return keys
+}
+
+func processFilterKeys(f entity.ProcessFilter) []string {
+	var keys []string
+	if f.Squad != "" {
+		keys = append(keys, processIndexKey+":SQUAD:"+f.Squad)
+	}
+	if f.Application != "" {
+		keys = append(keys, processIndexKey+":APPLICATION:"+f.Application)
+	}
+	if f.Status != "" {
+		keys = append(keys, processIndexKey+":STATUS:"+f.Status)
+	}
+	if len(keys) == 0 {
+		keys = append(keys, processIndexKey)
 	}
-	return status == "finished", nil
+	return keys
 }
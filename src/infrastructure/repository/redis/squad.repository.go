@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/zahirsis/dev-portal-backend/src/domain/entity"
+	"github.com/zahirsis/dev-portal-backend/src/domain/repository"
+	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
+)
+
+const (
+	ErrSquadNotFound = "squad not found"
+)
+
+const (
+	squadDataPrefix = "squad:DATA:"
+	// squadIndex has every squad with the same score, so they are listed by
+	// their code.
+	squadIndex = "squad:INDEX"
+)
+
+type squadRepository struct {
+	logger logger.Logger
+	client *redis.Client
+}
+
+func NewSquadRepository(logger logger.Logger, client *redis.Client) repository.SquadRepository {
+	return &squadRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (s squadRepository) List() ([]entity.SquadEntity, error) {
+	ctx := context.Background()
+	codes, err := s.client.ZRange(ctx, squadIndex, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := []entity.SquadEntity{}
+	if len(codes) == 0 {
+		return list, nil
+	}
+	keys := make([]string, len(codes))
+	for i, code := range codes {
+		keys[i] = squadDataPrefix + code
+	}
+	rows, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		jsonData, ok := row.(string)
+		if !ok {
+			continue
+		}
+		squad, err := s.unmarshal(jsonData)
+		if err != nil {
+			s.logger.Error("Error unmarshalling squad: %s", err.Error())
+			continue
+		}
+		list = append(list, squad)
+	}
+	return list, nil
+}
+
+func (s squadRepository) Get(code string) (entity.SquadEntity, error) {
+	ctx := context.Background()
+	jsonData, err := s.client.Get(ctx, squadDataPrefix+code).Result()
+	if err == redis.Nil {
+		return nil, errors.New(ErrSquadNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+	return s.unmarshal(jsonData)
+}
+
+func (s squadRepository) Save(squad entity.SquadEntity) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(squad.Config())
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, squadDataPrefix+squad.Code(), string(jsonData), 0)
+		pipe.ZAdd(ctx, squadIndex, &redis.Z{Score: 0, Member: squad.Code()})
+		return nil
+	})
+	return err
+}
+
+func (s squadRepository) Delete(code string) error {
+	ctx := context.Background()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, squadDataPrefix+code)
+		pipe.ZRem(ctx, squadIndex, code)
+		return nil
+	})
+	return err
+}
+
+func (s squadRepository) unmarshal(jsonData string) (entity.SquadEntity, error) {
+	config := &entity.SquadConfig{}
+	if err := json.Unmarshal([]byte(jsonData), config); err != nil {
+		return nil, err
+	}
+	return entity.NewSquadEntity(config), nil
+}
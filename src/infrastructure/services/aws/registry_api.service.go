@@ -23,7 +23,7 @@ func NewRegistryApiService(logger logger.Logger, client *ecr.Client) service.Reg
 	}
 }
 
-func (r *registryApiService) Create(e entity.RegistryEntity) (string, error) {
+func (r *registryApiService) Create(ctx context.Context, e entity.RegistryEntity) (string, bool, error) {
 	var tags []types.Tag
 	var msg string
 	for _, tag := range e.Tags() {
@@ -32,7 +32,6 @@ func (r *registryApiService) Create(e entity.RegistryEntity) (string, error) {
 			Value: tag.Value,
 		})
 	}
-	ctx := context.Background()
 	created, err := r.client.CreateRepository(ctx, &ecr.CreateRepositoryInput{
 		RepositoryName: e.Name(),
 		ImageScanningConfiguration: &types.ImageScanningConfiguration{
@@ -42,7 +41,7 @@ func (r *registryApiService) Create(e entity.RegistryEntity) (string, error) {
 		Tags:       tags,
 	}, func(opt *ecr.Options) { opt.Region = e.Config().Region })
 	if err != nil && !strings.Contains(err.Error(), "already exists in the registry with id") {
-		return msg, err
+		return msg, false, err
 	}
 	if err != nil {
 		name := e.Name()
@@ -61,8 +60,21 @@ func (r *registryApiService) Create(e entity.RegistryEntity) (string, error) {
 		},
 		func(opt *ecr.Options) { opt.Region = e.Config().Region })
 	if err != nil {
-		return msg, err
+		return msg, created != nil, err
 	}
 	r.logger.Info("Policy set", policySet)
-	return fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", *e.Config().RegistryId, e.Config().Region, *e.Name()), nil
+	return fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", *e.Config().RegistryId, e.Config().Region, *e.Name()), created != nil, nil
+}
+
+func (r *registryApiService) Delete(ctx context.Context, e entity.RegistryEntity) error {
+	deleted, err := r.client.DeleteRepository(ctx, &ecr.DeleteRepositoryInput{
+		RepositoryName: e.Name(),
+		RegistryId:     e.Config().RegistryId,
+		Force:          true,
+	}, func(opt *ecr.Options) { opt.Region = e.Config().Region })
+	if err != nil {
+		return err
+	}
+	r.logger.Info("Repository deleted", deleted.Repository)
+	return nil
 }
@@ -1,6 +1,7 @@
 package bitbucket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ktrysmt/go-bitbucket"
@@ -41,7 +42,10 @@ func NewGitApiService(cfg *config.GitConfig, l logger.Logger, client *bitbucket.
 	return &gitApiService{cfg, l, client}
 }
 
-func (a *gitApiService) CreatePullRequest(repository, sourceBranch, destinationBranch, title, message string) (*service.CreatedPullRequest, error) {
+func (a *gitApiService) CreatePullRequest(ctx context.Context, repository, sourceBranch, destinationBranch, title, message string) (*service.CreatedPullRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return &service.CreatedPullRequest{}, err
+	}
 	r, err := a.client.Repositories.PullRequests.Create(&bitbucket.PullRequestsOptions{
 		RepoSlug:          a.cfg.GetRepositoryPath(repository),
 		Title:             title,
@@ -62,7 +66,10 @@ func (a *gitApiService) CreatePullRequest(repository, sourceBranch, destinationB
 	return pr, nil
 }
 
-func (a *gitApiService) MergePullRequest(repository string, pullRequestId int) error {
+func (a *gitApiService) MergePullRequest(ctx context.Context, repository string, pullRequestId int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	r, err := a.client.Repositories.PullRequests.Merge(&bitbucket.PullRequestsOptions{
 		ID:                fmt.Sprintf("%d", pullRequestId),
 		RepoSlug:          a.cfg.GetRepositoryPath(repository),
@@ -70,13 +77,73 @@ func (a *gitApiService) MergePullRequest(repository string, pullRequestId int) e
 	})
 	if err != nil {
 		a.logger.Error("Error merging pull request", err)
-		return err
+		return "", err
 	}
 	a.logger.Debug("Pull request merged", r)
+	pr, _ := r.(map[string]interface{})
+	mergeCommit, _ := pr["merge_commit"].(map[string]interface{})
+	hash, _ := mergeCommit["hash"].(string)
+	if hash == "" {
+		a.logger.Warning("Merge commit of pull request not found", pullRequestId)
+	}
+	return hash, nil
+}
+
+func (a *gitApiService) DeclinePullRequest(ctx context.Context, repository string, pullRequestId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r, err := a.client.Repositories.PullRequests.Decline(&bitbucket.PullRequestsOptions{
+		ID:       fmt.Sprintf("%d", pullRequestId),
+		RepoSlug: a.cfg.GetRepositoryPath(repository),
+	})
+	if err != nil {
+		a.logger.Error("Error declining pull request", err)
+		return err
+	}
+	a.logger.Debug("Pull request declined", r)
 	return nil
 }
 
-func (a *gitApiService) EnablePipelines(repository string) error {
+func (a *gitApiService) GetPullRequest(ctx context.Context, repository string, pullRequestId int) (*service.PullRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, err := a.client.Repositories.PullRequests.Get(&bitbucket.PullRequestsOptions{
+		ID:       fmt.Sprintf("%d", pullRequestId),
+		RepoSlug: a.cfg.GetRepositoryPath(repository),
+	})
+	if err != nil {
+		a.logger.Error("Error reading pull request", err)
+		return nil, err
+	}
+	pr := &service.PullRequest{}
+	if err := a.unmarshalResponse(r, pr, "get pull request"); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (a *gitApiService) DeleteBranch(ctx context.Context, repository, branch string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.logger.Debug("Deleting branch", repository, branch)
+	err := a.client.Repositories.Repository.DeleteBranch(&bitbucket.RepositoryBranchDeleteOptions{
+		RepoSlug: a.cfg.GetRepositoryPath(repository),
+		RefName:  branch,
+	})
+	if err != nil {
+		a.logger.Error("Error deleting branch", repository, branch, err)
+		return err
+	}
+	return nil
+}
+
+func (a *gitApiService) EnablePipelines(ctx context.Context, repository string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	a.logger.Debug("Enabling pipelines", repository)
 	_, err := a.client.Repositories.Repository.UpdatePipelineConfig(&bitbucket.RepositoryPipelineOptions{
 		RepoSlug: a.cfg.GetRepositoryPath(repository),
@@ -89,7 +156,10 @@ func (a *gitApiService) EnablePipelines(repository string) error {
 	return nil
 }
 
-func (a *gitApiService) SetRepositoryVariables(repository string, variables []*service.PipelineVariable) error {
+func (a *gitApiService) SetRepositoryVariables(ctx context.Context, repository string, variables []*service.PipelineVariable) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	repoSlug := a.cfg.GetRepositoryPath(repository)
 	a.logger.Debug("Setting repository variables", repository, variables)
 	lvr, err := a.client.Repositories.Repository.ListPipelineVariables(&bitbucket.RepositoryPipelineVariablesOptions{
@@ -104,6 +174,9 @@ func (a *gitApiService) SetRepositoryVariables(repository string, variables []*s
 		return err
 	}
 	for _, v := range variables {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		skip := false
 		for _, rv := range lv.Variables {
 			if rv.Key == v.Key && rv.Value != v.Value {
@@ -140,7 +213,10 @@ func (a *gitApiService) SetRepositoryVariables(repository string, variables []*s
 	return nil
 }
 
-func (a *gitApiService) SetRepositoryEnvironmentsVariables(repository string, environments []*service.PipelineEnvironment) error {
+func (a *gitApiService) SetRepositoryEnvironmentsVariables(ctx context.Context, repository string, environments []*service.PipelineEnvironment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	repoSlug := a.cfg.GetRepositoryPath(repository)
 	a.logger.Debug("Setting repository environment variables", repository, environments)
 	le, err := a.client.Repositories.Repository.ListEnvironments(&bitbucket.RepositoryEnvironmentsOptions{
@@ -197,6 +273,9 @@ func (a *gitApiService) SetRepositoryEnvironmentsVariables(repository string, en
 		}
 	}
 	for _, e := range envs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		lvr, err := a.client.Repositories.Repository.ListDeploymentVariables(&bitbucket.RepositoryDeploymentVariablesOptions{
 			RepoSlug: repoSlug,
 			Environment: &bitbucket.Environment{
@@ -253,11 +332,29 @@ func (a *gitApiService) SetRepositoryEnvironmentsVariables(repository string, en
 	return nil
 }
 
-func (a *gitApiService) ActiveRepositoryPipelines(repository string) error {
+func (a *gitApiService) ActiveRepositoryPipelines(ctx context.Context, repository string) error {
 	a.logger.Debug("Activating repository pipelines", repository)
 	return nil
 }
 
+func (a *gitApiService) AddDefaultReviewers(ctx context.Context, repository string, reviewers []string) error {
+	a.logger.Debug("Adding default reviewers", repository, reviewers)
+	for _, reviewer := range reviewers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := a.client.Repositories.Repository.AddDefaultReviewer(&bitbucket.RepositoryDefaultReviewerOptions{
+			RepoSlug: a.cfg.GetRepositoryPath(repository),
+			Username: reviewer,
+		})
+		if err != nil {
+			a.logger.Error("Error adding default reviewer", repository, reviewer, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
 func (a *gitApiService) unmarshalResponse(r interface{}, pr any, responseType string) error {
 	jr, err := json.Marshal(r)
 	if err != nil {
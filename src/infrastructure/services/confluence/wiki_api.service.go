@@ -19,7 +19,7 @@ func NewConfluenceService(config *config.Config, logger logger.Logger, api *conf
 	return &confluenceService{config, logger, api}
 }
 
-func (c *confluenceService) CreatePage(title, space, parent string, content []byte) (url string, err error) {
+func (c *confluenceService) CreatePage(title, space, parent string, content []byte) (id string, url string, err error) {
 	c.logger.Debug(fmt.Sprintf("Creating page %s", title))
 	wc, err := c.api.CreatePage(&confluenceapiv2.Page{
 		Status:   "current",
@@ -32,9 +32,9 @@ func (c *confluenceService) CreatePage(title, space, parent string, content []by
 	})
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("Error creating page %s: %s", title, err.Error()))
-		return "", err
+		return "", "", err
 	}
-	return fmt.Sprintf("%s/wiki%s", c.config.WikiConfig.BaseUrl, wc.Links.Webui), nil
+	return wc.Id, fmt.Sprintf("%s/wiki%s", c.config.WikiConfig.BaseUrl, wc.Links.Webui), nil
 }
 
 func (c *confluenceService) ListSubPages(space, parent string) ([]*service.PageList, error) {
@@ -71,6 +71,44 @@ func (c *confluenceService) ListSubPages(space, parent string) ([]*service.PageL
 	return pages, nil
 }
 
+func (c *confluenceService) ArchivePage(Id string) error {
+	c.logger.Debug(fmt.Sprintf("Archiving page %s", Id))
+	err := c.api.ArchivePages(Id)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Error archiving page %s: %s", Id, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (c *confluenceService) MovePage(Id string, parent string) error {
+	c.logger.Debug(fmt.Sprintf("Moving page %s to %s", Id, parent))
+	page, err := c.api.GetPageByID(Id, &confluenceapiv2.GetPageByIdQuery{BodyFormat: "storage"})
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Error getting page %s: %s", Id, err.Error()))
+		return err
+	}
+	_, err = c.api.UpdatePage(&confluenceapiv2.Page{
+		Id:       page.Id,
+		SpaceId:  page.SpaceId,
+		Status:   page.Status,
+		Title:    page.Title,
+		ParentId: parent,
+		Body: &confluenceapiv2.PageBody{
+			Storage: page.Body.Storage,
+		},
+		Version: &confluenceapiv2.Version{
+			Message: "Moved to archive",
+			Number:  page.Version.Number + 1,
+		},
+	})
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Error moving page %s: %s", Id, err.Error()))
+		return err
+	}
+	return nil
+}
+
 func (c *confluenceService) UpdatePage(Id string, content []byte, updateMessage string) error {
 	page, err := c.api.GetPageByID(Id, &confluenceapiv2.GetPageByIdQuery{})
 	if err != nil {
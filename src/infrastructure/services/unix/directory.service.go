@@ -8,6 +8,7 @@ import (
 	"github.com/zahirsis/dev-portal-backend/src/domain/service"
 	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
 	templateHtml "html/template"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -57,6 +58,75 @@ func (d *directoryService) DirectoryExists(path string) (bool, error) {
 	}
 }
 
+func (d *directoryService) ListDirectories(path string) ([]service.DirectoryInfo, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return []service.DirectoryInfo{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	directories := []service.DirectoryInfo{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		directories = append(directories, service.DirectoryInfo{
+			Name:       entry.Name(),
+			Path:       filepath.Join(path, entry.Name()),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	return directories, nil
+}
+
+func (d *directoryService) DirectorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func (d *directoryService) ReadFiles(path string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = content
+		return nil
+	})
+	return files, err
+}
+
 func (d *directoryService) VerifyOrInsertLineInFile(path string, line string) error {
 	inputFile, err := os.Open(path)
 	if err != nil {
@@ -91,6 +161,34 @@ func (d *directoryService) VerifyOrInsertLineInFile(path string, line string) er
 	return nil
 }
 
+func (d *directoryService) RemoveLineFromFile(path string, line string) error {
+	inputFile, err := os.Open(path)
+	if err != nil {
+		d.logger.Error("Error opening file", path, err.Error())
+		return err
+	}
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(inputFile)
+	for scanner.Scan() {
+		l := scanner.Text()
+		if l == "" || strings.Trim(l, " ") == strings.Trim(line, " ") {
+			continue
+		}
+		buf.WriteString(l + "\n")
+	}
+	err = inputFile.Close()
+	if err != nil {
+		d.logger.Error("Error closing file", path, err.Error())
+		return err
+	}
+	err = os.WriteFile(path, buf.Bytes(), 0644)
+	if err != nil {
+		d.logger.Error("Error writing file", path, err.Error())
+		return err
+	}
+	return nil
+}
+
 func (d *directoryService) ApplyTemplate(path string, values interface{}) (err error) {
 	d.logger.Debug("Applying template on file", path)
 	defer func() {
@@ -119,7 +217,15 @@ func (d *directoryService) ApplyTemplate(path string, values interface{}) (err e
 	return d.ClearBlankLinesFromFile(path)
 }
 
-func (d *directoryService) LoadTemplate(path string, values interface{}, html bool) (value []byte, err error) {
+func (d *directoryService) LoadTemplate(path string, values interface{}, html bool) ([]byte, error) {
+	return d.loadTemplate(path, values, html, "missingkey=default")
+}
+
+func (d *directoryService) LoadStrictTemplate(path string, values interface{}, html bool) ([]byte, error) {
+	return d.loadTemplate(path, values, html, "missingkey=error")
+}
+
+func (d *directoryService) loadTemplate(path string, values interface{}, html bool, option string) (value []byte, err error) {
 	d.logger.Debug("Loading template on file", path)
 	defer func() {
 		if rec := recover(); rec != nil {
@@ -134,14 +240,14 @@ func (d *directoryService) LoadTemplate(path string, values interface{}, html bo
 	}()
 	var buf bytes.Buffer
 	if html {
-		t := templateHtml.Must(templateHtml.ParseFiles(path))
+		t := templateHtml.Must(templateHtml.ParseFiles(path)).Option(option)
 		err = t.Execute(&buf, values)
 		if err != nil {
 			d.logger.Error("Error loading result content from template", path, err.Error())
 			return nil, err
 		}
 	} else {
-		t := template.Must(template.ParseFiles(path))
+		t := template.Must(template.ParseFiles(path)).Option(option)
 		err = t.Execute(&buf, values)
 		if err != nil {
 			d.logger.Error("Error loading result content from template", path, err.Error())
@@ -0,0 +1,231 @@
+package unix
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// The repositories are kept as bare mirrors under the mirrors path, fetched
+// before each clone, and every clone is a worktree of the mirror. The mirror
+// only has the remote branches as remote-tracking refs, so the worktrees get
+// a detached HEAD on them and their local branches are never changed by a
+// fetch.
+
+// mirrorPath returns the absolute path of the mirror without symlinks, the
+// same path git gives for the mirror of a worktree.
+func (g *gitService) mirrorPath(repository string) (string, error) {
+	root, err := filepath.Abs(g.cfg.MirrorsPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	return filepath.Join(root, strings.ReplaceAll(repository, "/", "_")+".git"), nil
+}
+
+// mirrorLock returns the lock serializing the changes to a mirror, by its
+// path as given by mirrorPath.
+func (g *gitService) mirrorLock(mirror string) *sync.Mutex {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if _, ok := g.mirrors[mirror]; !ok {
+		g.mirrors[mirror] = &sync.Mutex{}
+	}
+	return g.mirrors[mirror]
+}
+
+// updateMirror creates the mirror of the repository or fetches its changes,
+// the caller must hold the mirror lock.
+func (g *gitService) updateMirror(ctx context.Context, repository string, mirror string) error {
+	url := g.cfg.GetRemoteUrl(repository)
+	if _, err := os.Stat(mirror + "/HEAD"); os.IsNotExist(err) {
+		cmd := exec.CommandContext(ctx, "git", "init", "--bare", mirror)
+		if err := g.execCommand(ctx, cmd, fmt.Sprintf("creating %s mirror", url)); err != nil {
+			return err
+		}
+		cmd = exec.CommandContext(ctx, "git", "remote", "add", "origin", url)
+		cmd.Dir = mirror
+		if err := g.execCommand(ctx, cmd, fmt.Sprintf("adding %s remote to mirror", url)); err != nil {
+			_ = os.RemoveAll(mirror)
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--prune", "origin")
+	cmd.Dir = mirror
+	return g.execCommand(ctx, cmd, fmt.Sprintf("fetching %s mirror", url))
+}
+
+// addWorktree updates the mirror of the repository and adds a worktree of it
+// at the branch on the path.
+func (g *gitService) addWorktree(ctx context.Context, repository string, branch string, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	mirror, err := g.mirrorPath(repository)
+	if err != nil {
+		return err
+	}
+	lock := g.mirrorLock(mirror)
+	lock.Lock()
+	defer lock.Unlock()
+	if err := g.updateMirror(ctx, repository, mirror); err != nil {
+		return err
+	}
+	// The worktrees of the removed workspaces are still registered
+	cmd := exec.CommandContext(ctx, "git", "worktree", "prune")
+	cmd.Dir = mirror
+	if err := g.execCommand(ctx, cmd, "pruning worktrees"); err != nil {
+		return err
+	}
+	cmd = exec.CommandContext(ctx, "git", "worktree", "add", "--detach", path, "origin/"+branch)
+	cmd.Dir = mirror
+	return g.execCommand(ctx, cmd, fmt.Sprintf("adding worktree of %s at %s branch", repository, branch))
+}
+
+// worktreeMirror returns the path of the mirror of the worktree.
+func (g *gitService) worktreeMirror(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		g.logger.Error("Error getting worktree mirror", path, err.Error())
+		return "", err
+	}
+	return filepath.EvalSymlinks(strings.TrimSpace(string(output)))
+}
+
+// removeBranchWorktrees removes the other worktrees of the mirror having the
+// branch checked out, like the workspace kept by a failed process, git
+// doesn't let a branch be checked out by two worktrees.
+func (g *gitService) removeBranchWorktrees(ctx context.Context, path string, branch string) error {
+	current, err := filepath.Abs(path)
+	if err == nil {
+		current, err = filepath.EvalSymlinks(current)
+	}
+	if err != nil {
+		return err
+	}
+	mirror, err := g.worktreeMirror(ctx, path)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
+	cmd.Dir = mirror
+	output, err := cmd.Output()
+	if err != nil {
+		g.logger.Error("Error listing worktrees", mirror, err.Error())
+		return err
+	}
+	worktree := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			worktree = p
+		} else if line == "branch refs/heads/"+branch && worktree != current {
+			g.logger.Info("Removing worktree with branch", worktree, branch)
+			if err := g.removeWorktree(ctx, mirror, worktree); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fetchWorktree fetches the changes of the mirror of the worktree.
+func (g *gitService) fetchWorktree(ctx context.Context, path string) error {
+	mirror, err := g.worktreeMirror(ctx, path)
+	if err != nil {
+		return err
+	}
+	lock := g.mirrorLock(mirror)
+	lock.Lock()
+	defer lock.Unlock()
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--prune", "origin")
+	cmd.Dir = path
+	return g.execCommand(ctx, cmd, "fetching mirror")
+}
+
+func (g *gitService) RemoveWorktrees(ctx context.Context, path string) error {
+	if g.cfg.MirrorsPath == "" {
+		return nil
+	}
+	var worktrees []string
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !d.IsDir() || d.Name() == ".git" {
+			return nil
+		}
+		if g.isWorktree(file) {
+			worktrees = append(worktrees, file)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, worktree := range worktrees {
+		mirror, err := g.worktreeMirror(ctx, worktree)
+		if err != nil {
+			return err
+		}
+		if err := g.removeWorktree(ctx, mirror, worktree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeWorktree removes the worktree of the mirror, even with changes or
+// locked. A worktree already gone is pruned instead.
+func (g *gitService) removeWorktree(ctx context.Context, mirror string, worktree string) error {
+	lock := g.mirrorLock(mirror)
+	lock.Lock()
+	defer lock.Unlock()
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", "--force", worktree)
+	cmd.Dir = mirror
+	if err := g.execCommand(ctx, cmd, fmt.Sprintf("removing worktree %s", worktree)); err == nil {
+		return nil
+	}
+	cmd = exec.CommandContext(ctx, "git", "worktree", "prune")
+	cmd.Dir = mirror
+	return g.execCommand(ctx, cmd, "pruning worktrees")
+}
+
+// isWorktree tells if the path is a worktree of a mirror, whose .git is a
+// file instead of the repository.
+func (g *gitService) isWorktree(path string) bool {
+	info, err := os.Stat(path + "/.git")
+	return err == nil && !info.IsDir()
+}
+
+// revision returns the remote-tracking ref of a branch only known by the
+// mirror of a worktree, otherwise the given ref.
+func (g *gitService) revision(ctx context.Context, path string, ref string) string {
+	if !g.isWorktree(path) || g.hasRef(ctx, path, "refs/heads/"+ref) || !g.hasRef(ctx, path, "refs/remotes/origin/"+ref) {
+		return ref
+	}
+	return "origin/" + ref
+}
+
+func (g *gitService) hasRef(ctx context.Context, path string, ref string) bool {
+	cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", ref)
+	cmd.Dir = path
+	return cmd.Run() == nil
+}
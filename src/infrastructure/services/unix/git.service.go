@@ -2,6 +2,7 @@ package unix
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/zahirsis/dev-portal-backend/config"
@@ -9,41 +10,63 @@ import (
 	"github.com/zahirsis/dev-portal-backend/src/pkg/logger"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
 type gitService struct {
-	cfg    *config.GitConfig
-	logger logger.Logger
+	cfg     *config.GitConfig
+	logger  logger.Logger
+	mutex   sync.Mutex
+	mirrors map[string]*sync.Mutex
 }
 
 func NewGitService(cfg *config.GitConfig, logger logger.Logger) service.GitService {
 	configGit(logger)
 	return &gitService{
-		cfg:    cfg,
-		logger: logger,
+		cfg:     cfg,
+		logger:  logger,
+		mirrors: make(map[string]*sync.Mutex),
 	}
 }
 
-func (g *gitService) CloneRepository(repository string, branch string, path string) error {
+// CloneRepository adds a worktree of the repository mirror when mirrors are
+// enabled, otherwise it clones the repository.
+func (g *gitService) CloneRepository(ctx context.Context, repository string, branch string, path string) error {
+	if g.cfg.MirrorsPath != "" {
+		return g.addWorktree(ctx, repository, branch, path)
+	}
 	url := g.cfg.GetRemoteUrl(repository)
-	cmd := exec.Command("git", "clone", "-b", branch, url, path)
-	return g.execCommand(cmd, fmt.Sprintf("cloning %s repository", url))
+	cmd := exec.CommandContext(ctx, "git", "clone", "-b", branch, url, path)
+	return g.execCommand(ctx, cmd, fmt.Sprintf("cloning %s repository", url))
 }
 
-func (g *gitService) Checkout(path string, branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
+func (g *gitService) Checkout(ctx context.Context, path string, branch string) error {
+	if revision := g.revision(ctx, path, branch); revision != branch {
+		cmd := exec.CommandContext(ctx, "git", "checkout", "--detach", revision)
+		cmd.Dir = path
+		return g.execCommand(ctx, cmd, fmt.Sprintf("checking out to %s branch", branch))
+	}
+	cmd := exec.CommandContext(ctx, "git", "checkout", branch)
 	cmd.Dir = path
-	return g.execCommand(cmd, fmt.Sprintf("checking out to %s branch", branch))
+	return g.execCommand(ctx, cmd, fmt.Sprintf("checking out to %s branch", branch))
 }
 
-func (g *gitService) Branch(path string, branch string) error {
-	cmd := exec.Command("git", "checkout", "-b", branch)
+func (g *gitService) Branch(ctx context.Context, path string, branch string) error {
+	flag := "-b"
+	if g.isWorktree(path) {
+		// The branch may be left on the mirror by a previous process
+		if err := g.removeBranchWorktrees(ctx, path, branch); err != nil {
+			return err
+		}
+		flag = "-B"
+	}
+	cmd := exec.CommandContext(ctx, "git", "checkout", flag, branch)
 	cmd.Dir = path
-	return g.execCommand(cmd, fmt.Sprintf("creating %s branch", branch))
+	return g.execCommand(ctx, cmd, fmt.Sprintf("creating %s branch", branch))
 }
 
-func (g *gitService) Commit(path string, message string) error {
-	hasChanges, err := g.HasChanges(path)
+func (g *gitService) Commit(ctx context.Context, path string, message string) error {
+	hasChanges, err := g.HasChanges(ctx, path)
 	if err != nil {
 		return err
 	}
@@ -51,30 +74,43 @@ func (g *gitService) Commit(path string, message string) error {
 		g.logger.Debug("No changes to commit, skipping...")
 		return nil
 	}
-	cmd := exec.Command("git", "add", ".")
+	cmd := exec.CommandContext(ctx, "git", "add", ".")
 	cmd.Dir = path
-	err = g.execCommand(cmd, fmt.Sprintf("adding files to stage"))
+	err = g.execCommand(ctx, cmd, fmt.Sprintf("adding files to stage"))
 	if err != nil {
 		return err
 	}
-	cmd = exec.Command("git", "commit", "-m", message)
+	cmd = exec.CommandContext(ctx, "git", "commit", "-m", message)
 	cmd.Dir = path
-	return g.execCommand(cmd, fmt.Sprintf("commiting files to git"))
+	return g.execCommand(ctx, cmd, fmt.Sprintf("commiting files to git"))
 }
 
-func (g *gitService) Push(path string, branch string) error {
-	cmd := exec.Command("git", "push", "-u", "origin", branch)
+// Push forces the branch with lease, a retried process pushes again the
+// branches of its first run, which have its id so no other process owns them.
+func (g *gitService) Push(ctx context.Context, path string, branch string) error {
+	args := []string{"push", "--force-with-lease", "-u", "origin", branch}
+	if g.isWorktree(path) {
+		// The upstream would be written on the config shared by the worktrees
+		args = []string{"push", "--force-with-lease", "origin", branch}
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = path
-	return g.execCommand(cmd, fmt.Sprintf("pushing changes to %s branch", branch))
+	return g.execCommand(ctx, cmd, fmt.Sprintf("pushing changes to %s branch", branch))
 }
 
-func (g *gitService) Pull(path string, branch string) error {
-	cmd := exec.Command("git", "pull", "origin", branch)
+func (g *gitService) Pull(ctx context.Context, path string, branch string) error {
+	if g.isWorktree(path) && !g.hasRef(ctx, path, "refs/heads/"+branch) {
+		if err := g.fetchWorktree(ctx, path); err != nil {
+			return err
+		}
+		return g.Checkout(ctx, path, branch)
+	}
+	cmd := exec.CommandContext(ctx, "git", "pull", "origin", branch)
 	cmd.Dir = path
-	return g.execCommand(cmd, fmt.Sprintf("pulling changes from %s branch", branch))
+	return g.execCommand(ctx, cmd, fmt.Sprintf("pulling changes from %s branch", branch))
 }
 
-func (g *gitService) execCommand(cmd *exec.Cmd, action string) error {
+func (g *gitService) execCommand(ctx context.Context, cmd *exec.Cmd, action string) error {
 	g.logger.Debug(action)
 	stderr, _ := cmd.StderrPipe()
 	if err := cmd.Start(); err != nil {
@@ -86,6 +122,10 @@ func (g *gitService) execCommand(cmd *exec.Cmd, action string) error {
 		errMessage += scanner.Text() + "\n"
 	}
 	err := cmd.Wait()
+	if err != nil && ctx.Err() != nil {
+		g.logger.Info(fmt.Sprintf("Interrupted %s", action), ctx.Err().Error())
+		return fmt.Errorf("%s: %w", action, ctx.Err())
+	}
 	if err != nil {
 		g.logger.Error(action, errMessage, err.Error())
 		return errors.New(fmt.Sprintf("%s: \n%s\n%s", action, errMessage, err.Error()))
@@ -93,8 +133,8 @@ func (g *gitService) execCommand(cmd *exec.Cmd, action string) error {
 	return nil
 }
 
-func (g *gitService) HasChanges(path string) (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+func (g *gitService) HasChanges(ctx context.Context, path string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	cmd.Dir = path
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -105,6 +145,47 @@ func (g *gitService) HasChanges(path string) (bool, error) {
 	return statusOutput != "", nil
 }
 
+func (g *gitService) Diff(ctx context.Context, path string, base string, head string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", g.revision(ctx, path, base), g.revision(ctx, path, head))
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		g.logger.Error("Error getting git diff", base, head, err.Error())
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (g *gitService) HeadCommit(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		g.logger.Error("Error getting git head commit", err.Error())
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Revert reverts the commit on the current branch, a merge commit is reverted
+// against its first parent, the target branch of the merge.
+func (g *gitService) Revert(ctx context.Context, path string, commit string) error {
+	parents := exec.CommandContext(ctx, "git", "rev-list", "--parents", "-n", "1", commit)
+	parents.Dir = path
+	output, err := parents.Output()
+	if err != nil {
+		g.logger.Error("Error reading parents of commit", commit, err.Error())
+		return err
+	}
+	args := []string{"revert", "--no-edit"}
+	if len(strings.Fields(string(output))) > 2 {
+		args = append(args, "-m", "1")
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, commit)...)
+	cmd.Dir = path
+	return g.execCommand(ctx, cmd, fmt.Sprintf("reverting commit %s", commit))
+}
+
 func configGit(logger logger.Logger) {
 	cmd := exec.Command("git", "version")
 	err := cmd.Run()
@@ -25,24 +25,54 @@ func NewSecretApiService(cfg *config.Config, logger logger.Logger, api *api.Clie
 	}
 }
 
-func (s *secretApiService) CreateBlank(location, path string) error {
+func (s *secretApiService) CreateBlank(location, path string) (bool, error) {
 	ctx := context.Background()
 	_, err := s.api.Auth().Login(ctx, s.auth)
 	if err != nil {
 		s.logger.Error("Error logging in to vault", err.Error())
-		return err
+		return false, err
 	}
 	_, err = s.api.KVv2(location).Get(ctx, path)
 	if err != nil && !strings.HasPrefix(err.Error(), "secret not found") {
 		s.logger.Error("Error verifying if secret exists", err.Error(), location, path)
-		return err
+		return false, err
 	} else if err == nil {
 		s.logger.Error("Secret already exists, skipping", location, path)
-		return nil
+		return false, nil
 	}
 	_, err = s.api.KVv2(location).Put(ctx, path, make(map[string]interface{}))
 	if err != nil {
 		s.logger.Error("Error creating secret", err.Error(), location, path)
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *secretApiService) Delete(location, path string) error {
+	ctx := context.Background()
+	_, err := s.api.Auth().Login(ctx, s.auth)
+	if err != nil {
+		s.logger.Error("Error logging in to vault", err.Error())
+		return err
+	}
+	err = s.api.KVv2(location).DeleteMetadata(ctx, path)
+	if err != nil {
+		s.logger.Error("Error deleting secret", err.Error(), location, path)
+		return err
+	}
+	return nil
+}
+
+func (s *secretApiService) SoftDelete(location, path string) error {
+	ctx := context.Background()
+	_, err := s.api.Auth().Login(ctx, s.auth)
+	if err != nil {
+		s.logger.Error("Error logging in to vault", err.Error())
+		return err
+	}
+	err = s.api.KVv2(location).Delete(ctx, path)
+	if err != nil {
+		s.logger.Error("Error soft deleting secret", err.Error(), location, path)
 		return err
 	}
 	return nil
@@ -0,0 +1,97 @@
+package messenger
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	eventsChannelPrefix = "process:EVENTS:"
+	closeChannelPrefix  = "process:CLOSE:"
+	subscribeTimeout    = 5 * time.Second
+)
+
+// redisMessageManager shares the messages through Redis pub/sub, so a process
+// running in one instance can be followed from any other instance.
+type redisMessageManager struct {
+	client        *redis.Client
+	subscriptions map[chan []byte]*redisSubscription
+	mutex         sync.Mutex
+}
+
+type redisSubscription struct {
+	pubSub *redis.PubSub
+	done   chan struct{}
+}
+
+func NewRedisMessageManager(client *redis.Client) MessageManager {
+	return &redisMessageManager{
+		client:        client,
+		subscriptions: make(map[chan []byte]*redisSubscription),
+	}
+}
+
+// Subscribe returns once Redis confirmed the subscription, so the messages
+// published after it are received. When it can't be confirmed the pub/sub
+// keeps retrying it in the background.
+func (mm *redisMessageManager) Subscribe(ID string) chan []byte {
+	pubSub := mm.client.Subscribe(context.Background(), eventsChannelPrefix+ID, closeChannelPrefix+ID)
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	// Both channels are subscribed by the same command, so its first
+	// confirmation is enough
+	_, _ = pubSub.Receive(ctx)
+	cancel()
+
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	ch := make(chan []byte, 10)
+	subscription := &redisSubscription{
+		pubSub: pubSub,
+		done:   make(chan struct{}),
+	}
+	mm.subscriptions[ch] = subscription
+	go func() {
+		defer close(ch)
+		defer mm.Unsubscribe(ID, ch)
+		for message := range subscription.pubSub.Channel() {
+			if message.Channel == closeChannelPrefix+ID {
+				return
+			}
+			select {
+			case ch <- []byte(message.Payload):
+			case <-subscription.done:
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (mm *redisMessageManager) Unsubscribe(_ string, ch chan []byte) {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	subscription, ok := mm.subscriptions[ch]
+	if !ok {
+		return
+	}
+	delete(mm.subscriptions, ch)
+	close(subscription.done)
+	_ = subscription.pubSub.Close()
+}
+
+func (mm *redisMessageManager) Broadcast(ID string, message []byte) {
+	mm.client.Publish(context.Background(), eventsChannelPrefix+ID, message)
+}
+
+func (mm *redisMessageManager) Close(ID string) {
+	mm.client.Publish(context.Background(), closeChannelPrefix+ID, "")
+}
+
+func (mm *redisMessageManager) GenerateID() string {
+	return uuid.New().String()
+}